@@ -0,0 +1,56 @@
+package encode
+
+import (
+	"fmt"
+	"io"
+)
+
+// Encode zeroes so that the next item starts at a multiple of n bytes from the start of the
+// Encoding, as in formats meant to be cast directly to structs. Decoding skips the same padding.
+//
+// AlignTo must be directly in an Encoding, since only Encoding tells it where it starts. Elsewhere it
+// pads as though it were at the start of the buffer, which is to say not at all. Since its Size
+// depends on where it is, it must not be shared between Encodings or used concurrently.
+//
+// Panics if n is not positive.
+func AlignTo(n int) Item {
+	if n < 1 {
+		panic(fmt.Sprintf("invalid n=%d, must be at least 1", n))
+	}
+	return alignTo{n: n, pad: new(int)}
+}
+
+// Implemented by items whose encoding depends on where they are, to be told their offset from the
+// start of the Encoding before Size or Decode.
+type offsetter interface {
+	setOffset(offset int)
+}
+
+func setOffset(item Item, offset int) {
+	if o, ok := findItem[offsetter](item); ok {
+		o.setOffset(offset)
+	}
+}
+
+type alignTo struct {
+	n   int
+	pad *int
+}
+
+func (e alignTo) setOffset(offset int) {
+	*e.pad = (e.n - offset%e.n) % e.n
+}
+func (e alignTo) Encode(buf []byte) {
+	for i := range buf[:*e.pad] {
+		buf[i] = 0
+	}
+}
+func (e alignTo) Size() int {
+	return *e.pad
+}
+func (e alignTo) Decode(buf []byte) error {
+	if len(buf) < *e.pad {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlignTo(t *testing.T) {
+	a := byte(1)
+	s := "ab"
+	c := uint64(0x0102030405060708)
+	enc := New(Byte(&a), AlignTo(4), LengthDelimString(&s), AlignTo(8), FixedUint64(&c), AlignTo(8))
+	b := enc.Encode()
+	require.Equal(t, []byte{
+		0x01, 0x00, 0x00, 0x00,
+		0x02, 'a', 'b', 0x00,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+	}, b)
+
+	buf, offsets := enc.EncodeWithOffsets()
+	require.Equal(t, b, buf)
+	require.Equal(t, FieldOffset{Offset: 8, Size: 8}, offsets[4])
+
+	a, s, c = 0, "", 0
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, byte(1), a)
+	require.Equal(t, "ab", s)
+	require.Equal(t, uint64(0x0102030405060708), c)
+
+	s = "abcd"
+	require.Len(t, enc.Encode(), 24)
+
+	err := enc.Decode(b[:2])
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,118 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendTo(t *testing.T) {
+	var a uint16 = 7
+	s := "foo"
+	enc := New(FixedUint16(&a), LengthDelimString(&s))
+	require.Equal(t, append([]byte("prefix"), enc.Encode()...), enc.AppendTo([]byte("prefix")))
+	require.Equal(t, enc.Encode(), enc.AppendTo(nil))
+
+	// Spare capacity is cleared first, since some items only write their non-zero bytes.
+	f := false
+	dirty := []byte{0xff, 0xff, 0xff}
+	require.Equal(t, []byte{0x00, 0x00, 0x00}, New(Bool(&f), Padding(2)).AppendTo(dirty[:0]))
+
+	var stats Stats
+	require.Equal(t, enc.Encode(), enc.WithStats(&stats).AppendTo(nil))
+}
+
+func TestLengthDelimBytesNoCopy(t *testing.T) {
+	v := []byte("bar")
+	buf := New(LengthDelimBytesNoCopy(&v)).Encode()
+	require.Equal(t, New(LengthDelimBytes(&v)).Encode(), buf)
+
+	var out []byte
+	require.NoError(t, New(LengthDelimBytesNoCopy(&out)).Decode(buf))
+	require.Equal(t, []byte("bar"), out)
+	require.Equal(t, &buf[1], &out[0])
+	require.Equal(t, 3, cap(out))
+
+	require.Error(t, New(LengthDelimBytesNoCopy(&out)).Decode(buf[:2]))
+}
+
+// The hot paths below must not allocate.
+
+func TestZeroAllocs(t *testing.T) {
+	var (
+		b   byte
+		u16 uint16
+		u32 uint32
+		u64 uint64
+		i64 int64
+		r   rune
+		s   string
+		bs  []byte
+		arr [16]byte
+	)
+	newEncoding := func() Encoding {
+		return New(
+			Byte(&b),
+			FixedUint16(&u16),
+			FixedUint32(&u32),
+			FixedUint64(&u64),
+			Uvarint32(&u32),
+			Uvarint64(&u64),
+			OrdUvarint64(&u64),
+			OrdVarint64(&i64),
+			Rune(&r),
+			LengthDelimString(&s),
+			LengthDelimBytesNoCopy(&bs),
+			EscapedString(&s),
+			Bytes16(&arr),
+		)
+	}
+	b, u16, u32, u64, i64, r = 1, 2, 3, 4, -5, 'x'
+	s = "hello"
+	bs = []byte("world")
+
+	enc := newEncoding()
+	encoded := enc.Encode()
+
+	t.Run("AppendTo", func(t *testing.T) {
+		buf := make([]byte, 0, len(encoded))
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = enc.AppendTo(buf[:0])
+		})
+		require.Zero(t, allocs)
+		require.Equal(t, encoded, buf)
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		// Strings have to be allocated, so leave them out.
+		dec := New(
+			Byte(&b),
+			FixedUint16(&u16),
+			FixedUint32(&u32),
+			FixedUint64(&u64),
+			Uvarint32(&u32),
+			Uvarint64(&u64),
+			OrdUvarint64(&u64),
+			OrdVarint64(&i64),
+			Rune(&r),
+			LengthDelimBytesNoCopy(&bs),
+			Bytes16(&arr),
+		)
+		decBuf := dec.Encode()
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = dec.Decode(decBuf)
+		})
+		require.Zero(t, allocs)
+		require.NoError(t, dec.Decode(decBuf))
+	})
+
+	t.Run("TupleEncode", func(t *testing.T) {
+		tup := NewTuple(OrdUvarint64(&u64), OrdVarint64(&i64), EscapedString(&s))
+		buf := make([]byte, 0, 64)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = tup.AppendTo(buf[:0])
+		})
+		require.Zero(t, allocs)
+		require.Equal(t, tup.Encode(), buf)
+	})
+}
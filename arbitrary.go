@@ -0,0 +1,145 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"unicode/utf8"
+)
+
+// ErrNoArbitrary is returned by Arbitrary for items that it can't generate values for, such as
+// Signed and Encrypted, whose valid encodings depend on more than the values they hold.
+var ErrNoArbitrary = errors.New("encode: item can't generate arbitrary values")
+
+// Implemented by items that can set their destinations to a random value they can represent.
+type arbitrarier interface {
+	arbitrary(r *rand.Rand)
+}
+
+// The longest byte string or string generated by Arbitrary.
+const arbitraryMaxLen = 64
+
+// Sets the values that enc's items decode into to random values drawn from r, for round-trip and
+// ordering tests. Values are generated by item type, so they respect the item's widths, and are
+// spread across magnitudes and lengths rather than clustered near zero.
+//
+// If any item doesn't support this, returns an error wrapping ErrNoArbitrary and leaves every
+// value unchanged. Items that wrap another, such as Default and PostDecode, generate the wrapped
+// item's value.
+func (enc Encoding) Arbitrary(r *rand.Rand) error {
+	gens := make([]arbitrarier, len(enc.items))
+	for i, item := range enc.items {
+		gen, ok := arbitraryFor(item)
+		if !ok {
+			return fmt.Errorf("item %d (%T): %w", i, item, ErrNoArbitrary)
+		}
+		gens[i] = gen
+	}
+	for _, gen := range gens {
+		gen.arbitrary(r)
+	}
+	return nil
+}
+
+// Returns the generator for item, or false if item or any of the bit items it packs can't generate
+// values.
+func arbitraryFor(item Item) (arbitrarier, bool) {
+	gen, ok := findItem[arbitrarier](item)
+	if packed, isPacked := gen.(bitpacked); ok && isPacked {
+		for _, bitItem := range packed.items {
+			if _, ok := bitItem.(arbitrarier); !ok {
+				return nil, false
+			}
+		}
+	}
+	return gen, ok
+}
+
+// Returns a random value of at most n bits, with the bit length itself chosen uniformly so that
+// small and large values are both common.
+func arbitraryBits(r *rand.Rand, n int) uint64 {
+	k := r.Intn(n + 1)
+	if k == 64 {
+		return r.Uint64()
+	}
+	return r.Uint64() & (1<<k - 1)
+}
+
+// Returns a random signed value of at most n bits including the sign.
+func arbitrarySigned(r *rand.Rand, n int) int64 {
+	v := int64(arbitraryBits(r, n-1))
+	if r.Intn(2) == 0 {
+		return -v - 1
+	}
+	return v
+}
+
+// Returns a random byte string. Bytes that tend to need special handling, such as delimiters and
+// varint continuation bits, are produced more often than at random.
+func arbitraryBytes(r *rand.Rand) []byte {
+	interesting := [...]byte{0x00, 0x01, 0x7F, 0x80, 0xFF}
+	b := make([]byte, r.Intn(arbitraryMaxLen+1))
+	for i := range b {
+		if r.Intn(4) == 0 {
+			b[i] = interesting[r.Intn(len(interesting))]
+		} else {
+			b[i] = byte(r.Intn(256))
+		}
+	}
+	return b
+}
+
+func (e padding) arbitrary(r *rand.Rand)       {}
+func (e encByte) arbitrary(r *rand.Rand)       { *e.v = byte(r.Intn(256)) }
+func (e encBool) arbitrary(r *rand.Rand)       { *e.v = r.Intn(2) == 1 }
+func (e fixedUint16) arbitrary(r *rand.Rand)   { *e.v = uint16(arbitraryBits(r, 16)) }
+func (e fixedUint24) arbitrary(r *rand.Rand)   { *e.v = uint32(arbitraryBits(r, 24)) }
+func (e fixedUint32) arbitrary(r *rand.Rand)   { *e.v = uint32(arbitraryBits(r, 32)) }
+func (e fixedUint48) arbitrary(r *rand.Rand)   { *e.v = arbitraryBits(r, 48) }
+func (e fixedUint64) arbitrary(r *rand.Rand)   { *e.v = arbitraryBits(r, 64) }
+func (e orderedUint16) arbitrary(r *rand.Rand) { *e.v = uint16(arbitraryBits(r, 16)) }
+func (e orderedUint32) arbitrary(r *rand.Rand) { *e.v = uint32(arbitraryBits(r, 32)) }
+func (e orderedUint64) arbitrary(r *rand.Rand) { *e.v = arbitraryBits(r, 64) }
+func (e uvarint32) arbitrary(r *rand.Rand)     { *e.v = uint32(arbitraryBits(r, 32)) }
+func (e uvarint64) arbitrary(r *rand.Rand)     { *e.v = arbitraryBits(r, 64) }
+func (e ordUvarint64) arbitrary(r *rand.Rand)  { *e.v = arbitraryBits(r, 64) }
+func (e ordVarint32) arbitrary(r *rand.Rand)   { *e.v = int32(arbitrarySigned(r, 32)) }
+func (e ordVarint64) arbitrary(r *rand.Rand)   { *e.v = arbitrarySigned(r, 64) }
+func (e bytes16) arbitrary(r *rand.Rand)       { r.Read(e.v[:]) }
+func (e bytes32) arbitrary(r *rand.Rand)       { r.Read(e.v[:]) }
+
+func (e delimBytes) arbitrary(r *rand.Rand)          { *e.v = arbitraryBytes(r) }
+func (e unorderedDelimBytes) arbitrary(r *rand.Rand) { e.e.arbitrary(r) }
+func (e escapedString) arbitrary(r *rand.Rand)       { *e.v = string(arbitraryBytes(r)) }
+func (e lengthDelimBytes) arbitrary(r *rand.Rand)    { *e.v = arbitraryBytes(r) }
+func (e lengthDelimString) arbitrary(r *rand.Rand)   { *e.v = string(arbitraryBytes(r)) }
+
+func (e encRune) arbitrary(r *rand.Rand) {
+	for {
+		c := rune(arbitraryBits(r, 21))
+		// Surrogates and values past MaxRune encode as RuneError, so they don't round-trip.
+		if utf8.ValidRune(c) {
+			*e.v = c
+			return
+		}
+	}
+}
+
+func (e bitpacked) arbitrary(r *rand.Rand) {
+	for _, item := range e.items {
+		item.(arbitrarier).arbitrary(r)
+	}
+}
+
+func (e bitPadding) arbitrary(r *rand.Rand) {}
+func (e bitItem) arbitrary(r *rand.Rand)    { *e.v = r.Intn(2) == 1 }
+func (e bits8) arbitrary(r *rand.Rand)      { *e.v = uint8(arbitraryBits(r, e.n)) }
+func (e bits16) arbitrary(r *rand.Rand)     { *e.v = uint16(arbitraryBits(r, e.n)) }
+func (e bits32) arbitrary(r *rand.Rand)     { *e.v = uint32(arbitraryBits(r, e.n)) }
+func (e bits64) arbitrary(r *rand.Rand)     { *e.v = arbitraryBits(r, e.n) }
+
+func (e bitFlags) arbitrary(r *rand.Rand) {
+	for _, v := range e.v {
+		*v = r.Intn(2) == 1
+	}
+}
@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArbitrary(t *testing.T) {
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		var (
+			a  uint32
+			b  int32
+			c  int64
+			d  rune
+			e  [16]byte
+			f  []byte
+			g  string
+			h  uint16
+			fl [3]bool
+		)
+		enc := New(
+			FixedUint24(&a),
+			OrdVarint32(&b),
+			OrdVarint64(&c),
+			Rune(&d),
+			Bytes16(&e),
+			DelimBytes(&f, 0x7F),
+			Default(EscapedString(&g), func() { g = "x" }),
+			Bitpacked(Bits16(&h, 11), BitFlags(&fl[0], &fl[1], &fl[2]), BitPadding(2)),
+		)
+		require.NoError(t, enc.Arbitrary(r))
+		require.Less(t, a, uint32(1<<24))
+		require.Less(t, h, uint16(1<<11))
+
+		b1 := enc.Encode()
+		require.NoError(t, enc.Decode(b1))
+		require.Equal(t, b1, enc.Encode())
+	})
+
+	var present bool
+	var x byte
+	enc := New(Bitpacked(
+		BitTLV(4, 4, 4, nil, BitTagField(1, &present, Bits8(&x, 4))),
+		BitPadding(4),
+	))
+	require.ErrorIs(t, enc.Arbitrary(rand.New(rand.NewSource(0))), ErrNoArbitrary)
+}
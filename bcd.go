@@ -0,0 +1,142 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrInvalidBCD = errors.New("encode: invalid binary-coded decimal digit")
+var ErrOverflowBCD = errors.New("encode: binary-coded decimal overflows uint64")
+
+// Encode v, which must be exactly n decimal digits, as packed binary-coded decimal: two digits per
+// byte, high-order nibble first, taking (n+1)/2 bytes. When n is odd, the first nibble is a padding
+// zero, as in ISO 8583.
+//
+// Encode panics if v isn't n digits.
+func BCD(v *string, n int) TupleItem {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid n=%d, must be positive", n))
+	}
+	return bcd{v, n}
+}
+
+type bcd struct {
+	v *string
+	n int
+}
+
+func (e bcd) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e bcd) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e bcd) SizeTuple(last bool) int                 { return e.Size() }
+func (e bcd) OrderPreserving()                        {}
+func (e bcd) Encode(buf []byte) {
+	s := *e.v
+	if len(s) != e.n {
+		panic(fmt.Sprintf("encode: BCD needs %d digits, got %q", e.n, s))
+	}
+	pad := e.n % 2
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			panic(fmt.Sprintf("encode: BCD needs %d digits, got %q", e.n, s))
+		}
+		putNibble(buf, i+pad, c-'0')
+	}
+}
+func (e bcd) Size() int {
+	return (e.n + 1) / 2
+}
+func (e bcd) Decode(buf []byte) error {
+	if len(buf) < e.Size() {
+		return io.ErrUnexpectedEOF
+	}
+	pad := e.n % 2
+	if pad == 1 && getNibble(buf, 0) != 0 {
+		return ErrInvalidBCD
+	}
+	digits := make([]byte, e.n)
+	for i := range digits {
+		d := getNibble(buf, i+pad)
+		if d > 9 {
+			return ErrInvalidBCD
+		}
+		digits[i] = '0' + d
+	}
+	*e.v = string(digits)
+	return nil
+}
+
+// Encode v as n packed binary-coded decimal digits, the same as BCD would encode v written in
+// decimal with leading zeroes. n can be at most 20, which is enough for any uint64.
+//
+// Encode panics if v has more than n digits.
+func BCDUint64(v *uint64, n int) TupleItem {
+	if n <= 0 || n > 20 {
+		panic(fmt.Sprintf("invalid n=%d, must be in [1, 20]", n))
+	}
+	return bcdUint64{v, n}
+}
+
+type bcdUint64 struct {
+	v *uint64
+	n int
+}
+
+func (e bcdUint64) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e bcdUint64) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e bcdUint64) SizeTuple(last bool) int                 { return e.Size() }
+func (e bcdUint64) OrderPreserving()                        {}
+func (e bcdUint64) Encode(buf []byte) {
+	x := *e.v
+	pad := e.n % 2
+	for i := e.n - 1; i >= 0; i-- {
+		putNibble(buf, i+pad, byte(x%10))
+		x /= 10
+	}
+	if x != 0 {
+		panic(fmt.Sprintf("encode: %d has more than %d digits", *e.v, e.n))
+	}
+}
+func (e bcdUint64) Size() int {
+	return (e.n + 1) / 2
+}
+func (e bcdUint64) Decode(buf []byte) error {
+	if len(buf) < e.Size() {
+		return io.ErrUnexpectedEOF
+	}
+	pad := e.n % 2
+	if pad == 1 && getNibble(buf, 0) != 0 {
+		return ErrInvalidBCD
+	}
+	x := uint64(0)
+	for i := 0; i < e.n; i++ {
+		d := getNibble(buf, i+pad)
+		if d > 9 {
+			return ErrInvalidBCD
+		}
+		if x > (math.MaxUint64-uint64(d))/10 {
+			return ErrOverflowBCD
+		}
+		x = x*10 + uint64(d)
+	}
+	*e.v = x
+	return nil
+}
+
+// Sets the i-th nibble of buf, counting from the high-order nibble of buf[0].
+func putNibble(buf []byte, i int, d byte) {
+	if i%2 == 0 {
+		buf[i/2] = buf[i/2]&0x0F | d<<4
+	} else {
+		buf[i/2] = buf[i/2]&0xF0 | d
+	}
+}
+
+func getNibble(buf []byte, i int) byte {
+	if i%2 == 0 {
+		return buf[i/2] >> 4
+	}
+	return buf[i/2] & 0x0F
+}
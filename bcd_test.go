@@ -0,0 +1,64 @@
+package encode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBCD(t *testing.T) {
+	s := "12345"
+	enc := New(BCD(&s, 5))
+	b := enc.Encode()
+	require.Equal(t, []byte{0x01, 0x23, 0x45}, b)
+	s = ""
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, "12345", s)
+
+	s = "0042"
+	require.Equal(t, []byte{0x00, 0x42}, New(BCD(&s, 4)).Encode())
+
+	s = "12a4"
+	require.Panics(t, func() { New(BCD(&s, 4)).Encode() })
+	s = "123"
+	require.Panics(t, func() { New(BCD(&s, 4)).Encode() })
+
+	require.Equal(t, ErrInvalidBCD, New(BCD(&s, 4)).Decode([]byte{0x1A, 0x00}))
+	require.Equal(t, ErrInvalidBCD, New(BCD(&s, 3)).Decode([]byte{0x11, 0x00}))
+}
+
+func TestBCDUint64(t *testing.T) {
+	check := func(x uint64, n int, expected []byte) {
+		enc := New(BCDUint64(&x, n))
+		b := enc.Encode()
+		require.Equal(t, expected, b)
+		x2 := x
+		x = 0
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x2, x)
+
+		s := ""
+		require.NoError(t, New(BCD(&s, n)).Decode(b))
+		require.Equal(t, b, New(BCD(&s, n)).Encode())
+	}
+
+	check(0, 1, []byte{0x00})
+	check(7, 3, []byte{0x00, 0x07})
+	check(1234, 4, []byte{0x12, 0x34})
+	check(math.MaxUint64, 20, []byte{0x18, 0x44, 0x67, 0x44, 0x07, 0x37, 0x09, 0x55, 0x16, 0x15})
+
+	x := uint64(123)
+	require.Panics(t, func() { New(BCDUint64(&x, 2)).Encode() })
+
+	require.Equal(
+		t,
+		ErrOverflowBCD,
+		New(BCDUint64(&x, 20)).Decode([]byte{0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99}),
+	)
+	require.Equal(
+		t,
+		ErrOverflowBCD,
+		New(BCDUint64(&x, 20)).Decode([]byte{0x18, 0x44, 0x67, 0x44, 0x07, 0x37, 0x09, 0x55, 0x16, 0x16}),
+	)
+}
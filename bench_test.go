@@ -0,0 +1,220 @@
+package encode
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type benchItem struct {
+	name string
+	item Item
+}
+
+func benchItems() []benchItem {
+	var (
+		b        byte   = 0x5a
+		boolean         = true
+		u16      uint16 = 0xbeef
+		u32      uint32 = 0xdeadbeef
+		u64      uint64 = 0xdeadbeefcafef00d
+		smallU64 uint64 = 100
+		i64      int64  = -1234567890
+		i32      int32  = -12345
+		r               = '☃'
+		short           = "hello, world"
+		long            = string(make([]byte, 1024))
+		bs              = []byte("hello\x00world")
+		b16             [16]byte
+		b32             [32]byte
+		f16      float32 = 1.5
+		u128             = Uint128{Hi: math.MaxUint64, Lo: 42}
+		ts               = time.Unix(1600000000, 123456789)
+	)
+	return []benchItem{
+		{"Byte", Byte(&b)},
+		{"Bool", Bool(&boolean)},
+		{"FixedUint16", FixedUint16(&u16)},
+		{"FixedUint32", FixedUint32(&u32)},
+		{"FixedUint64", FixedUint64(&u64)},
+		{"NativeUint64", NativeUint64(&u64)},
+		{"Uvarint32", Uvarint32(&u32)},
+		{"Uvarint64/Small", Uvarint64(&smallU64)},
+		{"Uvarint64/Large", Uvarint64(&u64)},
+		{"OrdUvarint64/Small", OrdUvarint64(&smallU64)},
+		{"OrdUvarint64/Large", OrdUvarint64(&u64)},
+		{"OrdVarint64", OrdVarint64(&i64)},
+		{"OrdVarint32", OrdVarint32(&i32)},
+		{"Rune", Rune(&r)},
+		{"EscapedBytes", EscapedBytes(&bs)},
+		{"EscapedString/Short", EscapedString(&short)},
+		{"EscapedString/Long", EscapedString(&long)},
+		{"LengthDelimString/Short", LengthDelimString(&short)},
+		{"LengthDelimString/Long", LengthDelimString(&long)},
+		{"Bytes16", Bytes16(&b16)},
+		{"Bytes32", Bytes32(&b32)},
+		{"Float16", Float16(&f16)},
+		{"FixedUint128", FixedUint128(&u128)},
+		{"ReverseTimestamp", ReverseTimestamp(&ts)},
+	}
+}
+
+func BenchmarkItemEncode(b *testing.B) {
+	for _, c := range benchItems() {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			buf := make([]byte, c.item.Size())
+			b.SetBytes(int64(len(buf)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.item.Encode(buf[:c.item.Size()])
+			}
+		})
+	}
+}
+
+func BenchmarkItemDecode(b *testing.B) {
+	for _, c := range benchItems() {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			buf := make([]byte, c.item.Size())
+			c.item.Encode(buf)
+			b.SetBytes(int64(len(buf)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := c.item.Decode(buf)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type benchRecord struct {
+	ID      uint64
+	Created time.Time
+	Name    string
+	Score   int64
+	Flags   byte
+	Payload []byte
+}
+
+func (r *benchRecord) encoding() Encoding {
+	return New(
+		OrdUvarint64(&r.ID),
+		ReverseTimestamp(&r.Created),
+		LengthDelimString(&r.Name),
+		OrdVarint64(&r.Score),
+		Byte(&r.Flags),
+		LengthDelimBytes(&r.Payload),
+	)
+}
+
+func (r *benchRecord) key() Tuple {
+	return NewTuple(
+		OrdUvarint64(&r.ID),
+		EscapedString(&r.Name),
+		OrdVarint64(&r.Score),
+	)
+}
+
+func newBenchRecord() benchRecord {
+	return benchRecord{
+		ID:      123456789,
+		Created: time.Unix(1600000000, 0),
+		Name:    "some record name",
+		Score:   -42,
+		Flags:   0x3,
+		Payload: make([]byte, 256),
+	}
+}
+
+func BenchmarkRecordEncode(b *testing.B) {
+	r := newBenchRecord()
+	enc := r.encoding()
+	b.SetBytes(int64(len(enc.Encode())))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = enc.Encode()
+	}
+}
+
+func BenchmarkRecordDecode(b *testing.B) {
+	r := newBenchRecord()
+	buf := r.encoding().Encode()
+	var out benchRecord
+	enc := out.encoding()
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := enc.Decode(buf)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTupleEncode(b *testing.B) {
+	r := newBenchRecord()
+	t := r.key()
+	b.SetBytes(int64(len(t.Encode())))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = t.Encode()
+	}
+}
+
+func BenchmarkTupleDecode(b *testing.B) {
+	r := newBenchRecord()
+	buf := r.key().Encode()
+	var out benchRecord
+	t := out.key()
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := t.Decode(buf)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// A record made of many tiny fields, where the per-item overhead dominates.
+func newSmallFieldsEncoding() Encoding {
+	var (
+		flag bool
+		b    byte
+		u16  uint16
+		u32  uint32
+		u64  uint64
+	)
+	var items []Item
+	for i := 0; i < 8; i++ {
+		items = append(items, Bool(&flag), Byte(&b), FixedUint16(&u16), FixedUint32(&u32), FixedUint64(&u64))
+	}
+	return New(items...)
+}
+
+func BenchmarkSmallFieldsEncode(b *testing.B) {
+	enc := newSmallFieldsEncoding()
+	buf := make([]byte, 0, len(enc.Encode()))
+	b.SetBytes(int64(cap(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = enc.AppendTo(buf[:0])
+	}
+}
+
+func BenchmarkSmallFieldsDecode(b *testing.B) {
+	enc := newSmallFieldsEncoding()
+	buf := enc.Encode()
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := enc.Decode(buf)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
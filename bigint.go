@@ -0,0 +1,48 @@
+package encode
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Encode v as its big endian magnitude, left-padded with zeroes to exactly nBytes bytes. This is the
+// usual encoding of fixed-size cryptographic values such as field elements and scalars. Since the
+// width is fixed, the encoding sorts in the same order as the value.
+//
+// v must not be negative, and panics when encoding if v doesn't fit in nBytes.
+func FixedBigInt(v *big.Int, nBytes int) TupleItem {
+	if nBytes < 1 {
+		panic(fmt.Sprintf("invalid nBytes=%d, must be at least 1", nBytes))
+	}
+	return fixedBigInt{v: v, n: nBytes}
+}
+
+type fixedBigInt struct {
+	v *big.Int
+	n int
+}
+
+func (e fixedBigInt) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e fixedBigInt) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e fixedBigInt) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedBigInt) OrderPreserving()                        {}
+func (e fixedBigInt) Encode(buf []byte) {
+	if e.v.Sign() < 0 {
+		panic(fmt.Sprintf("FixedBigInt can't encode negative %s", e.v))
+	}
+	if byteLen(e.v) > e.n {
+		panic(fmt.Sprintf("%s does not fit in %d bytes", e.v, e.n))
+	}
+	e.v.FillBytes(buf[:e.n])
+}
+func (e fixedBigInt) Size() int {
+	return e.n
+}
+func (e fixedBigInt) Decode(buf []byte) error {
+	if len(buf) < e.n {
+		return io.ErrUnexpectedEOF
+	}
+	e.v.SetBytes(buf[:e.n])
+	return nil
+}
@@ -0,0 +1,32 @@
+package encode
+
+import (
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedBigInt(t *testing.T) {
+	x := big.NewInt(0x0102)
+	enc := NewTuple(FixedBigInt(x, 4))
+	b := enc.Encode()
+	require.Equal(t, []byte{0x00, 0x00, 0x01, 0x02}, b)
+
+	x.SetInt64(0)
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, int64(0x0102), x.Int64())
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(b[:3]))
+
+	// The order of secp256k1, which takes exactly 32 bytes.
+	n, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	b = New(FixedBigInt(n, 32)).Encode()
+	require.Len(t, b, 32)
+	y := new(big.Int)
+	require.NoError(t, New(FixedBigInt(y, 32)).Decode(b))
+	require.Equal(t, 0, n.Cmp(y))
+
+	require.Panics(t, func() { New(FixedBigInt(n, 31)).Encode() })
+	require.Panics(t, func() { New(FixedBigInt(big.NewInt(-1), 4)).Encode() })
+}
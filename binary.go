@@ -0,0 +1,381 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Encode v following the same rules as binary.Write and binary.Read, so that code using those can
+// move to this package without changing the bytes it produces. v must be a non-nil pointer to a
+// bool, fixed-size number, or an array, slice, or struct of those. Fields are laid out in order
+// using order. Fields named _ are written as zeroes and skipped when decoding. Slices without a tag
+// are, as in binary.Read, decoded into their existing length.
+//
+// Struct fields may additionally be tagged to use this package's variable-length encodings:
+//
+//   `encode:"uvarint"`   an unsigned integer (including uint) as in Uvarint64
+//   `encode:"varint"`    a signed integer (including int) as in binary.PutVarint
+//   `encode:"lendelim"`  a string, or a slice prefixed with its uvarint length
+//   `encode:"-"`         the field is skipped entirely
+//
+// Decoding returns ErrNonCanonical for varints and lengths that aren't encoded minimally. Binary
+// panics if v's type can't be encoded.
+//
+// Building with the encode_unsafe tag lets Binary copy values whose memory layout matches their
+// encoding directly, which is much faster for large arrays and structs of numbers.
+func Binary(v interface{}, order binary.ByteOrder) Item {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic(fmt.Sprintf("encode: Binary requires a non-nil pointer, got %T", v))
+	}
+	checkBinaryType(rv.Type().Elem(), "")
+	if item, ok := rawBinary(rv.Elem(), order); ok {
+		return item
+	}
+	return binaryItem{v: rv.Elem(), order: order}
+}
+
+type binaryItem struct {
+	v     reflect.Value
+	order binary.ByteOrder
+}
+
+func (e binaryItem) Encode(buf []byte) {
+	binaryEncode(buf, e.v, "", e.order)
+}
+func (e binaryItem) Size() int {
+	return binarySize(e.v, "")
+}
+func (e binaryItem) Decode(buf []byte) error {
+	_, err := binaryDecode(buf, e.v, "", e.order)
+	return err
+}
+
+func checkBinaryType(t reflect.Type, tag string) {
+	switch tag {
+	case "":
+		switch t.Kind() {
+		case reflect.Bool,
+			reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+			return
+		case reflect.Array, reflect.Slice:
+			checkBinaryType(t.Elem(), "")
+			return
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				fieldTag := f.Tag.Get("encode")
+				if fieldTag == "-" {
+					continue
+				}
+				if f.PkgPath != "" && f.Name != "_" {
+					panic(fmt.Sprintf("encode: Binary can't decode into unexported field %s.%s", t, f.Name))
+				}
+				checkBinaryType(f.Type, fieldTag)
+			}
+			return
+		}
+	case "uvarint":
+		switch t.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return
+		}
+	case "varint":
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return
+		}
+	case "lendelim":
+		switch t.Kind() {
+		case reflect.String:
+			return
+		case reflect.Slice:
+			checkBinaryType(t.Elem(), "")
+			return
+		}
+	default:
+		panic(fmt.Sprintf("encode: unknown tag encode:%q", tag))
+	}
+	panic(fmt.Sprintf("encode: Binary can't encode %s with tag encode:%q", t, tag))
+}
+
+func binarySize(v reflect.Value, tag string) int {
+	switch tag {
+	case "uvarint":
+		return uvarintSize(v.Uint())
+	case "varint":
+		return varintSize(v.Int())
+	case "lendelim":
+		return uvarintSize(uint64(v.Len())) + binarySize(v, "")
+	}
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4
+	case reflect.Int64, reflect.Uint64, reflect.Float64, reflect.Complex64:
+		return 8
+	case reflect.Complex128:
+		return 16
+	case reflect.String:
+		return v.Len()
+	case reflect.Array, reflect.Slice:
+		n := 0
+		for i := 0; i < v.Len(); i++ {
+			n += binarySize(v.Index(i), "")
+		}
+		return n
+	case reflect.Struct:
+		n := 0
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			fieldTag := t.Field(i).Tag.Get("encode")
+			if fieldTag == "-" {
+				continue
+			}
+			n += binarySize(v.Field(i), fieldTag)
+		}
+		return n
+	}
+	panic("unreachable")
+}
+
+// Encodes v into buf, returning the number of bytes used.
+func binaryEncode(buf []byte, v reflect.Value, tag string, order binary.ByteOrder) int {
+	switch tag {
+	case "uvarint":
+		return binary.PutUvarint(buf, v.Uint())
+	case "varint":
+		return binary.PutVarint(buf, v.Int())
+	case "lendelim":
+		n := binary.PutUvarint(buf, uint64(v.Len()))
+		return n + binaryEncode(buf[n:], v, "", order)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			buf[0] = 1
+		}
+		return 1
+	case reflect.Int8:
+		buf[0] = byte(v.Int())
+		return 1
+	case reflect.Uint8:
+		buf[0] = byte(v.Uint())
+		return 1
+	case reflect.Int16:
+		order.PutUint16(buf, uint16(v.Int()))
+		return 2
+	case reflect.Uint16:
+		order.PutUint16(buf, uint16(v.Uint()))
+		return 2
+	case reflect.Int32:
+		order.PutUint32(buf, uint32(v.Int()))
+		return 4
+	case reflect.Uint32:
+		order.PutUint32(buf, uint32(v.Uint()))
+		return 4
+	case reflect.Int64:
+		order.PutUint64(buf, uint64(v.Int()))
+		return 8
+	case reflect.Uint64:
+		order.PutUint64(buf, v.Uint())
+		return 8
+	case reflect.Float32:
+		order.PutUint32(buf, math.Float32bits(float32(v.Float())))
+		return 4
+	case reflect.Float64:
+		order.PutUint64(buf, math.Float64bits(v.Float()))
+		return 8
+	case reflect.Complex64:
+		c := v.Complex()
+		order.PutUint32(buf, math.Float32bits(float32(real(c))))
+		order.PutUint32(buf[4:], math.Float32bits(float32(imag(c))))
+		return 8
+	case reflect.Complex128:
+		c := v.Complex()
+		order.PutUint64(buf, math.Float64bits(real(c)))
+		order.PutUint64(buf[8:], math.Float64bits(imag(c)))
+		return 16
+	case reflect.String:
+		return copy(buf, v.String())
+	case reflect.Array, reflect.Slice:
+		n := 0
+		for i := 0; i < v.Len(); i++ {
+			n += binaryEncode(buf[n:], v.Index(i), "", order)
+		}
+		return n
+	case reflect.Struct:
+		n := 0
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			fieldTag := f.Tag.Get("encode")
+			if fieldTag == "-" {
+				continue
+			}
+			if f.Name == "_" {
+				// Already zeroed.
+				n += binarySize(v.Field(i), fieldTag)
+				continue
+			}
+			n += binaryEncode(buf[n:], v.Field(i), fieldTag, order)
+		}
+		return n
+	}
+	panic("unreachable")
+}
+
+// Decodes buf into v, returning the number of bytes consumed.
+func binaryDecode(buf []byte, v reflect.Value, tag string, order binary.ByteOrder) (int, error) {
+	switch tag {
+	case "uvarint":
+		x, n := binary.Uvarint(buf)
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if n < 0 {
+			return 0, ErrOverflowVarint
+		}
+		if n != uvarintSize(x) {
+			// Size is the minimal length, so accepting padding would leave the next item misaligned.
+			return 0, ErrNonCanonical
+		}
+		if v.OverflowUint(x) {
+			return 0, ErrOverflowVarint
+		}
+		v.SetUint(x)
+		return n, nil
+	case "varint":
+		x, n := binary.Varint(buf)
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if n < 0 {
+			return 0, ErrOverflowVarint
+		}
+		if n != varintSize(x) {
+			return 0, ErrNonCanonical
+		}
+		if v.OverflowInt(x) {
+			return 0, ErrOverflowVarint
+		}
+		v.SetInt(x)
+		return n, nil
+	case "lendelim":
+		l, n := binary.Uvarint(buf)
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if n < 0 {
+			return 0, ErrOverflowVarint
+		}
+		if n != uvarintSize(l) {
+			return 0, ErrNonCanonical
+		}
+		if v.Kind() == reflect.String {
+			if uint64(len(buf[n:])) < l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			v.SetString(string(buf[n : n+int(l)]))
+			return n + int(l), nil
+		}
+		// Check before allocating, so that a corrupted length can't cause a huge allocation. A zero
+		// element is as small as an element can be. Elements can be empty, for example untagged
+		// slices, but then bound them as though they took a byte each anyway.
+		elemSize := binarySize(reflect.Zero(v.Type().Elem()), "")
+		if elemSize == 0 {
+			elemSize = 1
+		}
+		if l > uint64(len(buf[n:])/elemSize) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		v.Set(reflect.MakeSlice(v.Type(), int(l), int(l)))
+		m, err := binaryDecode(buf[n:], v, "", order)
+		return n + m, err
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		n := 0
+		for i := 0; i < v.Len(); i++ {
+			m, err := binaryDecode(buf[n:], v.Index(i), "", order)
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		}
+		return n, nil
+	case reflect.Struct:
+		n := 0
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			fieldTag := f.Tag.Get("encode")
+			if fieldTag == "-" {
+				continue
+			}
+			if f.Name == "_" {
+				m := binarySize(v.Field(i), fieldTag)
+				if len(buf[n:]) < m {
+					return 0, io.ErrUnexpectedEOF
+				}
+				n += m
+				continue
+			}
+			m, err := binaryDecode(buf[n:], v.Field(i), fieldTag, order)
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		}
+		return n, nil
+	}
+
+	size := binarySize(v, "")
+	if len(buf) < size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(buf[0] != 0)
+	case reflect.Int8:
+		v.SetInt(int64(int8(buf[0])))
+	case reflect.Uint8:
+		v.SetUint(uint64(buf[0]))
+	case reflect.Int16:
+		v.SetInt(int64(int16(order.Uint16(buf))))
+	case reflect.Uint16:
+		v.SetUint(uint64(order.Uint16(buf)))
+	case reflect.Int32:
+		v.SetInt(int64(int32(order.Uint32(buf))))
+	case reflect.Uint32:
+		v.SetUint(uint64(order.Uint32(buf)))
+	case reflect.Int64:
+		v.SetInt(int64(order.Uint64(buf)))
+	case reflect.Uint64:
+		v.SetUint(order.Uint64(buf))
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(order.Uint32(buf))))
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(order.Uint64(buf)))
+	case reflect.Complex64:
+		v.SetComplex(complex(
+			float64(math.Float32frombits(order.Uint32(buf))),
+			float64(math.Float32frombits(order.Uint32(buf[4:]))),
+		))
+	case reflect.Complex128:
+		v.SetComplex(complex(
+			math.Float64frombits(order.Uint64(buf)),
+			math.Float64frombits(order.Uint64(buf[8:])),
+		))
+	}
+	return size, nil
+}
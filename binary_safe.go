@@ -0,0 +1,13 @@
+//go:build !encode_unsafe
+
+package encode
+
+import (
+	"encoding/binary"
+	"reflect"
+)
+
+// Without the encode_unsafe build tag, Binary always goes through reflection. See binary_unsafe.go.
+func rawBinary(rv reflect.Value, order binary.ByteOrder) (Item, bool) {
+	return nil, false
+}
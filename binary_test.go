@@ -0,0 +1,114 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryMatchesStdlib(t *testing.T) {
+	type inner struct {
+		A int16
+		B [3]uint8
+	}
+	type fixed struct {
+		A bool
+		B int8
+		C uint32
+		_ [2]byte
+		D float64
+		E complex64
+		F inner
+	}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		v := fixed{
+			A: true,
+			B: -3,
+			C: 0xDEADBEEF,
+			D: 3.25,
+			E: complex(1.5, -2),
+			F: inner{A: -300, B: [3]uint8{1, 2, 3}},
+		}
+		var expected bytes.Buffer
+		require.NoError(t, binary.Write(&expected, order, &v))
+
+		item := Binary(&v, order)
+		require.Equal(t, binary.Size(&v), item.Size())
+		b := New(item).Encode()
+		require.Equal(t, expected.Bytes(), b)
+
+		var v2 fixed
+		require.NoError(t, New(Binary(&v2, order)).Decode(b))
+		require.Equal(t, v, v2)
+
+		s := []uint16{7, 8}
+		expected.Reset()
+		require.NoError(t, binary.Write(&expected, order, s))
+		b = New(Binary(&s, order)).Encode()
+		require.Equal(t, expected.Bytes(), b)
+
+		s2 := make([]uint16, 2)
+		require.NoError(t, New(Binary(&s2, order)).Decode(b))
+		require.Equal(t, s, s2)
+	}
+}
+
+func TestBinaryTags(t *testing.T) {
+	type tagged struct {
+		A uint   `encode:"uvarint"`
+		B int    `encode:"varint"`
+		C string `encode:"lendelim"`
+		D []byte `encode:"lendelim"`
+		E func() `encode:"-"`
+		F uint16
+	}
+
+	v := tagged{A: 300, B: -2, C: "hello", D: []byte{1, 2, 3}, F: 9}
+	enc := New(Binary(&v, binary.BigEndian))
+	b := enc.Encode()
+	require.Equal(t, []byte{
+		0xAC, 0x02,
+		0x03,
+		0x05, 'h', 'e', 'l', 'l', 'o',
+		0x03, 0x01, 0x02, 0x03,
+		0x00, 0x09,
+	}, b)
+
+	var v2 tagged
+	require.NoError(t, New(Binary(&v2, binary.BigEndian)).Decode(b))
+	require.Equal(t, v.A, v2.A)
+	require.Equal(t, v.B, v2.B)
+	require.Equal(t, v.C, v2.C)
+	require.Equal(t, v.D, v2.D)
+	require.Equal(t, v.F, v2.F)
+
+	// Padded varints and lengths would leave what follows decoded from the wrong place.
+	for _, padded := range [][]byte{
+		{0xAC, 0x82, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00},
+		{0xAC, 0x02, 0x83, 0x00, 0x00, 0x00, 0x00, 0x00},
+		{0xAC, 0x02, 0x03, 0x80, 0x00, 0x00, 0x00, 0x00},
+		{0xAC, 0x02, 0x03, 0x00, 0x80, 0x00, 0x00, 0x00},
+	} {
+		require.Equal(t, ErrNonCanonical, New(Binary(&v2, binary.BigEndian)).Decode(padded))
+	}
+
+	// The element count is bounded by the elements' real size.
+	var wide struct {
+		W []uint64 `encode:"lendelim"`
+	}
+	err := New(Binary(&wide, binary.BigEndian)).Decode([]byte{0x02, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+
+	require.Panics(t, func() {
+		var s struct{ A string }
+		Binary(&s, binary.BigEndian)
+	})
+	require.Panics(t, func() {
+		var s struct{ a uint16 }
+		Binary(&s, binary.BigEndian)
+	})
+}
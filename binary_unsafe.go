@@ -0,0 +1,119 @@
+//go:build encode_unsafe
+
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+// With the encode_unsafe build tag, Binary encodes and decodes values whose memory layout is
+// exactly their encoding by copying their memory, and then swapping the bytes of each field if
+// order isn't the native byte order. That is, structs and arrays of fixed-size numbers with no
+// padding, no bools, no fields named _, and no encode tags.
+
+// A multi-byte number within a value, whose bytes are reversed to change its byte order.
+type rawSwap struct {
+	offset int
+	width  int
+}
+
+func rawBinary(rv reflect.Value, order binary.ByteOrder) (Item, bool) {
+	var swaps []rawSwap
+	if !rawLayout(rv.Type(), 0, &swaps) {
+		return nil, false
+	}
+	if isNativeOrder(order) {
+		swaps = nil
+	}
+	return rawBinaryItem{
+		p:     rv.Addr().UnsafePointer(),
+		size:  int(rv.Type().Size()),
+		swaps: swaps,
+	}, true
+}
+
+func isNativeOrder(order binary.ByteOrder) bool {
+	var a, b [2]byte
+	order.PutUint16(a[:], 1)
+	binary.NativeEndian.PutUint16(b[:], 1)
+	return bytes.Equal(a[:], b[:])
+}
+
+// Reports whether t, at offset within the value, is laid out in memory as its encoding, and adds
+// its multi-byte numbers to swaps.
+func rawLayout(t reflect.Type, offset int, swaps *[]rawSwap) bool {
+	switch t.Kind() {
+	case reflect.Int8, reflect.Uint8:
+		return true
+	case reflect.Int16, reflect.Uint16, reflect.Int32, reflect.Uint32, reflect.Float32,
+		reflect.Int64, reflect.Uint64, reflect.Float64:
+		*swaps = append(*swaps, rawSwap{offset: offset, width: int(t.Size())})
+		return true
+	case reflect.Complex64, reflect.Complex128:
+		half := int(t.Size()) / 2
+		*swaps = append(*swaps, rawSwap{offset: offset, width: half}, rawSwap{offset: offset + half, width: half})
+		return true
+	case reflect.Array:
+		elemSize := int(t.Elem().Size())
+		for i := 0; i < t.Len(); i++ {
+			if !rawLayout(t.Elem(), offset+i*elemSize, swaps) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		end := 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Name == "_" || f.Tag.Get("encode") != "" || int(f.Offset) != end {
+				return false
+			}
+			if !rawLayout(f.Type, offset+end, swaps) {
+				return false
+			}
+			end += int(f.Type.Size())
+		}
+		return end == int(t.Size())
+	}
+	return false
+}
+
+type rawBinaryItem struct {
+	p     unsafe.Pointer
+	size  int
+	swaps []rawSwap
+}
+
+func (e rawBinaryItem) mem() []byte {
+	return unsafe.Slice((*byte)(e.p), e.size)
+}
+
+func (e rawBinaryItem) Encode(buf []byte) {
+	copy(buf, e.mem())
+	reverseEach(buf, e.swaps)
+}
+func (e rawBinaryItem) Size() int {
+	return e.size
+}
+func (e rawBinaryItem) Decode(buf []byte) error {
+	if len(buf) < e.size {
+		return io.ErrUnexpectedEOF
+	}
+	mem := e.mem()
+	copy(mem, buf)
+	reverseEach(mem, e.swaps)
+	return nil
+}
+
+func reverseEach(b []byte, swaps []rawSwap) {
+	for _, s := range swaps {
+		field := b[s.offset : s.offset+s.width]
+		for i, j := 0, len(field)-1; i < j; i, j = i+1, j-1 {
+			field[i], field[j] = field[j], field[i]
+		}
+	}
+}
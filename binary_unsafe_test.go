@@ -0,0 +1,63 @@
+//go:build encode_unsafe
+
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryUnsafe(t *testing.T) {
+	type inner struct {
+		A int16
+		B int16
+	}
+	type plain struct {
+		A uint64
+		B [2]inner
+		C float32
+		D complex64
+		E [4]uint8
+	}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		v := plain{
+			A: 0x0102030405060708,
+			B: [2]inner{{A: -300, B: 7}, {A: 1, B: -1}},
+			C: 1.5,
+			D: complex(2, -3),
+			E: [4]uint8{9, 8, 7, 6},
+		}
+		item := Binary(&v, order)
+		require.IsType(t, rawBinaryItem{}, item)
+
+		var expected bytes.Buffer
+		require.NoError(t, binary.Write(&expected, order, &v))
+		b := New(item).Encode()
+		require.Equal(t, expected.Bytes(), b)
+
+		var out plain
+		require.NoError(t, New(Binary(&out, order)).Decode(b))
+		require.Equal(t, v, out)
+		require.ErrorIs(t, New(Binary(&out, order)).Decode(b[:len(b)-1]), io.ErrUnexpectedEOF)
+	}
+
+	// Padding, bools, and tags all need the reflective path.
+	type padded struct {
+		A uint8
+		B uint32
+	}
+	require.IsType(t, binaryItem{}, Binary(&padded{}, binary.BigEndian))
+	type withBool struct {
+		A bool
+	}
+	require.IsType(t, binaryItem{}, Binary(&withBool{}, binary.BigEndian))
+	type tagged struct {
+		A uint32 `encode:"uvarint"`
+	}
+	require.IsType(t, binaryItem{}, Binary(&tagged{}, binary.BigEndian))
+}
@@ -30,12 +30,15 @@ func (e bitpacked) DecodeTuple(buf []byte, last bool) error { return e.Decode(bu
 func (e bitpacked) SizeTuple(last bool) int                 { return e.Size() }
 func (e bitpacked) OrderPreserving()                        {}
 func (b bitpacked) Encode(buf []byte) {
-	bitBuf := bitBuffer{b: buf, i: 0}
+	b.encodeScratch(buf, new(scratch))
+}
+func (b bitpacked) encodeScratch(buf []byte, s *scratch) {
+	s.bits = bitBuffer{b: buf, i: 0}
 	for _, item := range b.items {
-		item.encode(&bitBuf)
+		item.encode(&s.bits)
 	}
-	if bitBuf.i != b.sizeBits() {
-		panic(fmt.Sprintf("encode: sizeBits == %d, but wrote %d", b.sizeBits(), bitBuf.i))
+	if s.bits.i != b.sizeBits() {
+		panic(fmt.Sprintf("encode: sizeBits == %d, but wrote %d", b.sizeBits(), s.bits.i))
 	}
 }
 func (b bitpacked) Decode(buf []byte) error {
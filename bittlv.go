@@ -0,0 +1,195 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+var ErrUnknownTag = errors.New("encode: unknown TLV tag")
+
+// A field of BitTLV, see BitTagField.
+type BitTLVField struct {
+	tag     uint64
+	present *bool
+	item    BitpackItem
+}
+
+// Make a field for BitTLV with the given tag. The field is only encoded if *present is true, and
+// decoding sets *present to whether it appeared.
+func BitTagField(tag uint64, present *bool, item BitpackItem) BitTLVField {
+	return BitTLVField{tag: tag, present: present, item: item}
+}
+
+// One entry of a BitTLV whose tag doesn't belong to any of its fields. Value holds Bits bits,
+// packed most-significant first.
+type BitTLVEntry struct {
+	Tag   uint64
+	Bits  int
+	Value []byte
+}
+
+// A bit-granular version of TLV for use in Bitpacked, for payloads where every bit counts such as
+// LPWAN radio messages.
+//
+// Encodes the number of entries in countBits bits, followed by each field that is present and then
+// each entry in *unknown. An entry is its tag in tagBits bits, the length of its value in bits in
+// lenBits bits, and then the value. All three widths are part of the format, so countBits should
+// leave room for fields added later.
+//
+// Decoding accepts fields in any order, and appends entries with tags that don't match any field to
+// *unknown so that they're written back out when re-encoding. unknown may be nil, in which case
+// decoding an unknown tag returns ErrUnknownTag. Returns ErrInvalidTLV if a field's item doesn't
+// decode to exactly the length of its entry.
+//
+// Panics if any of the widths is not in [1, 64] or a tag doesn't fit in tagBits, or when encoding
+// if the number of entries or the size of a value doesn't fit.
+func BitTLV(countBits, tagBits, lenBits int, unknown *[]BitTLVEntry, fields ...BitTLVField) BitpackItem {
+	if countBits < 1 || countBits > 64 {
+		panic(fmt.Sprintf("invalid countBits=%d, must be in [1, 64]", countBits))
+	}
+	if tagBits < 1 || tagBits > 64 {
+		panic(fmt.Sprintf("invalid tagBits=%d, must be in [1, 64]", tagBits))
+	}
+	if lenBits < 1 || lenBits > 64 {
+		panic(fmt.Sprintf("invalid lenBits=%d, must be in [1, 64]", lenBits))
+	}
+	for _, f := range fields {
+		if !fitsBits(f.tag, tagBits) {
+			panic(fmt.Sprintf("tag %d does not fit in %d bits", f.tag, tagBits))
+		}
+	}
+	return bitTLV{
+		tagBits:   tagBits,
+		lenBits:   lenBits,
+		countBits: countBits,
+		unknown:   unknown,
+		fields:    fields,
+	}
+}
+
+type bitTLV struct {
+	tagBits   int
+	lenBits   int
+	countBits int
+	unknown   *[]BitTLVEntry
+	fields    []BitTLVField
+}
+
+func (e bitTLV) encode(b *bitBuffer) {
+	count := 0
+	for _, f := range e.fields {
+		if *f.present {
+			count++
+		}
+	}
+	if e.unknown != nil {
+		count += len(*e.unknown)
+	}
+	if !fitsBits(uint64(count), e.countBits) {
+		panic(fmt.Sprintf("%d entries do not fit in %d bits", count, e.countBits))
+	}
+	b.writeBits(uint64(count), e.countBits)
+	for _, f := range e.fields {
+		if !*f.present {
+			continue
+		}
+		size := f.item.size()
+		e.writeHeader(b, f.tag, size)
+		f.item.encode(b)
+	}
+	if e.unknown != nil {
+		for _, entry := range *e.unknown {
+			e.writeHeader(b, entry.Tag, entry.Bits)
+			for i := 0; i < entry.Bits; i += 8 {
+				n := minInt(8, entry.Bits-i)
+				b.writeBits(uint64(entry.Value[i/8]>>(8-n)), n)
+			}
+		}
+	}
+}
+func (e bitTLV) writeHeader(b *bitBuffer, tag uint64, size int) {
+	if !fitsBits(uint64(size), e.lenBits) {
+		panic(fmt.Sprintf("value of tag %d is %d bits, does not fit in %d bits", tag, size, e.lenBits))
+	}
+	b.writeBits(tag, e.tagBits)
+	b.writeBits(uint64(size), e.lenBits)
+}
+func (e bitTLV) decode(b *bitBuffer) error {
+	for _, f := range e.fields {
+		*f.present = false
+	}
+	count, err := b.readBits(e.countBits)
+	if err != nil {
+		return err
+	}
+	var unknown []BitTLVEntry
+	for i := uint64(0); i < count; i++ {
+		tag, err := b.readBits(e.tagBits)
+		if err != nil {
+			return err
+		}
+		size, err := b.readBits(e.lenBits)
+		if err != nil {
+			return err
+		}
+		if uint64(b.lenBits()-b.i) < size {
+			return io.ErrUnexpectedEOF
+		}
+		f, ok := e.field(tag)
+		if !ok {
+			if e.unknown == nil {
+				return ErrUnknownTag
+			}
+			entry := BitTLVEntry{Tag: tag, Bits: int(size), Value: make([]byte, (size+7)/8)}
+			for j := 0; j < entry.Bits; j += 8 {
+				n := minInt(8, entry.Bits-j)
+				x, _ := b.readBits(n)
+				entry.Value[j/8] = byte(x << (8 - n))
+			}
+			unknown = append(unknown, entry)
+			continue
+		}
+		start := b.i
+		err = f.item.decode(b)
+		if err != nil {
+			return err
+		}
+		if b.i-start != int(size) {
+			return ErrInvalidTLV
+		}
+		*f.present = true
+	}
+	if e.unknown != nil {
+		*e.unknown = unknown
+	}
+	return nil
+}
+func (e bitTLV) size() int {
+	size := e.countBits
+	for _, f := range e.fields {
+		if *f.present {
+			size += e.tagBits + e.lenBits + f.item.size()
+		}
+	}
+	if e.unknown != nil {
+		for _, entry := range *e.unknown {
+			size += e.tagBits + e.lenBits + entry.Bits
+		}
+	}
+	return size
+}
+func (e bitTLV) field(tag uint64) (BitTLVField, bool) {
+	for _, f := range e.fields {
+		if f.tag == tag {
+			return f, true
+		}
+	}
+	return BitTLVField{}, false
+}
+
+// Returns true if x can be written in n bits.
+func fitsBits(x uint64, n int) bool {
+	return bits.Len64(x) <= n
+}
@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitTLV(t *testing.T) {
+	var (
+		temp       byte
+		battery    uint16
+		hasTemp    bool
+		hasBattery bool
+		unknown    []BitTLVEntry
+	)
+	enc := New(Bitpacked(BitTLV(2, 3, 4, &unknown,
+		BitTagField(1, &hasTemp, Bits8(&temp, 7)),
+		BitTagField(2, &hasBattery, Bits16(&battery, 12)),
+	)))
+
+	temp, battery, hasTemp, hasBattery = 21, 3300, true, true
+	b := enc.Encode()
+	// 2 bits of count, then 3+4+7 and 3+4+12 bits of fields.
+	require.Len(t, b, 5)
+
+	temp, battery, hasTemp, hasBattery = 0, 0, false, false
+	require.NoError(t, enc.Decode(b))
+	require.True(t, hasTemp)
+	require.True(t, hasBattery)
+	require.Equal(t, byte(21), temp)
+	require.Equal(t, uint16(3300), battery)
+
+	hasTemp = false
+	b = enc.Encode()
+	require.Len(t, b, 3)
+	require.NoError(t, enc.Decode(b))
+	require.False(t, hasTemp)
+	require.True(t, hasBattery)
+	require.Equal(t, uint16(3300), battery)
+
+	// A newer producer adds a field that this one doesn't know, which is passed through.
+	var (
+		rssi    byte
+		hasRSSI bool
+	)
+	newer := New(Bitpacked(BitTLV(2, 3, 4, nil,
+		BitTagField(5, &hasRSSI, Bits8(&rssi, 6)),
+		BitTagField(1, &hasTemp, Bits8(&temp, 7)),
+		BitTagField(2, &hasBattery, Bits16(&battery, 12)),
+	)))
+	rssi, hasRSSI, temp, hasTemp, hasBattery = 40, true, 17, true, false
+	b = newer.Encode()
+	temp, hasTemp = 0, false
+	require.NoError(t, enc.Decode(b))
+	require.True(t, hasTemp)
+	require.False(t, hasBattery)
+	require.Equal(t, byte(17), temp)
+	require.Equal(t, []BitTLVEntry{{Tag: 5, Bits: 6, Value: []byte{40 << 2}}}, unknown)
+	require.Len(t, enc.Encode(), len(b))
+
+	rssi = 0
+	require.NoError(t, newer.Decode(enc.Encode()))
+	require.True(t, hasRSSI)
+	require.Equal(t, byte(40), rssi)
+
+	unknown = nil
+	require.ErrorIs(t, New(Bitpacked(BitTLV(2, 3, 4, nil))).Decode(b), ErrUnknownTag)
+
+	require.Panics(t, func() {
+		var x uint32
+		present := true
+		New(Bitpacked(BitTLV(2, 3, 4, nil, BitTagField(1, &present, Bits32(&x, 16))))).Encode()
+	})
+	require.Panics(t, func() { BitTLV(2, 3, 4, nil, BitTagField(8, &hasTemp, Bits8(&temp, 7))) })
+}
@@ -0,0 +1,211 @@
+// Package block builds and reads blocks of key-value records sorted by key, such as Tuple-encoded
+// keys from package encode, with keys prefix-compressed against the previous key.
+//
+// Sorted keys tend to share long prefixes with their neighbors, especially Tuple keys whose leading
+// items repeat, so each key is stored as the length of the prefix it shares with the previous key
+// plus the rest. Every restartInterval keys, a key is stored whole, and the offsets of these
+// restart points are listed at the end of the block so that lookups can binary search them rather
+// than decoding every key from the beginning. This is the same layout as LevelDB's data blocks:
+//
+//   entry      uvarint shared, uvarint unshared, uvarint len(value), key[shared:], value
+//   ...
+//   restarts   4-byte big endian offset of each restart point
+//   count      4-byte big endian number of restart points
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrCorrupt = errors.New("block: corrupt block")
+
+// Builds a block.
+type Builder struct {
+	restartInterval int
+	buf             []byte
+	restarts        []uint32
+	n               int
+	lastKey         []byte
+}
+
+// Returns a Builder that stores every restartInterval-th key whole. Higher values compress better,
+// and make lookups decode more keys.
+func NewBuilder(restartInterval int) *Builder {
+	if restartInterval < 1 {
+		panic("block: restartInterval must be at least 1")
+	}
+	return &Builder{restartInterval: restartInterval}
+}
+
+// Adds a record. Keys must be added in strictly increasing order, otherwise returns
+// encode.ErrUnsorted.
+func (b *Builder) Add(key []byte, value []byte) error {
+	if b.n > 0 && bytes.Compare(b.lastKey, key) >= 0 {
+		return encode.ErrUnsorted
+	}
+	shared := 0
+	if b.n%b.restartInterval == 0 {
+		b.restarts = append(b.restarts, uint32(len(b.buf)))
+	} else {
+		shared = sharedPrefixLen(b.lastKey, key)
+	}
+	b.buf = binary.AppendUvarint(b.buf, uint64(shared))
+	b.buf = binary.AppendUvarint(b.buf, uint64(len(key)-shared))
+	b.buf = binary.AppendUvarint(b.buf, uint64(len(value)))
+	b.buf = append(b.buf, key[shared:]...)
+	b.buf = append(b.buf, value...)
+	b.lastKey = append(b.lastKey[:0], key...)
+	b.n++
+	return nil
+}
+
+// The size of the block that Finish would return now.
+func (b *Builder) Size() int {
+	return len(b.buf) + 4*len(b.restarts) + 4
+}
+
+// Returns the finished block. The Builder must not be used afterwards except to Reset it.
+func (b *Builder) Finish() []byte {
+	for _, restart := range b.restarts {
+		b.buf = binary.BigEndian.AppendUint32(b.buf, restart)
+	}
+	b.buf = binary.BigEndian.AppendUint32(b.buf, uint32(len(b.restarts)))
+	return b.buf
+}
+
+// Empties the Builder to build another block.
+func (b *Builder) Reset() {
+	b.buf = nil
+	b.restarts = b.restarts[:0]
+	b.n = 0
+	b.lastKey = b.lastKey[:0]
+}
+
+func sharedPrefixLen(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// Reads a block built by Builder.
+type Reader struct {
+	data     []byte
+	restarts []byte
+}
+
+func NewReader(b []byte) (*Reader, error) {
+	if len(b) < 4 {
+		return nil, ErrCorrupt
+	}
+	n := binary.BigEndian.Uint32(b[len(b)-4:])
+	if uint64(n)*4 > uint64(len(b)-4) {
+		return nil, ErrCorrupt
+	}
+	restartsStart := len(b) - 4 - int(n)*4
+	return &Reader{data: b[:restartsStart], restarts: b[restartsStart : len(b)-4]}, nil
+}
+
+func (r *Reader) numRestarts() int {
+	return len(r.restarts) / 4
+}
+func (r *Reader) restart(i int) int {
+	return int(binary.BigEndian.Uint32(r.restarts[i*4:]))
+}
+
+// Returns an Iterator over every record, in order.
+func (r *Reader) Iter() *Iterator {
+	return &Iterator{data: r.data}
+}
+
+// Returns an Iterator positioned at the first record with a key at or after key.
+func (r *Reader) Seek(key []byte) *Iterator {
+	var err error
+	// The last restart point whose key is before key.
+	i := sort.Search(r.numRestarts(), func(i int) bool {
+		it := Iterator{data: r.data, i: r.restart(i)}
+		k, _, nextErr := it.Next()
+		if nextErr != nil {
+			if err == nil && nextErr != io.EOF {
+				err = nextErr
+			}
+			return true
+		}
+		return bytes.Compare(k, key) >= 0
+	}) - 1
+	it := &Iterator{data: r.data, err: err}
+	if i >= 0 {
+		it.i = r.restart(i)
+	}
+	it.skipBefore = key
+	return it
+}
+
+// Returns the value for key, or false if there isn't one.
+func (r *Reader) Get(key []byte) ([]byte, bool, error) {
+	k, v, err := r.Seek(key).Next()
+	if err == io.EOF || (err == nil && !bytes.Equal(k, key)) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// Iterates over the records of a block in key order.
+type Iterator struct {
+	data       []byte
+	i          int
+	key        []byte
+	skipBefore []byte
+	err        error
+}
+
+// Returns the next record's key and value. Returns io.EOF after the last one. The returned key is
+// only valid until the next call to Next, and the value aliases the block.
+func (it *Iterator) Next() ([]byte, []byte, error) {
+	for {
+		if it.err != nil {
+			return nil, nil, it.err
+		}
+		if it.i >= len(it.data) {
+			return nil, nil, io.EOF
+		}
+		var header [3]uint64
+		for j := range header {
+			x, n := binary.Uvarint(it.data[it.i:])
+			if n <= 0 {
+				it.err = ErrCorrupt
+				return nil, nil, it.err
+			}
+			header[j] = x
+			it.i += n
+		}
+		shared, unshared, valueLen := header[0], header[1], header[2]
+		if shared > uint64(len(it.key)) ||
+			unshared > uint64(len(it.data)-it.i) ||
+			valueLen > uint64(len(it.data)-it.i)-unshared {
+			it.err = ErrCorrupt
+			return nil, nil, it.err
+		}
+		it.key = append(it.key[:shared], it.data[it.i:it.i+int(unshared)]...)
+		it.i += int(unshared)
+		value := it.data[it.i : it.i+int(valueLen)]
+		it.i += int(valueLen)
+
+		if it.skipBefore != nil {
+			if bytes.Compare(it.key, it.skipBefore) < 0 {
+				continue
+			}
+			it.skipBefore = nil
+		}
+		return it.key, value, nil
+	}
+}
@@ -0,0 +1,82 @@
+package block
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bradenaw/encode"
+)
+
+func key(tenant string, id uint64) []byte {
+	return encode.NewTuple(encode.EscapedString(&tenant), encode.OrdUvarint64(&id)).Encode()
+}
+
+func TestBlock(t *testing.T) {
+	for _, restartInterval := range []int{1, 4, 16, 1000} {
+		b := NewBuilder(restartInterval)
+		type kv struct{ k, v string }
+		var expected []kv
+		uncompressed := 0
+		for _, tenant := range []string{"acme", "globex", "initech"} {
+			for id := uint64(0); id < 100; id += 3 {
+				k := key(tenant, id)
+				v := fmt.Sprintf("%s/%d", tenant, id)
+				require.NoError(t, b.Add(k, []byte(v)))
+				expected = append(expected, kv{string(k), v})
+				uncompressed += len(k) + len(v)
+			}
+		}
+		require.Equal(t, encode.ErrUnsorted, b.Add(key("acme", 0), nil))
+		size := b.Size()
+		block := b.Finish()
+		require.Len(t, block, size)
+		if restartInterval > 1 {
+			require.Less(t, len(block), uncompressed)
+		}
+
+		r, err := NewReader(block)
+		require.NoError(t, err)
+
+		var actual []kv
+		it := r.Iter()
+		for {
+			k, v, err := it.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			actual = append(actual, kv{string(k), string(v)})
+		}
+		require.Equal(t, expected, actual)
+
+		for _, tenant := range []string{"acme", "globex", "initech", "zzz"} {
+			for id := uint64(0); id < 101; id++ {
+				v, ok, err := r.Get(key(tenant, id))
+				require.NoError(t, err)
+				if tenant != "zzz" && id%3 == 0 && id < 100 {
+					require.True(t, ok)
+					require.Equal(t, fmt.Sprintf("%s/%d", tenant, id), string(v))
+				} else {
+					require.False(t, ok)
+				}
+			}
+		}
+
+		k, _, err := r.Seek(key("globex", 200)).Next()
+		require.NoError(t, err)
+		require.Equal(t, key("initech", 0), k)
+		k, _, err = r.Seek([]byte{}).Next()
+		require.NoError(t, err)
+		require.Equal(t, key("acme", 0), k)
+	}
+
+	_, err := NewReader([]byte{0, 0, 0, 5})
+	require.Equal(t, ErrCorrupt, err)
+	r, err := NewReader([]byte{0x05, 0x00, 0x00, 0, 0, 0, 0, 0, 0, 0, 1})
+	require.NoError(t, err)
+	_, _, err = r.Iter().Next()
+	require.Equal(t, ErrCorrupt, err)
+}
@@ -0,0 +1,77 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	ErrSizeChanged      = errors.New("encode: Size changed during Encode")
+	ErrWroteOutOfBounds = errors.New("encode: Encode wrote outside of its Size() bytes")
+	ErrNotRoundTrip     = errors.New("encode: decoding the item's encoding did not reproduce it")
+)
+
+const checkGuardSize = 16
+
+// Checks that each of enc's items behaves consistently with its current value, which catches most
+// mistakes in implementations of Item. Meant for tests and during development rather than
+// production, since it costs several times as much as Encode. For each item, checks that:
+//
+//   - Size returns the same thing before and after Encode.
+//   - Encode doesn't write past Size bytes into the rest of the buffer, which it could otherwise do
+//     by reslicing buf up to its capacity, overwriting the next item.
+//   - Decode succeeds when given exactly the Size bytes that Encode wrote, leaves Size the same, and
+//     re-encodes to the same bytes.
+//
+// Returns a *FieldError wrapping ErrSizeChanged, ErrWroteOutOfBounds, or ErrNotRoundTrip for the
+// first item that fails, or the error Decode returned. Decoding an item's own encoding should leave
+// its destination as it was, as long as the item is correct. Items that are randomized on purpose,
+// like Encrypted, always fail the round trip check.
+func (enc Encoding) Check() error {
+	offset := 0
+	for index, item := range enc.items {
+		err := checkItem(item)
+		if err != nil {
+			return &FieldError{
+				Index:     index,
+				Offset:    offset,
+				Err:       err,
+				sensitive: isSensitive(item),
+			}
+		}
+		offset += item.Size()
+	}
+	return nil
+}
+
+func checkItem(item Item) error {
+	size := item.Size()
+	buf := make([]byte, size+checkGuardSize)
+	for i := size; i < len(buf); i++ {
+		buf[i] = 0xA5
+	}
+	item.Encode(buf[:size])
+	if item.Size() != size {
+		return ErrSizeChanged
+	}
+	for i := size; i < len(buf); i++ {
+		if buf[i] != 0xA5 {
+			return ErrWroteOutOfBounds
+		}
+	}
+
+	encoded := append([]byte(nil), buf[:size]...)
+	err := item.Decode(encoded[:size:size])
+	if err != nil {
+		return err
+	}
+	if item.Size() != size {
+		return ErrNotRoundTrip
+	}
+	reencoded := make([]byte, size)
+	item.Encode(reencoded)
+	if !bytes.Equal(reencoded, encoded) {
+		return ErrNotRoundTrip
+	}
+	return nil
+}
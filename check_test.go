@@ -0,0 +1,62 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Writes one byte more than its Size.
+type overrunItem struct{}
+
+func (overrunItem) Encode(buf []byte)       { buf[:2][1] = 0xFF }
+func (overrunItem) Decode(buf []byte) error { return nil }
+func (overrunItem) Size() int               { return 1 }
+
+// Claims one more byte than it needs to decode.
+type greedyItem struct{ v *uint16 }
+
+func (e greedyItem) Encode(buf []byte) { FixedUint16(e.v).Encode(buf) }
+func (e greedyItem) Decode(buf []byte) error {
+	if len(buf) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	return FixedUint16(e.v).Decode(buf)
+}
+func (e greedyItem) Size() int { return 2 }
+
+// Encodes differently on every call.
+type counterItem struct{ n *int }
+
+func (e counterItem) Encode(buf []byte) {
+	*e.n++
+	buf[0] = byte(*e.n)
+}
+func (e counterItem) Decode(buf []byte) error { return nil }
+func (e counterItem) Size() int               { return 1 }
+
+func TestCheck(t *testing.T) {
+	var a uint16
+	var b string
+	var c bool
+	var d uint64
+	require.NoError(t, New(FixedUint16(&a), LengthDelimString(&b), Bool(&c), Uvarint64(&d)).Check())
+	b, c, d = "hello", true, 1<<40
+	require.NoError(t, New(FixedUint16(&a), LengthDelimString(&b), Bool(&c), Uvarint64(&d)).Check())
+	require.Equal(t, "hello", b)
+
+	check := func(enc Encoding, index int, offset int, target error) {
+		err := enc.Check()
+		var fieldErr *FieldError
+		require.True(t, errors.As(err, &fieldErr))
+		require.Equal(t, index, fieldErr.Index)
+		require.Equal(t, offset, fieldErr.Offset)
+		require.True(t, errors.Is(err, target), err.Error())
+	}
+	check(New(FixedUint16(&a), overrunItem{}), 1, 2, ErrWroteOutOfBounds)
+	check(New(Bool(&c), greedyItem{&a}), 1, 1, io.ErrUnexpectedEOF)
+	n := 0
+	check(New(counterItem{&n}), 0, 0, ErrNotRoundTrip)
+}
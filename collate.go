@@ -0,0 +1,92 @@
+package encode
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+)
+
+// Encode v so that it sorts in the order given by c, such as a locale's alphabetical order, rather
+// than in byte order. The encoding is c's collation key for v followed by v itself, each escaped as
+// in EscapedString, so that decoding gives back v exactly and strings that collate equally are still
+// distinct keys.
+//
+// collate.Collator is not safe for concurrent use, and neither is the result.
+func Collated(v *string, c *collate.Collator) TupleItem {
+	buf := &collate.Buffer{}
+	return sortKeyed{
+		v: v,
+		key: func(s string) []byte {
+			buf.Reset()
+			return c.KeyFromString(buf, s)
+		},
+		original: true,
+	}
+}
+
+// Encode v case-folded, escaped as in EscapedString, so that strings that differ only in case sort
+// together and compare equal, as is expected of indexes on usernames and email addresses.
+//
+// If keepOriginal is true, the folded form is followed by v itself so that decoding gives back v as
+// it was. Otherwise decoding gives back the folded form, and strings that differ only in case have
+// the same key.
+//
+// Like Collated, the result is not safe for concurrent use.
+func Folded(v *string, keepOriginal bool) TupleItem {
+	caser := cases.Fold()
+	return sortKeyed{
+		v: v,
+		key: func(s string) []byte {
+			return []byte(caser.String(s))
+		},
+		original: keepOriginal,
+	}
+}
+
+// A string encoded as a key derived from it, followed by the original string if original is true.
+// If original is false, decoding sets v to the key.
+type sortKeyed struct {
+	v        *string
+	key      func(s string) []byte
+	original bool
+}
+
+func (e sortKeyed) OrderPreserving() {}
+func (e sortKeyed) Encode(buf []byte) {
+	e.EncodeTuple(buf, false)
+}
+func (e sortKeyed) EncodeTuple(buf []byte, last bool) {
+	key := e.key(*e.v)
+	keyItem := EscapedBytes(&key)
+	if !e.original {
+		keyItem.EncodeTuple(buf, last)
+		return
+	}
+	n := keyItem.SizeTuple(false)
+	keyItem.EncodeTuple(buf[:n:n], false)
+	escapedString{e.v}.EncodeTuple(buf[n:], last)
+}
+func (e sortKeyed) Size() int {
+	return e.SizeTuple(false)
+}
+func (e sortKeyed) SizeTuple(last bool) int {
+	key := e.key(*e.v)
+	if !e.original {
+		return EscapedBytes(&key).SizeTuple(last)
+	}
+	return EscapedBytes(&key).SizeTuple(false) + escapedString{e.v}.SizeTuple(last)
+}
+func (e sortKeyed) Decode(buf []byte) error {
+	return e.DecodeTuple(buf, false)
+}
+func (e sortKeyed) DecodeTuple(buf []byte, last bool) error {
+	if !e.original {
+		return escapedString{e.v}.DecodeTuple(buf, last)
+	}
+	var key []byte
+	keyItem := EscapedBytes(&key)
+	err := keyItem.DecodeTuple(buf, false)
+	if err != nil {
+		return err
+	}
+	return escapedString{e.v}.DecodeTuple(buf[keyItem.SizeTuple(false):], last)
+}
@@ -0,0 +1,62 @@
+package encode
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+func TestCollated(t *testing.T) {
+	c := collate.New(language.German)
+	words := []string{"Zebra", "äpfel", "apfel", "Bär", "bar", "Äpfel"}
+
+	var s string
+	tup := NewTuple(Collated(&s, c), Collated(&s, c))
+	keys := make([][]byte, len(words))
+	for i, w := range words {
+		s = w
+		keys[i] = tup.Encode()
+
+		s = ""
+		require.NoError(t, tup.Decode(keys[i]))
+		require.Equal(t, w, s)
+	}
+
+	sorted := append([]string(nil), words...)
+	c.SortStrings(sorted)
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	for i, k := range keys {
+		require.NoError(t, tup.Decode(k))
+		require.Equal(t, sorted[i], s)
+	}
+}
+
+func TestFolded(t *testing.T) {
+	var s string
+	key := func(v string, keep bool) []byte {
+		s = v
+		return NewTuple(Folded(&s, keep)).Encode()
+	}
+	require.Equal(t, key("alice@example.com", false), key("Alice@Example.COM", false))
+	require.Equal(t, []byte("strasse"), key("STRAßE", false))
+	require.True(t, bytes.Compare(key("alice", false), key("Bob", false)) < 0)
+	require.True(t, bytes.Compare(key("Alice", true), key("bob", true)) < 0)
+	require.NotEqual(t, key("Alice", true), key("alice", true))
+
+	for _, keep := range []bool{false, true} {
+		tup := NewTuple(Folded(&s, keep), Folded(&s, keep))
+		s = "Alice"
+		b := tup.Encode()
+		s = ""
+		require.NoError(t, tup.Decode(b))
+		if keep {
+			require.Equal(t, "Alice", s)
+		} else {
+			require.Equal(t, "alice", s)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package encode
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+)
+
+type opcode uint8
+
+const (
+	opByte opcode = iota
+	opBool
+	opUint16
+	opUint32
+	opUint64
+	// Any other item, called through Item.
+	opItem
+)
+
+// One step of a Program. Each op is at offset from the end of the last opItem, or from the start of
+// the buffer if there isn't one, so fixed-size ops don't need to track where they are as they go.
+type op struct {
+	code   opcode
+	offset int
+	// The encoded size, for fixed-size ops.
+	size int
+	// The index of the item in the Encoding, for errors.
+	index int
+
+	u8   *byte
+	flag *bool
+	u16  *uint16
+	u32  *uint32
+	u64  *uint64
+	item Item
+}
+
+// A compiled Encoding, which encodes and decodes the same way but runs a flat list of ops in a
+// single loop instead of calling each item through Item. This is considerably faster for wide
+// records made mostly of Byte, Bool and the FixedUint items. Other items are called as usual.
+//
+// A Program refers to the same values as the Encoding it was compiled from.
+type Program struct {
+	enc Encoding
+	ops []op
+	// The total size of the fixed-size ops.
+	fixedSize int
+	// Whether any ops are opItem.
+	variable bool
+}
+
+// Compiles enc into a Program. enc's hooks, name, stats, and detailed errors carry over.
+func (enc Encoding) Compile() *Program {
+	p := &Program{enc: enc, ops: make([]op, 0, len(enc.items))}
+	offset := 0
+	for index, item := range enc.items {
+		o := op{offset: offset, index: index}
+		switch e := item.(type) {
+		case encByte:
+			o.code, o.u8 = opByte, e.v
+		case encBool:
+			o.code, o.flag = opBool, e.v
+		case fixedUint16:
+			o.code, o.u16 = opUint16, e.v
+		case fixedUint32:
+			o.code, o.u32 = opUint32, e.v
+		case fixedUint64:
+			o.code, o.u64 = opUint64, e.v
+		default:
+			o.code, o.item = opItem, item
+			p.ops = append(p.ops, o)
+			p.variable = true
+			offset = 0
+			continue
+		}
+		o.size, _ = fixedSize(item)
+		p.ops = append(p.ops, o)
+		p.fixedSize += o.size
+		offset += o.size
+	}
+	return p
+}
+
+func (p *Program) Encode() []byte {
+	return p.AppendTo(nil)
+}
+
+// Appends the encoding to buf and returns the extended buffer. If buf has enough spare capacity,
+// this doesn't allocate.
+func (p *Program) AppendTo(buf []byte) []byte {
+	if p.enc.stats != nil || p.enc.name != "" {
+		return append(buf, p.enc.Encode()...)
+	}
+	n := p.size()
+	start := len(buf)
+	if cap(buf)-start < n {
+		grown := make([]byte, start, start+n)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:start+n]
+	// Items may assume they're encoding into zeroed bytes.
+	clear(buf[start:])
+	p.encodeTo(buf[start:])
+	return buf
+}
+
+func (p *Program) size() int {
+	if !p.variable {
+		return p.fixedSize
+	}
+	size := p.fixedSize
+	base := 0
+	for i := range p.ops {
+		o := &p.ops[i]
+		if o.code != opItem {
+			continue
+		}
+		setOffset(o.item, base+o.offset)
+		itemSize := o.item.Size()
+		size += itemSize
+		base += o.offset + itemSize
+	}
+	return size
+}
+
+// Encodes into buf, which must be exactly size() bytes, and must have been preceded by a call to
+// size().
+func (p *Program) encodeTo(buf []byte) {
+	base := 0
+	for i := range p.ops {
+		o := &p.ops[i]
+		b := buf[base+o.offset:]
+		switch o.code {
+		case opByte:
+			b[0] = *o.u8
+		case opBool:
+			if *o.flag {
+				b[0] = 1
+			} else {
+				b[0] = 0
+			}
+		case opUint16:
+			binary.BigEndian.PutUint16(b, *o.u16)
+		case opUint32:
+			binary.BigEndian.PutUint32(b, *o.u32)
+		case opUint64:
+			binary.BigEndian.PutUint64(b, *o.u64)
+		case opItem:
+			size := o.item.Size()
+			o.item.Encode(b[:size:size])
+			base += o.offset + size
+		}
+	}
+}
+
+func (p *Program) Decode(buf []byte) error {
+	if p.enc.stats == nil && p.enc.name == "" {
+		_, err := p.decode(buf)
+		return err
+	}
+	return p.enc.instrument(context.Background(), opDecode, false, func(ctx context.Context) (int, error) {
+		return p.decode(buf)
+	})
+}
+
+// Returns the number of bytes of buf that were decoded.
+func (p *Program) decode(buf []byte) (int, error) {
+	if p.enc.preDecode != nil {
+		err := p.enc.preDecode(buf)
+		if err != nil {
+			return 0, err
+		}
+	}
+	base := 0
+	end := 0
+	for i := range p.ops {
+		o := &p.ops[i]
+		at := base + o.offset
+		if o.code == opItem {
+			setOffset(o.item, at)
+			err := o.item.Decode(buf[at:])
+			if err != nil {
+				return at, p.enc.itemError(o.index, at, buf, err)
+			}
+			base = at + decodedSize(o.item)
+			end = base
+			continue
+		}
+		if at+o.size > len(buf) {
+			return at, p.enc.itemError(o.index, at, buf, io.ErrUnexpectedEOF)
+		}
+		b := buf[at:]
+		switch o.code {
+		case opByte:
+			*o.u8 = b[0]
+		case opBool:
+			switch b[0] {
+			case 0:
+				*o.flag = false
+			case 1:
+				*o.flag = true
+			default:
+				return at, p.enc.itemError(o.index, at, buf, ErrInvalidBool)
+			}
+		case opUint16:
+			*o.u16 = binary.BigEndian.Uint16(b)
+		case opUint32:
+			*o.u32 = binary.BigEndian.Uint32(b)
+		case opUint64:
+			*o.u64 = binary.BigEndian.Uint64(b)
+		}
+		end = at + o.size
+	}
+	if p.enc.postDecode != nil {
+		return end, p.enc.postDecode()
+	}
+	return end, nil
+}
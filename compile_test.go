@@ -0,0 +1,104 @@
+package encode
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	var (
+		a    byte
+		flag bool
+		b    uint16
+		c    uint32
+		d    uint64
+		s    string
+		e    uint64
+	)
+	enc := New(
+		Byte(&a),
+		Bool(&flag),
+		FixedUint16(&b),
+		LengthDelimString(&s),
+		FixedUint32(&c),
+		AlignTo(8),
+		FixedUint64(&d),
+		Uvarint64(&e),
+		Byte(&a),
+	).WithDetailedErrors()
+	p := enc.Compile()
+
+	trand.RandomN(t, 100, func(t *testing.T, r *rand.Rand) {
+		a = byte(r.Intn(256))
+		flag = r.Intn(2) == 0
+		b = uint16(r.Uint32())
+		c = r.Uint32()
+		d = r.Uint64()
+		s = string(make([]byte, r.Intn(20)))
+		e = r.Uint64() >> uint(r.Intn(64))
+
+		expected := enc.Encode()
+		require.Equal(t, expected, p.Encode())
+		require.Equal(t, append([]byte{0xff}, expected...), p.AppendTo([]byte{0xff}))
+
+		a, flag, b, c, d, s, e = 0, false, 0, 0, 0, "", 0
+		require.NoError(t, p.Decode(expected))
+		require.Equal(t, expected, enc.Encode())
+
+		for i := 0; i < len(expected); i++ {
+			require.Equal(t, enc.Decode(expected[:i]), p.Decode(expected[:i]))
+		}
+	})
+
+	buf := enc.Encode()
+	buf[1] = 2
+	var invalid *InvalidError
+	require.True(t, errors.As(p.Decode(buf), &invalid))
+	require.Equal(t, 1, invalid.Index)
+	require.ErrorIs(t, invalid, ErrInvalidBool)
+}
+
+func TestCompileHooks(t *testing.T) {
+	var a uint16
+	var b uint32
+	decoded := false
+	p := New(
+		FixedUint16(&a),
+		Default(FixedUint32(&b), func() { b = 7 }),
+	).WithPostDecode(func() error {
+		decoded = true
+		return nil
+	}).Compile()
+
+	require.NoError(t, p.Decode([]byte{0x00, 0x01}))
+	require.Equal(t, uint16(1), a)
+	require.Equal(t, uint32(7), b)
+	require.True(t, decoded)
+}
+
+func BenchmarkCompiledSmallFieldsEncode(b *testing.B) {
+	p := newSmallFieldsEncoding().Compile()
+	buf := make([]byte, 0, len(p.Encode()))
+	b.SetBytes(int64(cap(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = p.AppendTo(buf[:0])
+	}
+}
+
+func BenchmarkCompiledSmallFieldsDecode(b *testing.B) {
+	p := newSmallFieldsEncoding().Compile()
+	buf := p.Encode()
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := p.Decode(buf)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
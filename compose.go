@@ -0,0 +1,45 @@
+package encode
+
+// Returns an Encoding of the items of each of encs in order, for example a common header followed by
+// a message's own fields. Only the items are combined: hooks, names, stats, and detailed errors set on
+// encs are not carried over. Use Embed to include an Encoding along with its hooks.
+func Concat(encs ...Encoding) Encoding {
+	n := 0
+	for _, enc := range encs {
+		n += len(enc.items)
+	}
+	items := make([]Item, 0, n)
+	for _, enc := range encs {
+		items = append(items, enc.items...)
+	}
+	return New(items...)
+}
+
+// Encode enc as a single item of another Encoding, exactly as it would be encoded on its own, so that
+// a common header can be defined once and reused across message types:
+//
+//   func header(h *Header) encode.Encoding {
+//   	return encode.New(...).WithPostDecode(h.validate)
+//   }
+//
+//   encode.New(encode.Embed(header(&msg.Header)), encode.Uvarint64(&msg.ID))
+//
+// enc's pre- and post-decode hooks and detailed errors apply when decoding. Its name and stats only
+// apply when it's used on its own.
+func Embed(enc Encoding) Item {
+	return embedded{enc}
+}
+
+type embedded struct{ enc Encoding }
+
+func (e embedded) Encode(buf []byte) {
+	e.enc.size()
+	e.enc.encodeTo(buf)
+}
+func (e embedded) Size() int {
+	return e.enc.size()
+}
+func (e embedded) Decode(buf []byte) error {
+	_, err := e.enc.decode(buf)
+	return err
+}
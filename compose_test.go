@@ -0,0 +1,57 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcat(t *testing.T) {
+	var version byte = 1
+	var kind uint16 = 7
+	var id uint64 = 300
+	var name = "foo"
+	header := New(Byte(&version), FixedUint16(&kind))
+	body := New(Uvarint64(&id), LengthDelimString(&name))
+
+	enc := Concat(header, body)
+	require.Len(t, enc.Items(), 4)
+	b := enc.Encode()
+	require.Equal(t, append(header.Encode(), body.Encode()...), b)
+
+	version, kind, id, name = 0, 0, 0, ""
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, byte(1), version)
+	require.Equal(t, uint16(7), kind)
+	require.Equal(t, uint64(300), id)
+	require.Equal(t, "foo", name)
+}
+
+func TestEmbed(t *testing.T) {
+	var version byte = 1
+	var kind uint16 = 7
+	var id uint64 = 300
+	errBadVersion := errors.New("bad version")
+	header := New(Byte(&version), FixedUint16(&kind)).WithPostDecode(func() error {
+		if version != 1 {
+			return errBadVersion
+		}
+		return nil
+	})
+
+	enc := New(Embed(header), Uvarint64(&id))
+	b := enc.Encode()
+	require.Equal(t, append(header.Encode(), New(Uvarint64(&id)).Encode()...), b)
+
+	version, kind, id = 0, 0, 0
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, byte(1), version)
+	require.Equal(t, uint16(7), kind)
+	require.Equal(t, uint64(300), id)
+
+	b[0] = 2
+	require.Equal(t, errBadVersion, enc.Decode(b))
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(b[:2]))
+}
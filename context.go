@@ -0,0 +1,96 @@
+package encode
+
+import (
+	"context"
+)
+
+// Optionally implemented by Items whose encoding or decoding is slow enough to be worth canceling,
+// such as ones that compress or copy very large values. EncodeCtx and DecodeCtx call these methods
+// instead of Encode and Decode, and the item should check ctx periodically and return ctx.Err() if
+// it's done.
+type ContextItem interface {
+	Item
+	EncodeCtx(ctx context.Context, buf []byte) error
+	DecodeCtx(ctx context.Context, buf []byte) error
+}
+
+// Like Encode, but stops and returns ctx.Err() if ctx is done before all items have been encoded.
+// ctx is checked between items, and passed to items that implement ContextItem.
+func (enc Encoding) EncodeCtx(ctx context.Context) ([]byte, error) {
+	var buf []byte
+	err := enc.instrument(ctx, opEncode, true, func(ctx context.Context) (int, error) {
+		var err error
+		buf, err = enc.encodeCtx(ctx)
+		return len(buf), err
+	})
+	return buf, err
+}
+
+func (enc Encoding) encodeCtx(ctx context.Context) ([]byte, error) {
+	totalSize := 0
+	for _, item := range enc.items {
+		setOffset(item, totalSize)
+		totalSize += item.Size()
+	}
+	buf := make([]byte, totalSize)
+	i := 0
+	for _, item := range enc.items {
+		err := ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+		size := item.Size()
+		if ctxItem, ok := item.(ContextItem); ok {
+			err := ctxItem.EncodeCtx(ctx, buf[i:i+size])
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			item.Encode(buf[i : i+size : i+size])
+		}
+		i += size
+	}
+	return buf, nil
+}
+
+// Like Decode, but stops and returns ctx.Err() if ctx is done before all items have been decoded.
+// ctx is checked between items, and passed to items that implement ContextItem. If decoding is
+// canceled, the items before the point of cancellation have already been decoded.
+func (enc Encoding) DecodeCtx(ctx context.Context, buf []byte) error {
+	return enc.instrument(ctx, opDecode, true, func(ctx context.Context) (int, error) {
+		return enc.decodeCtx(ctx, buf)
+	})
+}
+
+func (enc Encoding) decodeCtx(ctx context.Context, buf []byte) (int, error) {
+	if enc.preDecode != nil {
+		err := enc.preDecode(buf)
+		if err != nil {
+			return 0, err
+		}
+	}
+	i := 0
+	for index, item := range enc.items {
+		err := ctx.Err()
+		if err != nil {
+			return i, err
+		}
+		setOffset(item, i)
+		if ctxItem, ok := item.(ContextItem); ok {
+			err = ctxItem.DecodeCtx(ctx, buf[i:])
+		} else {
+			err = item.Decode(buf[i:])
+		}
+		if err != nil {
+			if err == ctx.Err() {
+				return i, err
+			}
+			return i, enc.itemError(index, i, buf, err)
+		}
+		i += decodedSize(item)
+	}
+	if enc.postDecode != nil {
+		return i, enc.postDecode()
+	}
+	return i, nil
+}
@@ -0,0 +1,78 @@
+package encode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Stands in for a slow item, copying its value a chunk at a time and checking ctx in between.
+type chunkedBytes struct {
+	v      *[]byte
+	chunks *int
+}
+
+func (e chunkedBytes) Encode(buf []byte) {
+	copy(buf, *e.v)
+}
+func (e chunkedBytes) Decode(buf []byte) error {
+	*e.v = append([]byte(nil), buf...)
+	return nil
+}
+func (e chunkedBytes) Size() int {
+	return len(*e.v)
+}
+func (e chunkedBytes) EncodeCtx(ctx context.Context, buf []byte) error {
+	for i := 0; i < len(*e.v); i += 4 {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+		*e.chunks++
+		copy(buf[i:], (*e.v)[i:])
+	}
+	return nil
+}
+func (e chunkedBytes) DecodeCtx(ctx context.Context, buf []byte) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	return e.Decode(buf)
+}
+
+func TestEncodeCtx(t *testing.T) {
+	var a uint16
+	var b []byte
+	chunks := 0
+	enc := New(FixedUint16(&a), chunkedBytes{&b, &chunks})
+
+	a, b = 5, []byte("0123456789")
+	buf, err := enc.EncodeCtx(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, enc.Encode(), buf)
+	require.Equal(t, 3, chunks)
+
+	a, b = 0, nil
+	require.NoError(t, enc.DecodeCtx(context.Background(), buf))
+	require.Equal(t, uint16(5), a)
+	require.Equal(t, []byte("0123456789"), b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = enc.EncodeCtx(ctx)
+	require.Equal(t, context.Canceled, err)
+	a = 0
+	require.Equal(t, context.Canceled, enc.DecodeCtx(ctx, buf))
+	require.Equal(t, uint16(0), a)
+
+	// Canceled partway through.
+	ctx, cancel = context.WithCancel(context.Background())
+	err = New(PostDecode(FixedUint16(&a), func() error {
+		cancel()
+		return nil
+	}), FixedUint16(&a)).DecodeCtx(ctx, []byte{0, 1, 0, 2})
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, uint16(1), a)
+}
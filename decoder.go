@@ -0,0 +1,127 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrNeedMoreData = errors.New("encode: need more data")
+
+// Decodes a stream of records framed as by WriteFrame that arrives in arbitrary chunks, such as from
+// reads on a network connection, without the caller having to find record boundaries. Typical usage
+// looks like:
+//
+//   d := encode.NewDecoder(enc)
+//   for {
+//   	n, err := conn.Read(buf)
+//   	d.Feed(buf[:n])
+//   	for {
+//   		err := d.Next()
+//   		if err == encode.ErrNeedMoreData {
+//   			break
+//   		} else if err != nil {
+//   			return err
+//   		}
+//   		// enc's destinations now hold the next record.
+//   	}
+//   	...
+//   }
+type Decoder struct {
+	enc Encoding
+	buf []byte
+	// The start of unconsumed bytes in buf.
+	i int
+	// The longest record Next will wait for.
+	maxRecordLen int
+	// Whatever a record had after its items, which is an error.
+	trailing []byte
+	// Once the framing is broken, the error that broke it, which every later Next returns.
+	err error
+}
+
+func NewDecoder(enc Encoding) *Decoder {
+	d := &Decoder{enc: enc, maxRecordLen: maxFrameSize}
+	// Rest catches anything left over, so that a record with trailing bytes doesn't decode silently.
+	d.enc.items = append(enc.items[:len(enc.items):len(enc.items)], Rest(&d.trailing))
+	return d
+}
+
+// Sets the longest record that Next will accept, which is 1GiB by default. Since Next waits until a
+// whole record has been fed, this bounds how much a corrupted or hostile stream can make the
+// Decoder buffer.
+func (d *Decoder) SetMaxRecordLen(n int) {
+	if n < 0 {
+		panic(fmt.Sprintf("invalid n=%d, must be non-negative", n))
+	}
+	d.maxRecordLen = n
+}
+
+// Adds b to the end of the stream. b is copied, so the caller may reuse it afterwards.
+func (d *Decoder) Feed(b []byte) {
+	if d.err != nil {
+		// Nothing more can be decoded, so don't keep it.
+		return
+	}
+	if d.i > 0 && d.i >= len(d.buf)/2 {
+		// Reclaim the space taken by records that have already been decoded.
+		n := copy(d.buf, d.buf[d.i:])
+		d.buf = d.buf[:n]
+		d.i = 0
+	}
+	d.buf = append(d.buf, b...)
+}
+
+// Decodes the next complete record into the Encoding's destinations. Returns ErrNeedMoreData if the
+// next record hasn't been completely fed yet, in which case nothing is consumed and Next should be
+// called again after the next Feed.
+//
+// If the record itself fails to decode, the error is returned and the record is skipped. A record
+// whose items don't use all of it returns ErrLengthMismatch.
+//
+// Returns ErrOverflowVarint if the next record's length is corrupt, and ErrFrameTooLarge if it's
+// longer than the limit set by SetMaxRecordLen. Since there's no way to find where the following
+// record starts, every later call returns the same error and Feed discards its input.
+func (d *Decoder) Next() error {
+	if d.err != nil {
+		return d.err
+	}
+	l, n := binary.Uvarint(d.buf[d.i:])
+	if n == 0 {
+		return ErrNeedMoreData
+	}
+	if n < 0 {
+		d.fail(ErrOverflowVarint)
+		return d.err
+	}
+	if l > uint64(d.maxRecordLen) {
+		d.fail(ErrFrameTooLarge)
+		return d.err
+	}
+	if uint64(len(d.buf)-d.i-n) < l {
+		return ErrNeedMoreData
+	}
+	start := d.i + n
+	end := start + int(l)
+	d.i = end
+	err := d.enc.Decode(d.buf[start:end])
+	if err != nil {
+		return err
+	}
+	if len(d.trailing) > 0 {
+		d.trailing = nil
+		return ErrLengthMismatch
+	}
+	return nil
+}
+
+func (d *Decoder) fail(err error) {
+	d.err = err
+	d.buf = nil
+	d.i = 0
+}
+
+// The number of bytes that have been fed but not yet consumed by Next.
+func (d *Decoder) Buffered() int {
+	return len(d.buf) - d.i
+}
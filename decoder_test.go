@@ -0,0 +1,82 @@
+package encode
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	trand.RandomN(t, 100, func(t *testing.T, r *rand.Rand) {
+		var a uint64
+		var b string
+		enc := New(Uvarint64(&a), LengthDelimString(&b))
+
+		type record struct {
+			a uint64
+			b string
+		}
+		var expected []record
+		var stream bytes.Buffer
+		for i := r.Intn(20); i > 0; i-- {
+			a = r.Uint64() >> uint(r.Intn(64))
+			b = string(make([]byte, r.Intn(300)))
+			expected = append(expected, record{a, b})
+			require.NoError(t, WriteFrame(&stream, enc.Encode()))
+		}
+
+		d := NewDecoder(enc)
+		var actual []record
+		remaining := stream.Bytes()
+		for len(remaining) > 0 {
+			n := r.Intn(len(remaining)) + 1
+			d.Feed(remaining[:n])
+			remaining = remaining[n:]
+			for {
+				err := d.Next()
+				if err == ErrNeedMoreData {
+					break
+				}
+				require.NoError(t, err)
+				actual = append(actual, record{a, b})
+			}
+		}
+		require.Equal(t, expected, actual)
+		require.Equal(t, 0, d.Buffered())
+		require.Equal(t, ErrNeedMoreData, d.Next())
+	})
+
+	var x bool
+	d := NewDecoder(New(Bool(&x)))
+	d.Feed([]byte{0x01, 0x02, 0x01, 0x01})
+	require.Equal(t, ErrInvalidBool, d.Next())
+	require.NoError(t, d.Next())
+	require.True(t, x)
+
+	d = NewDecoder(New(Bool(&x)))
+	d.SetMaxRecordLen(4)
+	d.Feed([]byte{0x05, 0x01})
+	require.Equal(t, ErrFrameTooLarge, d.Next())
+	d = NewDecoder(New(Bool(&x)))
+	d.Feed([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x40})
+	require.Equal(t, ErrFrameTooLarge, d.Next())
+
+	// A record with bytes left over is rejected, and the next one still decodes.
+	d = NewDecoder(New(Bool(&x)))
+	d.Feed([]byte{0x02, 0x00, 0x07, 0x01, 0x00})
+	require.Equal(t, ErrLengthMismatch, d.Next())
+	x = true
+	require.NoError(t, d.Next())
+	require.False(t, x)
+
+	// Broken framing can't be recovered from, so later input isn't kept.
+	d = NewDecoder(New(Bool(&x)))
+	d.Feed(bytes.Repeat([]byte{0xFF}, 11))
+	require.Equal(t, ErrOverflowVarint, d.Next())
+	d.Feed([]byte{0x01, 0x01})
+	require.Equal(t, ErrOverflowVarint, d.Next())
+	require.Equal(t, 0, d.Buffered())
+}
@@ -0,0 +1,80 @@
+package encode
+
+// Encodes item as-is, but when item is missing from the buffer being decoded, calls set instead of
+// failing. set should put item's destination in its default state.
+//
+// An item is missing when there are no bytes left for it, which happens when a newer version of a
+// record appends fields to the end and an older version is decoded. It's also missing when it's an
+// absent field of a SparseStruct.
+//
+// Since whether the last Decode filled in item is remembered, Default must not be used
+// concurrently.
+func Default(item Item, set func()) Item {
+	return defaultItem{item: item, set: set, filled: new(bool)}
+}
+
+type defaulter interface {
+	setDefault()
+}
+
+type defaultItem struct {
+	item Item
+	set  func()
+	// Whether the last Decode called set, in which case it consumed nothing.
+	filled *bool
+}
+
+func (e defaultItem) setDefault() {
+	e.set()
+}
+func (e defaultItem) Encode(buf []byte) {
+	e.item.Encode(buf)
+}
+func (e defaultItem) Size() int {
+	return e.item.Size()
+}
+func (e defaultItem) unwrap() Item {
+	return e.item
+}
+func (e defaultItem) decodedSize() int {
+	if *e.filled {
+		return 0
+	}
+	return decodedSize(e.item)
+}
+func (e defaultItem) Decode(buf []byte) error {
+	*e.filled = len(buf) == 0
+	if *e.filled {
+		e.set()
+		return nil
+	}
+	return e.item.Decode(buf)
+}
+
+// Encode v as-is, with no length. Decoding takes every remaining byte of the buffer, so this must be
+// the last item.
+//
+// This is the counterpart to Default for passing through fields added by newer versions of a record.
+// Placing Rest after the last field an older program knows about keeps whatever newer producers
+// appended, and writes it back out when the older program re-encodes the record, instead of
+// silently dropping it. TLV does the same for unknown tags.
+func Rest(v *[]byte) Item {
+	return rest{v}
+}
+
+type rest struct{ v *[]byte }
+
+func (e rest) Encode(buf []byte) {
+	copy(buf, *e.v)
+}
+func (e rest) Size() int {
+	return len(*e.v)
+}
+func (e rest) Decode(buf []byte) error {
+	if len(buf) == 0 {
+		*e.v = nil
+		return nil
+	}
+	*e.v = append([]byte(nil), buf...)
+	return nil
+}
@@ -0,0 +1,86 @@
+package encode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var a uint16
+	var b uint32
+	var c bool
+	enc := New(
+		FixedUint16(&a),
+		Default(FixedUint32(&b), func() { b = 7 }),
+		Default(Bool(&c), func() { c = true }),
+	)
+
+	b = 1
+	c = false
+	require.NoError(t, enc.Decode([]byte{0x00, 0x01}))
+	require.Equal(t, uint16(1), a)
+	require.Equal(t, uint32(7), b)
+	require.True(t, c)
+
+	require.NoError(t, enc.Decode([]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x02}))
+	require.Equal(t, uint32(2), b)
+	require.True(t, c)
+
+	// Every way of decoding stops at the end of the buffer once items start being filled in.
+	for _, decode := range []func([]byte) error{
+		enc.Compile().Decode,
+		enc.DecodeTolerant,
+		func(buf []byte) error { return enc.DecodeCtx(context.Background(), buf) },
+	} {
+		b, c = 1, false
+		require.NoError(t, decode([]byte{0x00, 0x01}))
+		require.Equal(t, uint32(7), b)
+		require.True(t, c)
+	}
+
+	sparse := New(SparseStruct(
+		OptionalField(&a, FixedUint16(&a)),
+		OptionalField(&b, Default(FixedUint32(&b), func() { b = 9 })),
+	))
+	a = 3
+	b = 0
+	buf := sparse.Encode()
+	b = 1
+	require.NoError(t, sparse.Decode(buf))
+	require.Equal(t, uint16(3), a)
+	require.Equal(t, uint32(0), b)
+
+	// Only absent when written by a version of the struct without b.
+	require.NoError(t, sparse.Decode([]byte{0x80, 0x00, 0x03}))
+	require.Equal(t, uint16(3), a)
+	require.Equal(t, uint32(9), b)
+}
+
+func TestRest(t *testing.T) {
+	// A newer version of the record with a field appended.
+	var a uint16
+	var b uint32
+	var c string
+	v2 := New(FixedUint16(&a), FixedUint32(&b), LengthDelimString(&c))
+	a, b, c = 1, 2, "new"
+	buf := v2.Encode()
+
+	// An older version only knows about a, but keeps the rest.
+	var oldA uint16
+	var unknown []byte
+	v1 := New(FixedUint16(&oldA), Rest(&unknown))
+	require.NoError(t, v1.Decode(buf))
+	require.Equal(t, uint16(1), oldA)
+	oldA = 5
+	a, b, c = 0, 0, ""
+	require.NoError(t, v2.Decode(v1.Encode()))
+	require.Equal(t, uint16(5), a)
+	require.Equal(t, uint32(2), b)
+	require.Equal(t, "new", c)
+
+	require.NoError(t, v1.Decode([]byte{0x00, 0x07}))
+	require.Nil(t, unknown)
+	require.Equal(t, []byte{0x00, 0x07}, v1.Encode())
+}
@@ -0,0 +1,104 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrLengthMismatch = errors.New("encode: items did not decode to exactly their length")
+
+// Encode items one after another, prefixed with their total length as a width-byte big endian
+// integer. width must be 1, 2, 4, or 8.
+//
+// Since the length slot has a fixed width, it can be back-patched once the items are written instead
+// of being sized up front. The sizes of the items found by Size are remembered and reused by the
+// following Encode rather than asked for again, which matters for expensive dynamic items. This
+// also means the result must not be used concurrently.
+//
+// Panics when encoding if the total length doesn't fit in width bytes. Decoding returns
+// ErrLengthMismatch if the items don't decode to exactly the length.
+func DeferredLength(width int, items ...Item) Item {
+	switch width {
+	case 1, 2, 4, 8:
+	default:
+		panic(fmt.Sprintf("invalid width=%d, must be 1, 2, 4, or 8", width))
+	}
+	return deferredLength{width: width, items: items, sizes: make([]int, len(items))}
+}
+
+type deferredLength struct {
+	width int
+	items []Item
+	// The sizes of items, as of the last call to Size.
+	sizes []int
+}
+
+func (e deferredLength) Encode(buf []byte) {
+	i := e.width
+	for j, item := range e.items {
+		item.Encode(buf[i : i+e.sizes[j] : i+e.sizes[j]])
+		i += e.sizes[j]
+	}
+	l := uint64(i - e.width)
+	switch e.width {
+	case 1:
+		if l > 0xFF {
+			panic(fmt.Sprintf("length %d does not fit in 1 byte", l))
+		}
+		buf[0] = byte(l)
+	case 2:
+		if l > 0xFFFF {
+			panic(fmt.Sprintf("length %d does not fit in 2 bytes", l))
+		}
+		binary.BigEndian.PutUint16(buf, uint16(l))
+	case 4:
+		if l > 0xFFFFFFFF {
+			panic(fmt.Sprintf("length %d does not fit in 4 bytes", l))
+		}
+		binary.BigEndian.PutUint32(buf, uint32(l))
+	case 8:
+		binary.BigEndian.PutUint64(buf, l)
+	}
+}
+func (e deferredLength) Size() int {
+	size := e.width
+	for j, item := range e.items {
+		e.sizes[j] = item.Size()
+		size += e.sizes[j]
+	}
+	return size
+}
+func (e deferredLength) Decode(buf []byte) error {
+	if len(buf) < e.width {
+		return io.ErrUnexpectedEOF
+	}
+	var l uint64
+	switch e.width {
+	case 1:
+		l = uint64(buf[0])
+	case 2:
+		l = uint64(binary.BigEndian.Uint16(buf))
+	case 4:
+		l = uint64(binary.BigEndian.Uint32(buf))
+	case 8:
+		l = binary.BigEndian.Uint64(buf)
+	}
+	if uint64(len(buf)-e.width) < l {
+		return io.ErrUnexpectedEOF
+	}
+	b := buf[e.width : e.width+int(l)]
+	i := 0
+	for _, item := range e.items {
+		err := item.Decode(b[i:])
+		if err != nil {
+			return err
+		}
+		i += decodedSize(item)
+	}
+	if i != len(b) {
+		return ErrLengthMismatch
+	}
+	return nil
+}
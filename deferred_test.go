@@ -0,0 +1,47 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingSizeItem struct {
+	Item
+	n *int
+}
+
+func (e countingSizeItem) Size() int {
+	*e.n++
+	return e.Item.Size()
+}
+
+func TestDeferredLength(t *testing.T) {
+	s := "hello"
+	x := uint64(300)
+	after := byte(9)
+	var sizeCalls int
+	enc := New(
+		DeferredLength(2, countingSizeItem{LengthDelimString(&s), &sizeCalls}, Uvarint64(&x)),
+		Byte(&after),
+	)
+	b := enc.Encode()
+	require.Equal(t, []byte("\x00\x08\x05hello\xac\x02\x09"), b)
+	// Once for the total size of the Encoding and once for the item's own size before Encode, but
+	// not again during Encode.
+	require.Equal(t, 2, sizeCalls)
+
+	s, x, after = "", 0, 0
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, "hello", s)
+	require.Equal(t, uint64(300), x)
+	require.Equal(t, byte(9), after)
+
+	require.Equal(t, io.ErrUnexpectedEOF, New(DeferredLength(2, Uvarint64(&x))).Decode([]byte{0x00, 0x03, 0x01}))
+	require.Equal(t, ErrLengthMismatch, New(DeferredLength(1, Uvarint64(&x))).Decode([]byte{0x02, 0x01, 0x01}))
+
+	long := string(make([]byte, 256))
+	require.Panics(t, func() { New(DeferredLength(1, LengthDelimString(&long))).Encode() })
+	require.Panics(t, func() { DeferredLength(3) })
+}
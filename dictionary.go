@@ -0,0 +1,129 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var ErrInvalidDictionaryIndex = errors.New("encode: invalid dictionary index")
+
+// A dictionary of strings shared by a batch of records, so that each record encodes a repeated
+// string, such as a service name or region, as a small index into the dictionary instead of the
+// string itself. Typical usage looks like:
+//
+//   d := encode.NewDictionary()
+//   for _, r := range records {
+//   	b := encode.New(encode.Uvarint64(&r.id), d.String(&r.region)).Encode()
+//   	...
+//   }
+//   // Then write d's own encoding, encode.New(d.Item()).Encode(), with the batch.
+//
+// To decode, decode the dictionary into a new Dictionary with d.Item() before decoding any of the
+// records with d.String.
+type Dictionary struct {
+	strings []string
+	indexes map[string]uint64
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{indexes: make(map[string]uint64)}
+}
+
+// The number of distinct strings in the dictionary.
+func (d *Dictionary) Len() int {
+	return len(d.strings)
+}
+
+func (d *Dictionary) index(s string) uint64 {
+	i, ok := d.indexes[s]
+	if !ok {
+		i = uint64(len(d.strings))
+		d.strings = append(d.strings, s)
+		d.indexes[s] = i
+	}
+	return i
+}
+
+// Encode v as the uvarint index of v in d, adding it to d if it isn't already there. Decoding
+// returns ErrInvalidDictionaryIndex if the index isn't in d.
+func (d *Dictionary) String(v *string) Item {
+	return dictString{d: d, v: v}
+}
+
+type dictString struct {
+	d *Dictionary
+	v *string
+}
+
+func (e dictString) Encode(buf []byte) {
+	binary.PutUvarint(buf, e.d.index(*e.v))
+}
+func (e dictString) Size() int {
+	return uvarintSize(e.d.index(*e.v))
+}
+func (e dictString) Decode(buf []byte) error {
+	i, n := binary.Uvarint(buf)
+	if n == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return ErrOverflowVarint
+	}
+	if i >= uint64(len(e.d.strings)) {
+		return ErrInvalidDictionaryIndex
+	}
+	*e.v = e.d.strings[i]
+	return nil
+}
+
+// Encode the contents of d, as the uvarint number of strings followed by each string in index order
+// prefixed with its uvarint length. Decoding replaces the contents of d.
+func (d *Dictionary) Item() Item {
+	return dictItem{d}
+}
+
+type dictItem struct{ d *Dictionary }
+
+func (e dictItem) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(e.d.strings)))
+	for _, s := range e.d.strings {
+		n += binary.PutUvarint(buf[n:], uint64(len(s)))
+		n += copy(buf[n:], s)
+	}
+}
+func (e dictItem) Size() int {
+	size := uvarintSize(uint64(len(e.d.strings)))
+	for _, s := range e.d.strings {
+		size += uvarintSize(uint64(len(s))) + len(s)
+	}
+	return size
+}
+func (e dictItem) Decode(buf []byte) error {
+	count, n := binary.Uvarint(buf)
+	if n == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return ErrOverflowVarint
+	}
+	// Every string takes at least one byte, so this bounds the allocation below.
+	if count > uint64(len(buf)-n) {
+		return io.ErrUnexpectedEOF
+	}
+	strings := make([]string, 0, count)
+	indexes := make(map[string]uint64, count)
+	for i := uint64(0); i < count; i++ {
+		b, m, err := decodeLengthDelim(buf[n:])
+		if err != nil {
+			return err
+		}
+		n += m
+		s := string(b)
+		strings = append(strings, s)
+		indexes[s] = i
+	}
+	e.d.strings = strings
+	e.d.indexes = indexes
+	return nil
+}
@@ -0,0 +1,47 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDictionary(t *testing.T) {
+	type record struct {
+		id     uint64
+		region string
+	}
+	records := []record{
+		{1, "us-east-1"},
+		{2, "eu-west-1"},
+		{3, "us-east-1"},
+		{4, "us-east-1"},
+		{5, "ap-south-1"},
+	}
+
+	d := NewDictionary()
+	var encoded [][]byte
+	for i := range records {
+		r := &records[i]
+		encoded = append(encoded, New(Uvarint64(&r.id), d.String(&r.region)).Encode())
+	}
+	require.Equal(t, 3, d.Len())
+	require.Equal(t, []byte{0x03, 0x00}, encoded[2])
+	dict := New(d.Item()).Encode()
+
+	d2 := NewDictionary()
+	require.NoError(t, New(d2.Item()).Decode(dict))
+	require.Equal(t, 3, d2.Len())
+	for i, b := range encoded {
+		var r record
+		require.NoError(t, New(Uvarint64(&r.id), d2.String(&r.region)).Decode(b))
+		require.Equal(t, records[i], r)
+	}
+
+	var s string
+	require.Equal(t, ErrInvalidDictionaryIndex, New(d2.String(&s)).Decode([]byte{0x03}))
+	for i := 0; i < len(dict); i++ {
+		require.Equal(t, io.ErrUnexpectedEOF, New(d2.Item()).Decode(dict[:i]))
+	}
+}
@@ -0,0 +1,73 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// One item that differs between two encoded buffers.
+type FieldDiff struct {
+	// The index of the item within the Encoding.
+	Index int
+	// The item's encoding in each buffer.
+	Old []byte
+	New []byte
+	// Whether the item was wrapped in Sensitive. If so, String and GoString do not include Old and
+	// New.
+	Sensitive bool
+}
+
+func (d FieldDiff) String() string {
+	if d.Sensitive {
+		return fmt.Sprintf("item %d: %s", d.Index, redacted)
+	}
+	return fmt.Sprintf("item %d: %x -> %x", d.Index, d.Old, d.New)
+}
+func (d FieldDiff) GoString() string {
+	if d.Sensitive {
+		return fmt.Sprintf(
+			"encode.FieldDiff{Index: %d, Old: %s, New: %s, Sensitive: true}",
+			d.Index, redacted, redacted,
+		)
+	}
+	return fmt.Sprintf("encode.FieldDiff{Index: %d, Old: %#v, New: %#v}", d.Index, d.Old, d.New)
+}
+
+// Decode both a and b with enc and report which of its items differ, in order, comparing the bytes
+// each item was decoded from. Afterwards, enc's destinations hold the values decoded from b.
+func Diff(a, b []byte, enc Encoding) ([]FieldDiff, error) {
+	before, err := splitItems(a, enc)
+	if err != nil {
+		return nil, err
+	}
+	after, err := splitItems(b, enc)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []FieldDiff
+	for i := range enc.items {
+		if !bytes.Equal(before[i], after[i]) {
+			diffs = append(diffs, FieldDiff{
+				Index:     i,
+				Old:       before[i],
+				New:       after[i],
+				Sensitive: isSensitive(enc.items[i]),
+			})
+		}
+	}
+	return diffs, nil
+}
+
+// Decodes buf with enc, and returns the part of buf that each item was decoded from.
+func splitItems(buf []byte, enc Encoding) ([][]byte, error) {
+	err := enc.Decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	offsets := enc.itemOffsets(true)
+	result := make([][]byte, len(offsets))
+	for i, o := range offsets {
+		result[i] = append([]byte(nil), buf[o.Offset:o.Offset+o.Size]...)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	var a uint16
+	var b bool
+	var c uint64
+	enc := New(FixedUint16(&a), Bool(&b), Uvarint64(&c))
+
+	a, b, c = 1, true, 5
+	before := enc.Encode()
+	b, c = false, 300
+	after := enc.Encode()
+
+	diffs, err := Diff(before, after, enc)
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{
+		{Index: 1, Old: []byte{0x01}, New: []byte{0x00}},
+		{Index: 2, Old: []byte{0x05}, New: []byte{0xAC, 0x02}},
+	}, diffs)
+
+	diffs, err = Diff(after, after, enc)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	_, err = Diff(before, after[:1], enc)
+	require.Error(t, err)
+
+	// The bytes compared are the ones each item was actually decoded from, so an item that encodes
+	// differently every time, like Encrypted with its random nonce, only differs if its bytes did.
+	var s string
+	enc = New(FixedUint16(&a), Encrypted(LengthDelimString(&s), 1, testKeys(t, 1)), AlignTo(4))
+	a, s = 1, "secret"
+	before = enc.Encode()
+	after = append([]byte(nil), before...)
+	after[1] = 2
+	diffs, err = Diff(before, after, enc)
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{{Index: 0, Old: []byte{0x00, 0x01}, New: []byte{0x00, 0x02}}}, diffs)
+}
+
+func TestPatch(t *testing.T) {
+	var a uint16
+	var b bool
+	var c uint64
+	enc := New(FixedUint16(&a), Bool(&b), Uvarint64(&c))
+
+	a, b, c = 1, true, 5
+	before := enc.Encode()
+	b, c = false, 300
+	after := enc.Encode()
+
+	diffs, err := Diff(before, after, enc)
+	require.NoError(t, err)
+	patch := MakePatch(diffs)
+	require.Equal(t, []byte{0x02, 0x01, 0x01, 0x00, 0x02, 0x02, 0xAC, 0x02}, patch)
+
+	a, b, c = 0, false, 0
+	patched, err := ApplyPatch(before, patch, enc)
+	require.NoError(t, err)
+	require.Equal(t, after, patched)
+	require.Equal(t, uint64(300), c)
+
+	_, err = ApplyPatch(before, []byte{0x01, 0x03, 0x01, 0x00}, enc)
+	require.Equal(t, ErrInvalidPatch, err)
+	_, err = ApplyPatch(before, []byte{0x01, 0x00, 0x01}, enc)
+	require.Equal(t, ErrInvalidPatch, err)
+
+	// A patch that fails partway through leaves none of it applied.
+	_, err = ApplyPatch(before, []byte{0x02, 0x00, 0x02, 0x00, 0x09, 0x01, 0x01, 0x05}, enc)
+	require.Equal(t, ErrInvalidBool, err)
+	require.Equal(t, uint16(1), a)
+	require.True(t, b)
+	require.Equal(t, uint64(5), c)
+
+	// New encodings that only decode by running into each other's bytes aren't valid.
+	_, err = ApplyPatch(before, []byte{0x02, 0x00, 0x01, 0x00, 0x01, 0x02, 0x01, 0x01}, enc)
+	require.Equal(t, ErrInvalidPatch, err)
+	require.Equal(t, uint16(1), a)
+	require.True(t, b)
+	require.Equal(t, uint64(5), c)
+}
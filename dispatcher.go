@@ -0,0 +1,60 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrUnknownVersion = errors.New("encode: unknown version")
+
+// Decodes records that start with a version, such as a number or a Schema.Fingerprint, by routing
+// the rest of the record to the Encoding registered for that version. This is for reading data
+// written by several versions of a program.
+type Dispatcher struct {
+	encodings map[uint64]Encoding
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{encodings: make(map[uint64]Encoding)}
+}
+
+// Sets the Encoding to use for records of version. Panics if version is already registered.
+func (d *Dispatcher) Register(version uint64, enc Encoding) {
+	if _, ok := d.encodings[version]; ok {
+		panic(fmt.Sprintf("encode: version %d already registered", version))
+	}
+	d.encodings[version] = enc
+}
+
+// Encodes the record with the Encoding registered for version, prefixed with version as a uvarint.
+// Panics if version isn't registered.
+func (d *Dispatcher) Encode(version uint64) []byte {
+	enc, ok := d.encodings[version]
+	if !ok {
+		panic(fmt.Sprintf("encode: version %d not registered", version))
+	}
+	n := uvarintSize(version)
+	buf := make([]byte, n+enc.size())
+	binary.PutUvarint(buf, version)
+	enc.encodeTo(buf[n:])
+	return buf
+}
+
+// Reads the version at the start of buf and decodes the rest with the Encoding registered for it,
+// returning the version. Returns ErrUnknownVersion if no Encoding is registered for it.
+func (d *Dispatcher) Decode(buf []byte) (uint64, error) {
+	version, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return 0, ErrOverflowVarint
+	}
+	enc, ok := d.encodings[version]
+	if !ok {
+		return version, ErrUnknownVersion
+	}
+	return version, enc.Decode(buf[n:])
+}
@@ -0,0 +1,42 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	var id uint64
+	var name string
+	var email string
+
+	d := NewDispatcher()
+	d.Register(1, New(Uvarint64(&id), LengthDelimString(&name)))
+	d.Register(2, New(Uvarint64(&id), LengthDelimString(&name), LengthDelimString(&email)))
+	require.Panics(t, func() { d.Register(1, New()) })
+
+	id, name, email = 5, "a", "a@example.com"
+	v1 := d.Encode(1)
+	require.Equal(t, []byte("\x01\x05\x01a"), v1)
+	v2 := d.Encode(2)
+
+	id, name, email = 0, "", ""
+	version, err := d.Decode(v1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), version)
+	require.Equal(t, uint64(5), id)
+	require.Equal(t, "a", name)
+	require.Equal(t, "", email)
+
+	version, err = d.Decode(v2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), version)
+	require.Equal(t, "a@example.com", email)
+
+	version, err = d.Decode([]byte{0x03, 0x00})
+	require.Equal(t, ErrUnknownVersion, err)
+	require.Equal(t, uint64(3), version)
+
+	require.Panics(t, func() { d.Encode(3) })
+}
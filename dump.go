@@ -0,0 +1,72 @@
+package encode
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Returns a hex dump of buf as decoded by enc, one line per item, for diagnostic output such as
+// test failures. The bytes of Sensitive items are shown as redacted. Bytes after the last item,
+// which Decode ignores, are shown on a line of their own.
+//
+// If buf doesn't decode, everything from the item that failed onward is shown on one line, which is
+// redacted if any of the remaining items is Sensitive.
+//
+// Like Validate, this leaves enc's destinations with the values they had before, and doesn't run
+// the pre-decode and post-decode hooks.
+func (enc Encoding) Dump(buf []byte) string {
+	var sb strings.Builder
+	offsets, failed := enc.dumpOffsets(buf)
+	end := 0
+	for i, o := range offsets {
+		line := hex.EncodeToString(buf[o.Offset : o.Offset+o.Size])
+		if isSensitive(enc.items[i]) {
+			line = redacted
+		}
+		fmt.Fprintf(&sb, "item %d [%d, %d): %s\n", i, o.Offset, o.Offset+o.Size, line)
+		end = o.Offset + o.Size
+	}
+	if failed < len(enc.items) {
+		line := hex.EncodeToString(buf[end:])
+		for _, item := range enc.items[failed:] {
+			if isSensitive(item) {
+				line = redacted
+			}
+		}
+		fmt.Fprintf(&sb, "item %d failed [%d, %d): %s\n", failed, end, len(buf), line)
+	} else if end < len(buf) {
+		fmt.Fprintf(&sb, "trailing [%d, %d): %s\n", end, len(buf), hex.EncodeToString(buf[end:]))
+	}
+	return sb.String()
+}
+
+// Returns where each item of enc was in buf, up to the index of the first item that failed to
+// decode, or len(enc.items) if none did.
+func (enc Encoding) dumpOffsets(buf []byte) ([]FieldOffset, int) {
+	saved, err := enc.save()
+	if err != nil {
+		// The destinations couldn't be put back, so don't decode into them.
+		return nil, 0
+	}
+	d := enc
+	d.preDecode, d.postDecode = nil, nil
+	d.detailedErrors = true
+	_, err = d.decode(buf)
+	failed := len(enc.items)
+	var truncated *TruncatedError
+	var invalid *InvalidError
+	if errors.As(err, &truncated) {
+		failed = truncated.Index
+	} else if errors.As(err, &invalid) {
+		failed = invalid.Index
+	}
+	offsets := d.itemOffsets(true)[:failed]
+	_, err = d.decode(saved)
+	if err != nil {
+		// Restoring failed, see Validate. No Item in this package does this.
+		return nil, 0
+	}
+	return offsets, failed
+}
@@ -0,0 +1,37 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	var (
+		a uint16
+		b bool
+		s string
+	)
+	enc := New(FixedUint16(&a), Bool(&b), LengthDelimString(&s))
+	a, b, s = 7, true, "keep"
+
+	require.Equal(
+		t,
+		"item 0 [0, 2): 0102\n"+
+			"item 1 [2, 3): 00\n"+
+			"item 2 [3, 6): 026869\n"+
+			"trailing [6, 7): ff\n",
+		enc.Dump([]byte{0x01, 0x02, 0x00, 0x02, 'h', 'i', 0xFF}),
+	)
+	require.Equal(
+		t,
+		"item 0 [0, 2): 0102\n"+
+			"item 1 failed [2, 4): 0702\n",
+		enc.Dump([]byte{0x01, 0x02, 0x07, 0x02}),
+	)
+
+	// The values from before are left in place.
+	require.Equal(t, uint16(7), a)
+	require.True(t, b)
+	require.Equal(t, "keep", s)
+}
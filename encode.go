@@ -27,16 +27,22 @@ package encode
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"math/bits"
+	"strings"
+	"unicode/utf8"
 )
 
 var ErrOverflowVarint = errors.New("encode: overflowed varint")
 var ErrInvalidBool = errors.New("encode: invalid bool, encoded value not 0 or 1")
 var ErrInvalidVarint = errors.New("encode: invalid varint")
+var ErrInvalidEscape = errors.New("encode: invalid escape sequence")
+var ErrInvalidUTF8 = errors.New("encode: invalid UTF-8")
 
 type Item interface {
 	// Encode this item into buf. buf will be at least Size() bytes.
@@ -49,37 +55,200 @@ type Item interface {
 
 type Encoding struct {
 	items []Item
+
+	preDecode  func(buf []byte) error
+	postDecode func() error
+	stats      *Stats
+	name       string
+
+	detailedErrors bool
 }
 
 func New(items ...Item) Encoding {
 	return Encoding{items: items}
 }
 
+// Returns enc's items, in order.
+func (enc Encoding) Items() []Item {
+	return append([]Item(nil), enc.items...)
+}
+
 func (enc Encoding) Encode() []byte {
+	if enc.stats == nil && enc.name == "" {
+		return enc.encode()
+	}
+	var buf []byte
+	enc.instrument(context.Background(), opEncode, false, func(ctx context.Context) (int, error) {
+		buf = enc.encode()
+		return len(buf), nil
+	})
+	return buf
+}
+
+// Appends the encoding of enc to buf and returns the extended buffer. If buf has enough spare
+// capacity, this doesn't allocate.
+func (enc Encoding) AppendTo(buf []byte) []byte {
+	if enc.stats != nil || enc.name != "" {
+		return append(buf, enc.Encode()...)
+	}
+	n := enc.size()
+	start := len(buf)
+	if cap(buf)-start < n {
+		grown := make([]byte, start, start+n)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:start+n]
+	// Items may assume they're encoding into zeroed bytes.
+	clear(buf[start:])
+	enc.encodeTo(buf[start:])
+	return buf
+}
+
+func (enc Encoding) encode() []byte {
+	buf := make([]byte, enc.size())
+	enc.encodeTo(buf)
+	return buf
+}
+
+// The total size of enc's items.
+func (enc Encoding) size() int {
 	totalSize := 0
 	for _, item := range enc.items {
+		if size, ok := fixedSize(item); ok {
+			totalSize += size
+			continue
+		}
+		setOffset(item, totalSize)
 		totalSize += item.Size()
 	}
-	buf := make([]byte, totalSize)
+	return totalSize
+}
+
+// Encodes enc's items into buf, which must be at least size() bytes, and must have been preceded by
+// a call to size().
+func (enc Encoding) encodeTo(buf []byte) {
 	i := 0
 	for _, item := range enc.items {
-		size := item.Size()
-		item.Encode(buf[i : i+size])
-		i += size
+		i += encodeItem(item, buf[i:])
 	}
-	return buf
+}
+
+// The small items below make up most of the fields of a typical record, and for them the cost of
+// calling through Item dominates the work itself. fixedSize, encodeItem and decodeItem switch on
+// them so that their methods are called directly and can be inlined, and fall back to the Item
+// methods for everything else.
+
+// Returns item's size if it's one of the small fixed-size items.
+func fixedSize(item Item) (int, bool) {
+	switch item.(type) {
+	case encByte, encBool:
+		return 1, true
+	case fixedUint16:
+		return 2, true
+	case fixedUint32:
+		return 4, true
+	case fixedUint64:
+		return 8, true
+	}
+	return 0, false
+}
+
+// Encodes item at the start of buf and returns its size.
+func encodeItem(item Item, buf []byte) int {
+	switch e := item.(type) {
+	case encByte:
+		e.Encode(buf)
+		return 1
+	case encBool:
+		e.Encode(buf)
+		return 1
+	case fixedUint16:
+		e.Encode(buf)
+		return 2
+	case fixedUint32:
+		e.Encode(buf)
+		return 4
+	case fixedUint64:
+		e.Encode(buf)
+		return 8
+	}
+	size := item.Size()
+	item.Encode(buf[:size:size])
+	return size
+}
+
+// Decodes item from the start of buf. ok is false if item isn't one of the small fixed-size items,
+// in which case the caller must decode it itself.
+func decodeItem(item Item, buf []byte) (size int, ok bool, err error) {
+	switch e := item.(type) {
+	case encByte:
+		return 1, true, e.Decode(buf)
+	case encBool:
+		return 1, true, e.Decode(buf)
+	case fixedUint16:
+		return 2, true, e.Decode(buf)
+	case fixedUint32:
+		return 4, true, e.Decode(buf)
+	case fixedUint64:
+		return 8, true, e.Decode(buf)
+	}
+	return 0, false, nil
 }
 
 func (enc Encoding) Decode(buf []byte) error {
+	if enc.stats == nil && enc.name == "" {
+		_, err := enc.decode(buf)
+		return err
+	}
+	return enc.instrument(context.Background(), opDecode, false, func(ctx context.Context) (int, error) {
+		return enc.decode(buf)
+	})
+}
+
+// Implemented by items whose Size after Decode can differ from the number of bytes Decode used,
+// because what they decoded isn't what they'd encode. Decoding must advance by the latter to find
+// the next item.
+type decodedSizer interface {
+	decodedSize() int
+}
+
+// Returns the number of bytes item used in its last successful Decode.
+func decodedSize(item Item) int {
+	if d, ok := findItem[decodedSizer](item); ok {
+		return d.decodedSize()
+	}
+	return item.Size()
+}
+
+// Returns the number of bytes of buf that were decoded.
+func (enc Encoding) decode(buf []byte) (int, error) {
+	if enc.preDecode != nil {
+		err := enc.preDecode(buf)
+		if err != nil {
+			return 0, err
+		}
+	}
 	i := 0
-	for _, item := range enc.items {
+	for index, item := range enc.items {
+		if size, ok, err := decodeItem(item, buf[i:]); ok {
+			if err != nil {
+				return i, enc.itemError(index, i, buf, err)
+			}
+			i += size
+			continue
+		}
+		setOffset(item, i)
 		err := item.Decode(buf[i:])
 		if err != nil {
-			return err
+			return i, enc.itemError(index, i, buf, err)
 		}
-		i += item.Size()
+		i += decodedSize(item)
 	}
-	return nil
+	if enc.postDecode != nil {
+		return i, enc.postDecode()
+	}
+	return i, nil
 }
 
 // Quietly ignore n bytes.
@@ -92,6 +261,7 @@ type padding struct{ n int }
 func (e padding) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e padding) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e padding) SizeTuple(last bool) int                 { return e.Size() }
+func (e padding) OrderPreserving()                        {}
 func (e padding) Encode(buf []byte)                       {}
 func (e padding) Size() int {
 	return e.n
@@ -113,6 +283,7 @@ type encByte struct{ v *byte }
 func (e encByte) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e encByte) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e encByte) SizeTuple(last bool) int                 { return e.Size() }
+func (e encByte) OrderPreserving()                        {}
 func (e encByte) Encode(buf []byte) {
 	buf[0] = *e.v
 }
@@ -137,6 +308,7 @@ type encBool struct{ v *bool }
 func (e encBool) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e encBool) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e encBool) SizeTuple(last bool) int                 { return e.Size() }
+func (e encBool) OrderPreserving()                        {}
 func (e encBool) Encode(buf []byte) {
 	if *e.v {
 		buf[0] = 1
@@ -170,6 +342,7 @@ type fixedUint16 struct{ v *uint16 }
 func (e fixedUint16) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e fixedUint16) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e fixedUint16) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedUint16) OrderPreserving()                        {}
 func (e fixedUint16) Encode(buf []byte) {
 	binary.BigEndian.PutUint16(buf, *e.v)
 }
@@ -194,6 +367,7 @@ type fixedUint32 struct{ v *uint32 }
 func (e fixedUint32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e fixedUint32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e fixedUint32) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedUint32) OrderPreserving()                        {}
 func (e fixedUint32) Encode(buf []byte) {
 	binary.BigEndian.PutUint32(buf, *e.v)
 }
@@ -218,6 +392,7 @@ type fixedUint64 struct{ v *uint64 }
 func (e fixedUint64) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e fixedUint64) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e fixedUint64) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedUint64) OrderPreserving()                        {}
 func (e fixedUint64) Encode(buf []byte) {
 	binary.BigEndian.PutUint64(buf, *e.v)
 }
@@ -232,6 +407,162 @@ func (e fixedUint64) Decode(buf []byte) error {
 	return nil
 }
 
+// Encode v in big endian order, taking 3 bytes. This is common in audio samples and network
+// protocols. Panics when encoding if v doesn't fit in 24 bits.
+func FixedUint24(v *uint32) TupleItem {
+	return fixedUint24{v}
+}
+
+type fixedUint24 struct{ v *uint32 }
+
+func (e fixedUint24) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e fixedUint24) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e fixedUint24) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedUint24) OrderPreserving()                        {}
+func (e fixedUint24) Encode(buf []byte) {
+	x := *e.v
+	if x >= 1<<24 {
+		panic(fmt.Sprintf("%d does not fit in 24 bits", x))
+	}
+	_ = buf[2]
+	buf[0] = byte(x >> 16)
+	buf[1] = byte(x >> 8)
+	buf[2] = byte(x)
+}
+func (e fixedUint24) Size() int {
+	return 3
+}
+func (e fixedUint24) Decode(buf []byte) error {
+	if len(buf) < 3 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = uint32(buf[0])<<16 | uint32(buf[1])<<8 | uint32(buf[2])
+	return nil
+}
+
+// Encode v in big endian order, taking 6 bytes. This is the size of MAC addresses and of many
+// hardware timestamps. Panics when encoding if v doesn't fit in 48 bits.
+func FixedUint48(v *uint64) TupleItem {
+	return fixedUint48{v}
+}
+
+type fixedUint48 struct{ v *uint64 }
+
+func (e fixedUint48) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e fixedUint48) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e fixedUint48) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedUint48) OrderPreserving()                        {}
+func (e fixedUint48) Encode(buf []byte) {
+	x := *e.v
+	if x >= 1<<48 {
+		panic(fmt.Sprintf("%d does not fit in 48 bits", x))
+	}
+	binary.BigEndian.PutUint16(buf, uint16(x>>32))
+	binary.BigEndian.PutUint32(buf[2:], uint32(x))
+}
+func (e fixedUint48) Size() int {
+	return 6
+}
+func (e fixedUint48) Decode(buf []byte) error {
+	if len(buf) < 6 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = uint64(binary.BigEndian.Uint16(buf))<<32 | uint64(binary.BigEndian.Uint32(buf[2:]))
+	return nil
+}
+
+// Encode v in the given byte order, taking 2 bytes. This is for formats that allow either order, so
+// that it can be chosen at runtime. With binary.BigEndian this is the same as FixedUint16.
+func Uint16(v *uint16, order binary.ByteOrder) Item {
+	return orderedUint16{v: v, order: order}
+}
+
+type orderedUint16 struct {
+	v     *uint16
+	order binary.ByteOrder
+}
+
+func (e orderedUint16) Encode(buf []byte) {
+	e.order.PutUint16(buf, *e.v)
+}
+func (e orderedUint16) Size() int {
+	return 2
+}
+func (e orderedUint16) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = e.order.Uint16(buf)
+	return nil
+}
+
+// Encode v in the given byte order, taking 4 bytes. With binary.BigEndian this is the same as
+// FixedUint32.
+func Uint32(v *uint32, order binary.ByteOrder) Item {
+	return orderedUint32{v: v, order: order}
+}
+
+type orderedUint32 struct {
+	v     *uint32
+	order binary.ByteOrder
+}
+
+func (e orderedUint32) Encode(buf []byte) {
+	e.order.PutUint32(buf, *e.v)
+}
+func (e orderedUint32) Size() int {
+	return 4
+}
+func (e orderedUint32) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = e.order.Uint32(buf)
+	return nil
+}
+
+// Encode v in the given byte order, taking 8 bytes. With binary.BigEndian this is the same as
+// FixedUint64.
+func Uint64(v *uint64, order binary.ByteOrder) Item {
+	return orderedUint64{v: v, order: order}
+}
+
+type orderedUint64 struct {
+	v     *uint64
+	order binary.ByteOrder
+}
+
+func (e orderedUint64) Encode(buf []byte) {
+	e.order.PutUint64(buf, *e.v)
+}
+func (e orderedUint64) Size() int {
+	return 8
+}
+func (e orderedUint64) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = e.order.Uint64(buf)
+	return nil
+}
+
+// Encode v in the host's byte order, taking 2 bytes. The encoding differs between architectures, so
+// this is only for layouts shared between processes on the same machine, such as shared memory or
+// IPC, where swapping bytes would be wasted work.
+func NativeUint16(v *uint16) Item {
+	return Uint16(v, binary.NativeEndian)
+}
+
+// Encode v in the host's byte order, taking 4 bytes. See NativeUint16.
+func NativeUint32(v *uint32) Item {
+	return Uint32(v, binary.NativeEndian)
+}
+
+// Encode v in the host's byte order, taking 8 bytes. See NativeUint16.
+func NativeUint64(v *uint64) Item {
+	return Uint64(v, binary.NativeEndian)
+}
+
 // Encode v using a variable-length encoding, so that smaller numbers use fewer bytes.
 //
 // See more at https://developers.google.com/protocol-buffers/docs/encoding#varints
@@ -266,9 +597,13 @@ func (e uvarint32) Decode(buf []byte) error {
 	if n < 0 {
 		return ErrOverflowVarint
 	}
-	if n > math.MaxUint32 {
+	if l > math.MaxUint32 {
 		return ErrOverflowVarint
 	}
+	if n != uvarintSize(l) {
+		// Size is the minimal length, so accepting padding would leave the next item misaligned.
+		return ErrNonCanonical
+	}
 	*e.v = uint32(l)
 	return nil
 }
@@ -312,6 +647,10 @@ func (e uvarint64) Decode(buf []byte) error {
 	if n < 0 {
 		return ErrOverflowVarint
 	}
+	if n != uvarintSize(l) {
+		// Size is the minimal length, so accepting padding would leave the next item misaligned.
+		return ErrNonCanonical
+	}
 	*e.v = l
 	return nil
 }
@@ -343,20 +682,24 @@ type ordUvarint64 struct{ v *uint64 }
 func (e ordUvarint64) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e ordUvarint64) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e ordUvarint64) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordUvarint64) OrderPreserving()                        {}
 func (e ordUvarint64) Encode(buf []byte) {
 	l := bits.Len64(*e.v)
 	if l > 56 {
+		buf = buf[:9]
 		buf[0] = 0xFF
 		binary.BigEndian.PutUint64(buf[1:], *e.v)
 		return
 	}
 
-	nBytes := (l + 6) / 7
+	nBytes := 1 + (l-1)/7
 	nLeadingOnes := nBytes - 1
-	buf[0] = ((1 << uint(nLeadingOnes)) - 1) << uint(8-nLeadingOnes)
-	for i := 0; i < nBytes; i++ {
-		buf[i] |= byte(*e.v >> uint((nBytes-i-1)*8))
-	}
+	// Build the whole encoding in the high-order bytes of a uint64 and store it all at once, which
+	// compiles to a single byte-swapping store rather than a loop over bytes.
+	x := ^uint64(0)<<uint(64-nLeadingOnes) | *e.v<<uint(64-8*nBytes)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	copy(buf, b[:nBytes])
 }
 func (e ordUvarint64) Size() int {
 	l := bits.Len64(*e.v)
@@ -382,6 +725,12 @@ func (e ordUvarint64) Decode(buf []byte) error {
 		return nil
 	}
 
+	if len(buf) >= 8 {
+		// Load all at once and shift away the bytes that belong to whatever comes next, which is a
+		// single byte-swapping load rather than a loop over bytes.
+		*e.v = (binary.BigEndian.Uint64(buf) >> uint(64-8*nBytes)) & ((uint64(1) << uint(rBits)) - 1)
+		return nil
+	}
 	if len(buf) < nBytes {
 		return io.ErrUnexpectedEOF
 	}
@@ -428,12 +777,14 @@ func OrdVarint64(v *int64) TupleItem {
 
 type ordVarint64 struct{ v *int64 }
 
+func (e ordVarint64) OrderPreserving() {}
 func (e ordVarint64) EncodeTuple(buf []byte, last bool) {
 	e.Encode(buf)
 }
 func (e ordVarint64) Encode(buf []byte) {
 	switch {
 	case *e.v <= -(1<<55)-1:
+		_ = buf[8] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x00
 		buf[1] = byte(*e.v>>56) & 0x7F
 		buf[2] = byte(*e.v >> 48)
@@ -444,6 +795,7 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[7] = byte(*e.v >> 8)
 		buf[8] = byte(*e.v)
 	case -(1<<55) <= *e.v && *e.v <= -(1<<48)-1:
+		_ = buf[7] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x00
 		buf[1] = 0x80 | byte(*e.v>>48)
 		buf[2] = byte(*e.v >> 40)
@@ -453,6 +805,7 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[6] = byte(*e.v >> 8)
 		buf[7] = byte(*e.v)
 	case -(1<<48) <= *e.v && *e.v <= -(1<<41)-1:
+		_ = buf[6] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x01
 		buf[1] = byte(*e.v >> 40)
 		buf[2] = byte(*e.v >> 32)
@@ -461,6 +814,7 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[5] = byte(*e.v >> 8)
 		buf[6] = byte(*e.v)
 	case -(1<<41) <= *e.v && *e.v <= -(1<<34)-1:
+		_ = buf[5] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x02 | (byte(*e.v>>40) & 0x01)
 		buf[1] = byte(*e.v >> 32)
 		buf[2] = byte(*e.v >> 24)
@@ -468,21 +822,25 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[4] = byte(*e.v >> 8)
 		buf[5] = byte(*e.v)
 	case -(1<<34) <= *e.v && *e.v <= -(1<<27)-1:
+		_ = buf[4] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x04 | (byte(*e.v>>32) & 0x03)
 		buf[1] = byte(*e.v >> 24)
 		buf[2] = byte(*e.v >> 16)
 		buf[3] = byte(*e.v >> 8)
 		buf[4] = byte(*e.v)
 	case -(1<<27) <= *e.v && *e.v <= -(1<<20)-1:
+		_ = buf[3] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x08 | (byte(*e.v>>24) & 0x07)
 		buf[1] = byte(*e.v >> 16)
 		buf[2] = byte(*e.v >> 8)
 		buf[3] = byte(*e.v)
 	case -(1<<20) <= *e.v && *e.v <= -(1<<13)-1:
+		_ = buf[2] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x10 | (byte(*e.v>>16) & 0x0F)
 		buf[1] = byte(*e.v >> 8)
 		buf[2] = byte(*e.v)
 	case -(1<<13) <= *e.v && *e.v <= -(1<<6)-1:
+		_ = buf[1] // early bounds check to guarantee safety of writes below
 		buf[0] = 0x20 | (byte(*e.v>>8) & 0x1F)
 		buf[1] = byte(*e.v)
 	case -(1<<6) <= *e.v && *e.v <= -1:
@@ -490,24 +848,29 @@ func (e ordVarint64) Encode(buf []byte) {
 	case 0 <= *e.v && *e.v <= (1<<6)-1:
 		buf[0] = 0x80 | byte(*e.v)
 	case (1<<6) <= *e.v && *e.v <= (1<<13)-1:
+		_ = buf[1] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xC0 | byte(*e.v>>8)
 		buf[1] = byte(*e.v)
 	case (1<<13) <= *e.v && *e.v <= (1<<20)-1:
+		_ = buf[2] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xE0 | byte(*e.v>>16)
 		buf[1] = byte(*e.v >> 8)
 		buf[2] = byte(*e.v)
 	case (1<<20) <= *e.v && *e.v <= (1<<27)-1:
+		_ = buf[3] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xF0 | byte(*e.v>>24)
 		buf[1] = byte(*e.v >> 16)
 		buf[2] = byte(*e.v >> 8)
 		buf[3] = byte(*e.v)
 	case (1<<27) <= *e.v && *e.v <= (1<<34)-1:
+		_ = buf[4] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xF8 | byte(*e.v>>32)
 		buf[1] = byte(*e.v >> 24)
 		buf[2] = byte(*e.v >> 16)
 		buf[3] = byte(*e.v >> 8)
 		buf[4] = byte(*e.v)
 	case (1<<34) <= *e.v && *e.v <= (1<<41)-1:
+		_ = buf[5] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xFC | byte(*e.v>>40)
 		buf[1] = byte(*e.v >> 32)
 		buf[2] = byte(*e.v >> 24)
@@ -515,6 +878,7 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[4] = byte(*e.v >> 8)
 		buf[5] = byte(*e.v)
 	case (1<<41) <= *e.v && *e.v <= (1<<48)-1:
+		_ = buf[6] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xFE | byte(*e.v>>48)
 		buf[1] = byte(*e.v >> 40)
 		buf[2] = byte(*e.v >> 32)
@@ -523,6 +887,7 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[5] = byte(*e.v >> 8)
 		buf[6] = byte(*e.v)
 	case (1<<48) <= *e.v && *e.v <= (1<<55)-1:
+		_ = buf[7] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xFF
 		buf[1] = byte(*e.v >> 48)
 		buf[2] = byte(*e.v >> 40)
@@ -532,6 +897,7 @@ func (e ordVarint64) Encode(buf []byte) {
 		buf[6] = byte(*e.v >> 8)
 		buf[7] = byte(*e.v)
 	case (1 << 55) <= *e.v:
+		_ = buf[8] // early bounds check to guarantee safety of writes below
 		buf[0] = 0xFF
 		buf[1] = 0x80 | byte(*e.v>>56)
 		buf[2] = byte(*e.v >> 48)
@@ -553,6 +919,7 @@ func (e ordVarint64) Size() int {
 	l := bits.Len64(uv ^ signMask)
 	return 1 + l/7 - l/63
 }
+
 func (e ordVarint64) DecodeTuple(buf []byte, last bool) error {
 	return e.Decode(buf)
 }
@@ -740,34 +1107,82 @@ func (e ordVarint64) Decode(buf []byte) error {
 	return nil
 }
 
+// Encode v as an order-preserving variable-length integer, using the same format as OrdVarint64. Small
+// magnitudes use 1 or 2 bytes and any int32 fits in at most 5, so short keys of small signed integers
+// don't need to be widened to int64. Decoding a value outside the range of an int32 returns
+// ErrOverflowVarint.
+func OrdVarint32(v *int32) TupleItem {
+	return ordVarint32{v}
+}
+
+type ordVarint32 struct{ v *int32 }
+
+func (e ordVarint32) OrderPreserving()                        {}
+func (e ordVarint32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ordVarint32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ordVarint32) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordVarint32) Encode(buf []byte) {
+	x := int64(*e.v)
+	ordVarint64{&x}.Encode(buf)
+}
+func (e ordVarint32) Size() int {
+	x := int64(*e.v)
+	return ordVarint64{&x}.Size()
+}
+func (e ordVarint32) Decode(buf []byte) error {
+	var x int64
+	err := ordVarint64{&x}.Decode(buf)
+	if err != nil {
+		return err
+	}
+	if x < math.MinInt32 || x > math.MaxInt32 {
+		return ErrOverflowVarint
+	}
+	*e.v = int32(x)
+	return nil
+}
+
 // Encodes v, using {delim,0x00} as the ending delimeter. delim is allowed to appear in v, and will
 // be escaped with a following 0xFF per occurrence.
-func DelimBytes(v *[]byte, delim byte) TupleItem {
-	return delimBytes{v: v, delim: delim}
+//
+// This is only order-preserving when delim is 0x00, so only then is the result a TupleItem, the same
+// as EscapedBytes.
+func DelimBytes(v *[]byte, delim byte) Item {
+	if delim == 0x00 {
+		return EscapedBytes(v)
+	}
+	return unorderedDelimBytes{delimBytes{v: v, delim: delim}}
 }
 
+// delimBytes without the TupleItem methods, since it doesn't sort unless delim is 0x00.
+type unorderedDelimBytes struct{ e delimBytes }
+
+func (e unorderedDelimBytes) Encode(buf []byte)       { e.e.Encode(buf) }
+func (e unorderedDelimBytes) Decode(buf []byte) error { return e.e.Decode(buf) }
+func (e unorderedDelimBytes) Size() int               { return e.e.Size() }
+
 type delimBytes struct {
 	v     *[]byte
 	delim byte
 }
 
+func (e delimBytes) OrderPreserving() {}
 func (e delimBytes) Encode(buf []byte) {
 	e.EncodeTuple(buf, false)
 }
 func (e delimBytes) EncodeTuple(buf []byte, last bool) {
-	i := 0
 	j := 0
-	for i = 0; i < len(buf); i++ {
-		b := (*e.v)[i]
-		buf[i+j] = b
+	for _, b := range *e.v {
+		buf[j] = b
+		j++
 		if b == e.delim {
-			buf[i+j+1] = 0xFF
+			buf[j] = 0xFF
 			j++
 		}
 	}
 	if !last {
-		buf[i+j] = e.delim
-		buf[i+j+1] = 0x00
+		buf[j] = e.delim
+		buf[j+1] = 0x00
 	}
 }
 func (e delimBytes) Size() int {
@@ -786,25 +1201,92 @@ func (e delimBytes) Decode(buf []byte) error {
 	return e.DecodeTuple(buf, false)
 }
 func (e delimBytes) DecodeTuple(buf []byte, last bool) error {
-	j := 0
-	for i := 0; i < len(buf); i++ {
-		b := buf[i]
-		(*e.v)[i-j] = b
-		if b == e.delim && !(i == len(buf)-1 && last) {
-			if len(buf) <= i+1 {
+	result := make([]byte, 0, len(buf))
+	i := 0
+	for {
+		if i >= len(buf) {
+			if !last {
 				return io.ErrUnexpectedEOF
 			}
-			j++
+			break
+		}
+		b := buf[i]
+		if b != e.delim {
+			result = append(result, b)
 			i++
-			if buf[i+1] == 0x00 {
-				break
-			}
+			continue
+		}
+		if i+1 >= len(buf) {
+			return io.ErrUnexpectedEOF
 		}
+		if buf[i+1] == 0xFF {
+			result = append(result, b)
+			i += 2
+			continue
+		}
+		if buf[i+1] == 0x00 && !last {
+			break
+		}
+		return ErrInvalidEscape
 	}
+	*e.v = result
+	return nil
+}
+
+// Encode v so that it can be used anywhere in a Tuple: each 0x00 byte is escaped as {0x00,0xFF}, and
+// the end is marked with {0x00,0x00}. This preserves ordering, including that v orders before any
+// longer value it is a prefix of. When v is the last item of a Tuple, the end marker is left off.
+//
+// Prefer LengthDelimBytes outside of Tuples, since it doesn't need to scan for the end.
+func EscapedBytes(v *[]byte) TupleItem {
+	return delimBytes{v: v, delim: 0x00}
+}
+
+// Encode v in the same way as EscapedBytes.
+//
+// Prefer LengthDelimString outside of Tuples, since it doesn't need to scan for the end.
+func EscapedString(v *string) TupleItem {
+	return escapedString{v}
+}
+
+type escapedString struct{ v *string }
+
+func (e escapedString) OrderPreserving() {}
+func (e escapedString) Encode(buf []byte) {
+	e.EncodeTuple(buf, false)
+}
+func (e escapedString) EncodeTuple(buf []byte, last bool) {
+	b := []byte(*e.v)
+	EscapedBytes(&b).EncodeTuple(buf, last)
+}
+func (e escapedString) Size() int {
+	return e.SizeTuple(false)
+}
+func (e escapedString) SizeTuple(last bool) int {
+	n := len(*e.v) + strings.Count(*e.v, "\x00")
+	if !last {
+		n += 2
+	}
+	return n
+}
+func (e escapedString) Decode(buf []byte) error {
+	return e.DecodeTuple(buf, false)
+}
+func (e escapedString) DecodeTuple(buf []byte, last bool) error {
+	var b []byte
+	err := EscapedBytes(&b).DecodeTuple(buf, last)
+	if err != nil {
+		return err
+	}
+	*e.v = string(b)
 	return nil
 }
 
 // Encode v as a uvarint of v's length, followed by v.
+//
+// This doesn't preserve ordering, so it can't be used in a Tuple. See EscapedBytes.
+//
+// Bytes takes options, such as a maximum length.
 func LengthDelimBytes(v *[]byte) Item {
 	return lengthDelimBytes{v}
 }
@@ -813,28 +1295,43 @@ type lengthDelimBytes struct{ v *[]byte }
 
 func (e lengthDelimBytes) Encode(buf []byte) {
 	n := binary.PutUvarint(buf, uint64(len(*e.v)))
-	copy(buf, (*e.v)[n:])
+	copy(buf[n:], *e.v)
 }
 func (e lengthDelimBytes) Size() int {
 	return uvarintSize(uint64(len(*e.v))) + len(*e.v)
 }
 func (e lengthDelimBytes) Decode(buf []byte) error {
-	l, n := binary.Uvarint(buf)
-	if n == 0 {
-		return io.ErrUnexpectedEOF
-	}
-	if n < 0 {
-		return ErrOverflowVarint
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
 	}
-	if uint64(len(buf[n:])) < l {
-		return io.ErrUnexpectedEOF
+	*e.v = make([]byte, len(b))
+	copy(*e.v, b)
+	return nil
+}
+
+// Like LengthDelimBytes, but decoding sets v to a subslice of the decoded buffer instead of a copy,
+// so it doesn't allocate. v is only valid as long as the buffer passed to Decode is not modified.
+func LengthDelimBytesNoCopy(v *[]byte) Item {
+	return lengthDelimBytesNoCopy{lengthDelimBytes{v}}
+}
+
+type lengthDelimBytesNoCopy struct{ lengthDelimBytes }
+
+func (e lengthDelimBytesNoCopy) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
 	}
-	*e.v = make([]byte, l)
-	copy(buf[n:], *e.v)
+	*e.v = b[:len(b):len(b)]
 	return nil
 }
 
 // Encode v as a uvarint of v's length, followed by v.
+//
+// This doesn't preserve ordering, so it can't be used in a Tuple. See EscapedString.
+//
+// String takes options, such as a maximum length.
 func LengthDelimString(v *string) Item {
 	return lengthDelimString{v}
 }
@@ -843,23 +1340,53 @@ type lengthDelimString struct{ v *string }
 
 func (e lengthDelimString) Encode(buf []byte) {
 	n := binary.PutUvarint(buf, uint64(len(*e.v)))
-	copy(buf, (*e.v)[n:])
+	copy(buf[n:], *e.v)
 }
 func (e lengthDelimString) Size() int {
 	return uvarintSize(uint64(len(*e.v))) + len(*e.v)
 }
 func (e lengthDelimString) Decode(buf []byte) error {
-	l, n := binary.Uvarint(buf)
-	if n == 0 {
-		return io.ErrUnexpectedEOF
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
 	}
+	*e.v = string(b)
+	return nil
+}
+
+// Encode v as UTF-8, taking 1 to 4 bytes. Invalid code points are encoded as utf8.RuneError, and
+// decoding anything other than a single valid UTF-8 sequence returns ErrInvalidUTF8.
+//
+// UTF-8 orders the same way as code points, so this can be used in a Tuple.
+func Rune(v *rune) TupleItem {
+	return encRune{v}
+}
+
+type encRune struct{ v *rune }
+
+func (e encRune) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e encRune) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e encRune) SizeTuple(last bool) int                 { return e.Size() }
+func (e encRune) OrderPreserving()                        {}
+func (e encRune) Encode(buf []byte) {
+	utf8.EncodeRune(buf, *e.v)
+}
+func (e encRune) Size() int {
+	n := utf8.RuneLen(*e.v)
 	if n < 0 {
-		return ErrOverflowVarint
+		return utf8.RuneLen(utf8.RuneError)
 	}
-	if uint64(len(buf[n:])) < l {
+	return n
+}
+func (e encRune) Decode(buf []byte) error {
+	if !utf8.FullRune(buf) {
 		return io.ErrUnexpectedEOF
 	}
-	*e.v = string(buf[n:])
+	r, n := utf8.DecodeRune(buf)
+	if r == utf8.RuneError && n <= 1 {
+		return ErrInvalidUTF8
+	}
+	*e.v = r
 	return nil
 }
 
@@ -873,6 +1400,7 @@ type bytes16 struct{ v *[16]byte }
 func (e bytes16) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e bytes16) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e bytes16) SizeTuple(last bool) int                 { return e.Size() }
+func (e bytes16) OrderPreserving()                        {}
 func (e bytes16) Encode(buf []byte) {
 	copy(buf, (*e.v)[:])
 }
@@ -897,6 +1425,7 @@ type bytes32 struct{ v *[32]byte }
 func (e bytes32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e bytes32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e bytes32) SizeTuple(last bool) int                 { return e.Size() }
+func (e bytes32) OrderPreserving()                        {}
 func (e bytes32) Encode(buf []byte) {
 	copy(buf, (*e.v)[:])
 }
@@ -2,9 +2,13 @@ package encode
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"io"
+	"math"
 	"math/rand"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/bradenaw/trand"
 	"github.com/stretchr/testify/require"
@@ -20,6 +24,12 @@ func TestOrdUvarint64(t *testing.T) {
 		err := enc.Decode(b)
 		require.NoError(t, err)
 		require.Equal(t, x2, x)
+
+		// Followed by enough bytes to take the whole-word path when decoding.
+		x = ^x
+		err = enc.Decode(append(b, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF))
+		require.NoError(t, err)
+		require.Equal(t, x2, x)
 	}
 
 	checkOrdering := func(x uint64, x2 uint64) {
@@ -159,6 +169,43 @@ func TestOrdVarint64(t *testing.T) {
 	})
 }
 
+func TestOrdVarint32(t *testing.T) {
+	check := func(x int32, size int) {
+		enc := New(OrdVarint32(&x))
+		b := enc.Encode()
+		require.Len(t, b, size)
+		x64 := int64(x)
+		require.Equal(t, New(OrdVarint64(&x64)).Encode(), b)
+		x2 := x
+		x = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x2, x)
+	}
+	check(0, 1)
+	check(-64, 1)
+	check(63, 1)
+	check(-65, 2)
+	check(8191, 2)
+	check(math.MinInt32, 5)
+	check(math.MaxInt32, 5)
+
+	trand.RandomN(t, 10000, func(t *testing.T, r *rand.Rand) {
+		x1 := int32(r.Uint32()) >> uint(r.Intn(32))
+		x2 := int32(r.Uint32()) >> uint(r.Intn(32))
+		check(x1, OrdVarint32(&x1).Size())
+		check(x2, OrdVarint32(&x2).Size())
+		b1 := New(OrdVarint32(&x1)).Encode()
+		b2 := New(OrdVarint32(&x2)).Encode()
+		require.Equal(t, x1 < x2, bytes.Compare(b1, b2) < 0)
+	})
+
+	var x int32
+	for _, x64 := range []int64{math.MinInt32 - 1, math.MaxInt32 + 1} {
+		b := New(OrdVarint64(&x64)).Encode()
+		require.Equal(t, ErrOverflowVarint, New(OrdVarint32(&x)).Decode(b))
+	}
+}
+
 func BenchmarkOrdUvarint64Encode(b *testing.B) {
 	bunchaUint64s := make([]uint64, b.N)
 	for i := range bunchaUint64s {
@@ -193,3 +240,201 @@ func BenchmarkOrdUvarint64Decode(b *testing.B) {
 		_ = enc.Decode(bunchaEncoded[i%len(bunchaEncoded)])
 	}
 }
+
+func TestEscapedBytes(t *testing.T) {
+	checkRoundtrip := func(x []byte) {
+		var other uint16 = 7
+		for _, last := range []bool{false, true} {
+			var tuple Tuple
+			if last {
+				tuple = NewTuple(FixedUint16(&other), EscapedBytes(&x))
+			} else {
+				tuple = NewTuple(EscapedBytes(&x), FixedUint16(&other))
+			}
+			x2 := x
+			b := tuple.Encode()
+			x = []byte("garbage")
+			other = 0
+			require.NoError(t, tuple.Decode(b))
+			require.Equal(t, x2, x)
+			require.Equal(t, uint16(7), other)
+		}
+	}
+
+	checkOrdering := func(x []byte, x2 []byte) {
+		checkRoundtrip(x)
+		checkRoundtrip(x2)
+
+		s, s2 := string(x), string(x2)
+		for _, last := range []bool{false, true} {
+			// A Tuple leaves off the end marker of its last item, Encoding never does.
+			var b, b2 []byte
+			if last {
+				b = NewTuple(EscapedBytes(&x)).Encode()
+				b2 = NewTuple(EscapedBytes(&x2)).Encode()
+			} else {
+				b = New(EscapedBytes(&x)).Encode()
+				b2 = New(EscapedBytes(&x2)).Encode()
+			}
+			require.True(
+				t,
+				bytes.Compare(b, b2) < 0,
+				"%x < %x but %x >= %x", x, x2, b, b2,
+			)
+
+			bs := New(EscapedString(&s)).Encode()
+			require.Equal(t, New(EscapedBytes(&x)).Encode(), bs)
+			bs2 := New(EscapedString(&s2)).Encode()
+			require.True(t, bytes.Compare(bs, bs2) < 0)
+		}
+	}
+
+	checkOrdering([]byte{}, []byte{0x00})
+	checkOrdering([]byte{0x00}, []byte{0x00, 0x00})
+	checkOrdering([]byte{0x00, 0xFF}, []byte{0x01})
+	checkOrdering([]byte("a"), []byte("a\x00"))
+	checkOrdering([]byte("a\x00"), []byte("a\x01"))
+	checkOrdering([]byte("a\xFF"), []byte("b"))
+
+	trand.RandomN(t, 10000, func(t *testing.T, r *rand.Rand) {
+		randBytes := func() []byte {
+			b := make([]byte, r.Intn(5))
+			for i := range b {
+				b[i] = []byte{0x00, 0x01, 0xFE, 0xFF}[r.Intn(4)]
+			}
+			return b
+		}
+		x1 := randBytes()
+		x2 := randBytes()
+		c := bytes.Compare(x1, x2)
+		if c == 0 {
+			return
+		}
+		if c > 0 {
+			x1, x2 = x2, x1
+		}
+		checkOrdering(x1, x2)
+	})
+
+	var x []byte
+	require.Equal(t, io.ErrUnexpectedEOF, New(EscapedBytes(&x)).Decode([]byte{0x01, 0x00}))
+	require.Equal(t, ErrInvalidEscape, New(EscapedBytes(&x)).Decode([]byte{0x01, 0x00, 0x02}))
+}
+
+func TestLengthDelim(t *testing.T) {
+	b := []byte("hello")
+	s := "world"
+	enc := New(LengthDelimBytes(&b), LengthDelimString(&s))
+	buf := enc.Encode()
+	require.Equal(t, []byte("\x05hello\x05world"), buf)
+
+	b = nil
+	s = ""
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []byte("hello"), b)
+	require.Equal(t, "world", s)
+
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(buf[:9]))
+
+	// A padded length would leave s decoded from the wrong place, since Size only counts the
+	// minimal length.
+	require.Equal(t, ErrNonCanonical, enc.Decode([]byte("\x85\x00hello\x05world")))
+}
+
+func TestFixedUint24And48(t *testing.T) {
+	a, b := uint32(0x010203), uint64(0x010203040506)
+	enc := NewTuple(FixedUint24(&a), FixedUint48(&b))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x01, 0x02, 0x03, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, buf)
+
+	a, b = 0, 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint32(0x010203), a)
+	require.Equal(t, uint64(0x010203040506), b)
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(buf[:8]))
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		a1, a2 := uint32(r.Intn(1<<24)), uint32(r.Intn(1<<24))
+		b1, b2 := uint64(r.Int63n(1<<48)), uint64(r.Int63n(1<<48))
+		if a2 < a1 {
+			a1, a2 = a2, a1
+		}
+		if b2 < b1 {
+			b1, b2 = b2, b1
+		}
+		require.True(t, bytes.Compare(NewTuple(FixedUint24(&a1)).Encode(), NewTuple(FixedUint24(&a2)).Encode()) <= 0)
+		require.True(t, bytes.Compare(NewTuple(FixedUint48(&b1)).Encode(), NewTuple(FixedUint48(&b2)).Encode()) <= 0)
+	})
+
+	a = 1 << 24
+	require.Panics(t, func() { New(FixedUint24(&a)).Encode() })
+	b = 1 << 48
+	require.Panics(t, func() { New(FixedUint48(&b)).Encode() })
+}
+
+func TestByteOrderUints(t *testing.T) {
+	a, b, c := uint16(0x0102), uint32(0x01020304), uint64(0x0102030405060708)
+	enc := New(Uint16(&a, binary.LittleEndian), Uint32(&b, binary.LittleEndian), Uint64(&c, binary.BigEndian))
+	buf := enc.Encode()
+	require.Equal(t, []byte{
+		0x02, 0x01,
+		0x04, 0x03, 0x02, 0x01,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+	}, buf)
+
+	a, b, c = 0, 0, 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint16(0x0102), a)
+	require.Equal(t, uint32(0x01020304), b)
+	require.Equal(t, uint64(0x0102030405060708), c)
+
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(buf[:13]))
+}
+
+func TestNativeUints(t *testing.T) {
+	a, b, c := uint16(0x0102), uint32(0x01020304), uint64(0x0102030405060708)
+	buf := New(NativeUint16(&a), NativeUint32(&b), NativeUint64(&c)).Encode()
+
+	expected := make([]byte, 14)
+	binary.NativeEndian.PutUint16(expected, a)
+	binary.NativeEndian.PutUint32(expected[2:], b)
+	binary.NativeEndian.PutUint64(expected[6:], c)
+	require.Equal(t, expected, buf)
+
+	var a2 uint16
+	var b2 uint32
+	var c2 uint64
+	require.NoError(t, New(NativeUint16(&a2), NativeUint32(&b2), NativeUint64(&c2)).Decode(buf))
+	require.Equal(t, a, a2)
+	require.Equal(t, b, b2)
+	require.Equal(t, c, c2)
+}
+
+func TestRune(t *testing.T) {
+	check := func(r rune, expected []byte) {
+		enc := New(Rune(&r))
+		b := enc.Encode()
+		require.Equal(t, expected, b)
+		r2 := r
+		r = 0
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, r2, r)
+	}
+	check('a', []byte("a"))
+	check('é', []byte("é"))
+	check('€', []byte("€"))
+	check('😀', []byte("😀"))
+	check(utf8.RuneError, []byte("�"))
+
+	invalid := rune(0xD800)
+	require.Equal(t, []byte("�"), New(Rune(&invalid)).Encode())
+
+	var r rune
+	require.Equal(t, io.ErrUnexpectedEOF, New(Rune(&r)).Decode([]byte{}))
+	require.Equal(t, io.ErrUnexpectedEOF, New(Rune(&r)).Decode([]byte{0xE2, 0x82}))
+	require.Equal(t, ErrInvalidUTF8, New(Rune(&r)).Decode([]byte{0xFF}))
+	require.Equal(t, ErrInvalidUTF8, New(Rune(&r)).Decode([]byte{0xC0, 0x80}))
+
+	a, b := 'z', 'é'
+	require.True(t, bytes.Compare(NewTuple(Rune(&a)).Encode(), NewTuple(Rune(&b)).Encode()) < 0)
+}
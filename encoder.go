@@ -0,0 +1,72 @@
+package encode
+
+import (
+	"context"
+	"math/big"
+)
+
+// Encodes records repeatedly into the same buffer, for hot paths that encode many records one after
+// another on one goroutine. Once the buffer has grown to fit the largest record, encoding doesn't
+// allocate, including for items like Bitpacked and Rat that otherwise need temporary space each time.
+//
+// An Encoder must not be used concurrently. To share them between goroutines, keep them in a
+// sync.Pool and Reset them to the Encoding needed.
+type Encoder struct {
+	enc     Encoding
+	buf     []byte
+	scratch scratch
+}
+
+func NewEncoder(enc Encoding) *Encoder {
+	return &Encoder{enc: enc}
+}
+
+// Temporary space used while encoding a single item.
+type scratch struct {
+	bits bitBuffer
+	n    big.Int
+}
+
+// Implemented by items that need temporary space to encode, so that an Encoder can lend them its own
+// rather than have them allocate their own each time.
+type scratchEncoder interface {
+	encodeScratch(buf []byte, s *scratch)
+}
+
+// Switches e to encoding enc, keeping the buffers it has already allocated.
+func (e *Encoder) Reset(enc Encoding) {
+	e.enc = enc
+}
+
+// Returns the encoding of the Encoding's current values. The result is only valid until the next
+// call to Encode or Reset, after which it's overwritten.
+func (e *Encoder) Encode() []byte {
+	if e.enc.stats == nil && e.enc.name == "" {
+		return e.encode()
+	}
+	e.enc.instrument(context.Background(), opEncode, false, func(ctx context.Context) (int, error) {
+		return len(e.encode()), nil
+	})
+	return e.buf
+}
+
+func (e *Encoder) encode() []byte {
+	n := e.enc.size()
+	if cap(e.buf) < n {
+		e.buf = make([]byte, n)
+	}
+	e.buf = e.buf[:n]
+	// Items may assume they're encoding into zeroed bytes.
+	clear(e.buf)
+	i := 0
+	for _, item := range e.enc.items {
+		if s, ok := findItem[scratchEncoder](item); ok {
+			size := item.Size()
+			s.encodeScratch(e.buf[i:i+size:i+size], &e.scratch)
+			i += size
+			continue
+		}
+		i += encodeItem(item, e.buf[i:])
+	}
+	return e.buf
+}
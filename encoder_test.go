@@ -0,0 +1,51 @@
+package encode
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	var (
+		a    byte
+		b    byte
+		flag bool
+		u    uint64
+		s    string
+	)
+	r := new(big.Rat)
+	enc := New(
+		Bitpacked(Bits8(&a, 3), Bits8(&b, 5), Bit(&flag), BitPadding(7)),
+		Rat(r),
+		Uvarint64(&u),
+		LengthDelimString(&s),
+	)
+	e := NewEncoder(enc)
+
+	for i := 0; i < 10; i++ {
+		a = byte(i % 8)
+		b = byte(i)
+		flag = i%2 == 0
+		u = uint64(i) << 40
+		s = string(make([]byte, i))
+		r.SetFrac64(int64(-i*1000), 7)
+		require.Equal(t, enc.Encode(), e.Encode())
+	}
+
+	s = ""
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = e.Encode()
+	})
+	require.Zero(t, allocs)
+
+	var other uint16 = 0xBEEF
+	e.Reset(New(FixedUint16(&other)))
+	require.Equal(t, []byte{0xBE, 0xEF}, e.Encode())
+
+	var stats Stats
+	e.Reset(enc.WithStats(&stats))
+	require.Equal(t, enc.Encode(), e.Encode())
+	require.Equal(t, int64(1), stats.Snapshot().Encodes)
+}
@@ -0,0 +1,22 @@
+package encodetest
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/bradenaw/encode"
+)
+
+// Fills the values that enc's items decode into with random valid values, for round-trip and
+// ordering tests.
+//
+// Values are generated by item type using enc.Arbitrary, so they respect the item's widths and
+// lengths. Items that can't generate values, such as Signed or Encrypted, make this return an error
+// wrapping encode.ErrNoArbitrary without changing anything.
+func Arbitrary(enc encode.Encoding, r *rand.Rand) error {
+	err := enc.Arbitrary(r)
+	if err != nil {
+		return fmt.Errorf("encodetest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package encodetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArbitrary(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+
+	var (
+		a    uint16
+		b    bool
+		c    byte
+		d    uint64
+		s    string
+		esc  []byte
+		flag bool
+	)
+	enc := encode.New(
+		encode.FixedUint16(&a),
+		encode.Bool(&b),
+		encode.Bitpacked(encode.Bits8(&c, 3), encode.Bit(&flag), encode.BitPadding(4)),
+		encode.Uvarint64(&d),
+		encode.LengthDelimString(&s),
+		encode.EscapedBytes(&esc),
+	)
+
+	sawBig := false
+	for i := 0; i < 100; i++ {
+		require.NoError(t, Arbitrary(enc, r))
+		require.Less(t, c, byte(8))
+		if d >= 128 {
+			sawBig = true
+		}
+
+		b1 := enc.Encode()
+		require.NoError(t, enc.Decode(b1))
+		require.Equal(t, b1, enc.Encode())
+	}
+	require.True(t, sawBig)
+
+	var v uint64
+	signed := encode.Signed(encode.HMACSHA256([]byte("key")), encode.FixedUint64(&v))
+	v = 5
+	err := Arbitrary(encode.New(encode.FixedUint64(&d), signed), r)
+	require.ErrorIs(t, err, encode.ErrNoArbitrary)
+	require.Equal(t, uint64(5), v)
+}
@@ -0,0 +1,89 @@
+package encodetest
+
+import (
+	"math"
+	"sort"
+)
+
+// Returns values on both sides of every size class boundary of encode.Uvarint32, so that round-trip
+// tests exercise every encoded size.
+func Uvarint32Boundaries() []uint32 {
+	var result []uint32
+	for _, x := range Uvarint64Boundaries() {
+		if x <= math.MaxUint32 {
+			result = append(result, uint32(x))
+		}
+	}
+	return result
+}
+
+// Returns values on both sides of every size class boundary of encode.Uvarint64, so that round-trip
+// tests exercise every encoded size.
+func Uvarint64Boundaries() []uint64 {
+	var edges []uint64
+	for shift := 7; shift < 64; shift += 7 {
+		edges = append(edges, 1<<uint(shift))
+	}
+	edges = append(edges, math.MaxUint32+1)
+	return aroundUint64(edges)
+}
+
+// Returns values on both sides of every size class boundary of encode.OrdUvarint64, so that
+// round-trip tests exercise every encoded size.
+func OrdUvarint64Boundaries() []uint64 {
+	var edges []uint64
+	for shift := 7; shift <= 56; shift += 7 {
+		edges = append(edges, 1<<uint(shift))
+	}
+	edges = append(edges, 1<<63)
+	return aroundUint64(edges)
+}
+
+// Returns values on both sides of every size class boundary of encode.OrdVarint64, so that
+// round-trip tests exercise every encoded size.
+func OrdVarint64Boundaries() []int64 {
+	var edges []int64
+	for shift := 6; shift <= 55; shift += 7 {
+		edges = append(edges, 1<<uint(shift), -(1 << uint(shift)))
+	}
+	edges = append(edges, 0)
+
+	seen := map[int64]struct{}{
+		math.MinInt64:     {},
+		math.MinInt64 + 1: {},
+		math.MaxInt64 - 1: {},
+		math.MaxInt64:     {},
+	}
+	for _, edge := range edges {
+		seen[edge-1] = struct{}{}
+		seen[edge] = struct{}{}
+		seen[edge+1] = struct{}{}
+	}
+	result := make([]int64, 0, len(seen))
+	for x := range seen {
+		result = append(result, x)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// Returns each of edges and the values on either side, plus the extremes, sorted and deduplicated.
+func aroundUint64(edges []uint64) []uint64 {
+	seen := map[uint64]struct{}{
+		0:                  {},
+		1:                  {},
+		math.MaxUint64 - 1: {},
+		math.MaxUint64:     {},
+	}
+	for _, edge := range edges {
+		seen[edge-1] = struct{}{}
+		seen[edge] = struct{}{}
+		seen[edge+1] = struct{}{}
+	}
+	result := make([]uint64, 0, len(seen))
+	for x := range seen {
+		result = append(result, x)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
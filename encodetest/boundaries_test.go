@@ -0,0 +1,58 @@
+package encodetest
+
+import (
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundariesCoverEverySize(t *testing.T) {
+	sizes := map[int]bool{}
+	for _, x := range Uvarint64Boundaries() {
+		y := x
+		enc := encode.New(encode.Uvarint64(&y))
+		b := enc.Encode()
+		y = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x, y)
+		sizes[len(b)] = true
+	}
+	require.Len(t, sizes, 10)
+
+	sizes = map[int]bool{}
+	for _, x := range Uvarint32Boundaries() {
+		y := x
+		enc := encode.New(encode.Uvarint32(&y))
+		b := enc.Encode()
+		y = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x, y)
+		sizes[len(b)] = true
+	}
+	require.Len(t, sizes, 5)
+
+	sizes = map[int]bool{}
+	for _, x := range OrdUvarint64Boundaries() {
+		y := x
+		enc := encode.New(encode.OrdUvarint64(&y))
+		b := enc.Encode()
+		y = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x, y)
+		sizes[len(b)] = true
+	}
+	require.Len(t, sizes, 9)
+
+	sizes = map[int]bool{}
+	for _, x := range OrdVarint64Boundaries() {
+		y := x
+		enc := encode.New(encode.OrdVarint64(&y))
+		b := enc.Encode()
+		y = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x, y)
+		sizes[len(b)] = true
+	}
+	require.Len(t, sizes, 9)
+}
@@ -0,0 +1,61 @@
+package encodetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/bradenaw/encode"
+)
+
+// Returns seed inputs for fuzzing the decoding of enc: n records made by filling enc with Arbitrary
+// and encoding it, and for each of them every truncation and the record with a trailing byte. These
+// are the cases where decoders most often go wrong.
+//
+// The values enc's items point to are overwritten.
+func Corpus(enc encode.Encoding, r *rand.Rand, n int) ([][]byte, error) {
+	var corpus [][]byte
+	seen := make(map[string]struct{})
+	add := func(b []byte) {
+		if _, ok := seen[string(b)]; ok {
+			return
+		}
+		seen[string(b)] = struct{}{}
+		corpus = append(corpus, b)
+	}
+	for i := 0; i < n; i++ {
+		err := Arbitrary(enc, r)
+		if err != nil {
+			return nil, err
+		}
+		b := enc.Encode()
+		for j := 0; j <= len(b); j++ {
+			add(b[:j])
+		}
+		add(append(b[:len(b):len(b)], byte(r.Intn(256))))
+	}
+	return corpus, nil
+}
+
+// Writes inputs as the seed corpus of the fuzz test named fuzzName in the package in dir, that is
+// to dir/testdata/fuzz/fuzzName, in the format used by go test -fuzz. The fuzz test must take a
+// single []byte argument.
+func WriteCorpus(dir string, fuzzName string, inputs [][]byte) error {
+	corpusDir := filepath.Join(dir, "testdata", "fuzz", fuzzName)
+	err := os.MkdirAll(corpusDir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		contents := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", input)
+		sum := sha256.Sum256([]byte(contents))
+		err := os.WriteFile(filepath.Join(corpusDir, hex.EncodeToString(sum[:])[:16]), []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package encodetest
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpus(t *testing.T) {
+	var a uint16
+	var s string
+	enc := encode.New(encode.FixedUint16(&a), encode.LengthDelimString(&s))
+
+	corpus, err := Corpus(enc, rand.New(rand.NewSource(0)), 5)
+	require.NoError(t, err)
+	require.Contains(t, corpus, []byte{})
+	valid := 0
+	for _, b := range corpus {
+		if enc.Decode(b) == nil {
+			valid++
+		}
+	}
+	require.GreaterOrEqual(t, valid, 5)
+	require.Less(t, valid, len(corpus))
+
+	dir := t.TempDir()
+	require.NoError(t, WriteCorpus(dir, "FuzzThing", [][]byte{{0x00, 'a', '"'}}))
+	entries, err := os.ReadDir(filepath.Join(dir, "testdata", "fuzz", "FuzzThing"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	contents, err := os.ReadFile(filepath.Join(dir, "testdata", "fuzz", "FuzzThing", entries[0].Name()))
+	require.NoError(t, err)
+	require.Equal(t, "go test fuzz v1\n[]byte(\"\\x00a\\\"\")\n", string(contents))
+}
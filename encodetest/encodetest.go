@@ -0,0 +1,53 @@
+// Package encodetest provides helpers for testing code that uses package encode.
+package encodetest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bradenaw/encode"
+)
+
+var update = flag.Bool("encodetest.update", false, "rewrite golden files instead of comparing against them")
+
+// Compare enc's encoding to the contents of the file at path, failing t if they differ. This
+// protects data that has already been written somewhere from accidental changes to the byte layout.
+//
+// If the file doesn't exist yet, or the test is run with -encodetest.update, the file is written
+// instead. It should be checked in alongside the test.
+//
+// A mismatch is reported with encode.Encoding.Dump, so the bytes of Sensitive items are redacted.
+func Golden(t testing.TB, enc encode.Encoding, path string) {
+	t.Helper()
+
+	actual := enc.Encode()
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) || *update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatalf("encodetest: creating golden file directory: %s", err)
+		}
+		err = os.WriteFile(path, actual, 0644)
+		if err != nil {
+			t.Fatalf("encodetest: writing golden file: %s", err)
+		}
+		t.Logf("encodetest: wrote golden file %s", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("encodetest: reading golden file: %s", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf(
+			"encodetest: encoding does not match golden file %s\n\nexpected:\n%s\nactual:\n%s\n"+
+				"If this change is intentional, rerun with -encodetest.update. Anything already encoded "+
+				"with the old layout will no longer decode the same way.",
+			path,
+			enc.Dump(expected),
+			enc.Dump(actual),
+		)
+	}
+}
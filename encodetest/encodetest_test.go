@@ -0,0 +1,51 @@
+package encodetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// Records failures instead of failing the real test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "foo.bin")
+
+	a := uint16(0x0102)
+	enc := encode.New(encode.FixedUint16(&a))
+
+	Golden(t, enc, path)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02}, b)
+
+	r := &recordingTB{TB: t}
+	Golden(r, enc, path)
+	require.Empty(t, r.errors)
+
+	a = 0x0103
+	Golden(r, enc, path)
+	require.Len(t, r.errors, 1)
+
+	secret := uint16(0xABCD)
+	sensitive := encode.New(encode.FixedUint16(&a), encode.Sensitive(encode.FixedUint16(&secret)))
+	secretPath := filepath.Join(t.TempDir(), "secret.bin")
+	Golden(t, sensitive, secretPath)
+	secret = 0xDCBA
+	Golden(r, sensitive, secretPath)
+	require.Len(t, r.errors, 2)
+	require.NotContains(t, r.errors[1], "abcd")
+	require.NotContains(t, r.errors[1], "dcba")
+}
@@ -0,0 +1,63 @@
+package encodetest
+
+import "io"
+
+// One call to a method of a FakeItem.
+type Call struct {
+	// "Encode", "Decode", or "Size".
+	Method string
+	// The length of the buffer passed to Encode or Decode, or zero for Size. For Decode, the
+	// item's offset within the record being decoded is the record's length minus this.
+	Len int
+}
+
+// An encode.Item with scripted behavior that records how it's called, for testing code that handles
+// items failing partway through a record. Failures are scripted by call rather than by offset, since
+// an item isn't told where it is within the record being decoded.
+//
+// It encodes as Data, and decodes by checking that there are at least len(Data) bytes and then
+// returning the next error from DecodeErrs.
+type FakeItem struct {
+	// What the item encodes as. Its Size is len(Data).
+	Data []byte
+	// The results of successive calls to Decode. Once these run out, Decode succeeds.
+	DecodeErrs []error
+	// Every call made to the item, in order.
+	Calls []Call
+
+	decodes int
+}
+
+func (f *FakeItem) Encode(buf []byte) {
+	f.Calls = append(f.Calls, Call{Method: "Encode", Len: len(buf)})
+	copy(buf, f.Data)
+}
+func (f *FakeItem) Size() int {
+	f.Calls = append(f.Calls, Call{Method: "Size"})
+	return len(f.Data)
+}
+func (f *FakeItem) Decode(buf []byte) error {
+	f.Calls = append(f.Calls, Call{Method: "Decode", Len: len(buf)})
+	i := f.decodes
+	f.decodes++
+	if len(buf) < len(f.Data) {
+		return io.ErrUnexpectedEOF
+	}
+	if i < len(f.DecodeErrs) {
+		return f.DecodeErrs[i]
+	}
+	return nil
+}
+
+// Returns the offsets within a record of length recordLen that Decode was called at, in order. This
+// is only right when the item is directly in an Encoding that's decoding exactly that record, not
+// when it's nested inside a length-delimited item or followed by trailing data.
+func (f *FakeItem) DecodeOffsets(recordLen int) []int {
+	var offsets []int
+	for _, c := range f.Calls {
+		if c.Method == "Decode" {
+			offsets = append(offsets, recordLen-c.Len)
+		}
+	}
+	return offsets
+}
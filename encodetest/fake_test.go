@@ -0,0 +1,29 @@
+package encodetest
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeItem(t *testing.T) {
+	errBad := errors.New("bad")
+	var a uint16
+	fake := &FakeItem{Data: []byte{0xAA, 0xBB}, DecodeErrs: []error{errBad}}
+	enc := encode.New(encode.FixedUint16(&a), fake)
+
+	b := enc.Encode()
+	require.Equal(t, []byte{0x00, 0x00, 0xAA, 0xBB}, b)
+
+	require.ErrorIs(t, enc.Decode(b), errBad)
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, []int{2, 2}, fake.DecodeOffsets(len(b)))
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(b[:3]))
+
+	var fe *encode.FieldError
+	require.ErrorAs(t, enc.DecodeTolerant(b[:3]), &fe)
+	require.Equal(t, 1, fe.Index)
+}
@@ -0,0 +1,62 @@
+package encodetest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/encode"
+)
+
+// Check that tuple's encoding orders the same way as the values it encodes, for n random pairs of
+// values made by gen. cmp is the intended ordering of the values, returning a negative number if
+// a < b, zero if a == b, and a positive number if a > b. tuple makes a Tuple that encodes from and
+// decodes into v.
+//
+// Also checks that each encoded value decodes back to one that cmp says is equal.
+func Ordering[T any](
+	t testing.TB,
+	r *rand.Rand,
+	n int,
+	gen func(r *rand.Rand) T,
+	tuple func(v *T) encode.Tuple,
+	cmp func(a, b T) int,
+) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		a := gen(r)
+		b := gen(r)
+		aEncoded := tuple(&a).Encode()
+		bEncoded := tuple(&b).Encode()
+
+		expected := sign(cmp(a, b))
+		actual := bytes.Compare(aEncoded, bEncoded)
+		if expected != actual {
+			t.Fatalf(
+				"encodetest: cmp(%v, %v) = %d but encodings %s and %s compare %d",
+				a, b, expected, hex.EncodeToString(aEncoded), hex.EncodeToString(bEncoded), actual,
+			)
+		}
+
+		var decoded T
+		err := tuple(&decoded).Decode(aEncoded)
+		if err != nil {
+			t.Fatalf("encodetest: decoding %v from %s: %s", a, hex.EncodeToString(aEncoded), err)
+		}
+		if cmp(a, decoded) != 0 {
+			t.Fatalf("encodetest: %v encoded as %s but decoded as %v", a, hex.EncodeToString(aEncoded), decoded)
+		}
+	}
+}
+
+func sign(x int) int {
+	if x < 0 {
+		return -1
+	}
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
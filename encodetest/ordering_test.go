@@ -0,0 +1,39 @@
+package encodetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/encode"
+)
+
+type pair struct {
+	a int64
+	b uint16
+}
+
+func TestOrdering(t *testing.T) {
+	Ordering(
+		t,
+		rand.New(rand.NewSource(0)),
+		10000,
+		func(r *rand.Rand) pair {
+			return pair{
+				a: int64(r.Uint64()) >> uint(r.Intn(64)),
+				b: uint16(r.Intn(4)),
+			}
+		},
+		func(v *pair) encode.Tuple {
+			return encode.NewTuple(encode.OrdVarint64(&v.a), encode.FixedUint16(&v.b))
+		},
+		func(x, y pair) int {
+			if x.a != y.a {
+				if x.a < y.a {
+					return -1
+				}
+				return 1
+			}
+			return int(x.b) - int(y.b)
+		},
+	)
+}
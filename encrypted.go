@@ -0,0 +1,149 @@
+package encode
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrUnknownKey   = errors.New("encode: unknown key ID")
+	ErrDecryptField = errors.New("encode: message authentication failed")
+)
+
+// Returns the AEAD for the key with the given ID, or ErrUnknownKey.
+type KeyFunc func(id uint32) (cipher.AEAD, error)
+
+// Encrypt the encoding of item with the key that keys returns for keyID, typically AES-GCM.
+//
+// The key ID is written ahead of the ciphertext and authenticated along with it, and decoding
+// looks the key up again by that ID. Different fields can be protected under different keys, and a
+// key can be rotated by changing the keyID passed here while keys still returns the old key: values
+// encrypted under the old key still decode, and are encrypted under the new one the next time
+// they're encoded.
+//
+// The encoding is a 4-byte big-endian key ID, the uvarint length of the sealed ciphertext, a random
+// nonce, and then the ciphertext. Decoding returns ErrDecryptField if the field was tampered with or
+// encrypted under a different key with the same ID. Encoding panics if keys fails for keyID.
+//
+// A decoded value may have been encrypted under a key whose AEAD has a different nonce size or
+// overhead than keyID's, so Encrypted remembers the length it decoded, and must not be used
+// concurrently.
+func Encrypted(item Item, keyID uint32, keys KeyFunc) Item {
+	return encrypted{item: item, keyID: keyID, keys: keys, decoded: new(int)}
+}
+
+// Like Encrypted, but the nonce is derived from the plaintext with HMAC-SHA256 under nonceKey
+// instead of chosen randomly, in the style of SIV modes. Equal values encrypted under the same key ID
+// produce equal ciphertexts, so the encrypted field can be used for equality lookups, for example as
+// part of an index key.
+//
+// This reveals to anyone who can see the encoded values which of them are equal, which is
+// exactly the property being asked for, so use Encrypted unless it's needed. Since a nonce only
+// repeats when the plaintext does, it doesn't have the catastrophic failure of reusing a random
+// nonce. nonceKey should be a secret of at least 32 bytes, independent of the encryption keys.
+//
+// The encoding is the same as Encrypted's, so the two decode each other's output.
+func DeterministicEncrypted(item Item, keyID uint32, keys KeyFunc, nonceKey []byte) Item {
+	return encrypted{item: item, keyID: keyID, keys: keys, nonceKey: nonceKey, decoded: new(int)}
+}
+
+type encrypted struct {
+	item  Item
+	keyID uint32
+	keys  KeyFunc
+	// If non-nil, used to derive the nonce instead of choosing one randomly.
+	nonceKey []byte
+	// The length of the envelope last decoded, or -1 if the last decode failed.
+	decoded *int
+}
+
+func (e encrypted) aead(id uint32) cipher.AEAD {
+	aead, err := e.keys(id)
+	if err != nil {
+		panic(fmt.Sprintf("encode: getting key %d: %s", id, err))
+	}
+	return aead
+}
+func (e encrypted) Encode(buf []byte) {
+	aead := e.aead(e.keyID)
+	plaintext := make([]byte, e.item.Size())
+	e.item.Encode(plaintext)
+	var nonce []byte
+	if e.nonceKey != nil {
+		mac := hmac.New(sha256.New, e.nonceKey)
+		var id [4]byte
+		binary.BigEndian.PutUint32(id[:], e.keyID)
+		mac.Write(id[:])
+		mac.Write(plaintext)
+		nonce = mac.Sum(nil)[:aead.NonceSize()]
+	} else {
+		nonce = make([]byte, aead.NonceSize())
+		_, err := io.ReadFull(rand.Reader, nonce)
+		if err != nil {
+			panic(err)
+		}
+	}
+	e.seal(buf, aead, nonce, plaintext)
+}
+func (e encrypted) seal(buf []byte, aead cipher.AEAD, nonce []byte, plaintext []byte) {
+	binary.BigEndian.PutUint32(buf, e.keyID)
+	header := buf[:4]
+	n := 4 + binary.PutUvarint(buf[4:], uint64(len(plaintext)+aead.Overhead()))
+	n += copy(buf[n:], nonce)
+	aead.Seal(buf[n:n], nonce, plaintext, header)
+}
+func (e encrypted) Size() int {
+	aead := e.aead(e.keyID)
+	l := e.item.Size() + aead.Overhead()
+	return 4 + uvarintSize(uint64(l)) + aead.NonceSize() + l
+}
+func (e encrypted) decodedSize() int {
+	if *e.decoded < 0 {
+		return e.Size()
+	}
+	return *e.decoded
+}
+func (e encrypted) Decode(buf []byte) error {
+	*e.decoded = -1
+	if len(buf) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	header := buf[:4]
+	aead, err := e.keys(binary.BigEndian.Uint32(header))
+	if err != nil {
+		return err
+	}
+	l, n := binary.Uvarint(buf[4:])
+	if n == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return ErrOverflowVarint
+	}
+	if n != uvarintSize(l) {
+		// The envelope should be written the way Encode writes it.
+		return ErrNonCanonical
+	}
+	n += 4
+	if len(buf) < n+aead.NonceSize() || uint64(len(buf)-n-aead.NonceSize()) < l {
+		return io.ErrUnexpectedEOF
+	}
+	nonce := buf[n : n+aead.NonceSize()]
+	n += aead.NonceSize()
+	plaintext, err := aead.Open(nil, nonce, buf[n:n+int(l)], header)
+	if err != nil {
+		return ErrDecryptField
+	}
+	err = e.item.Decode(plaintext)
+	if err != nil {
+		return err
+	}
+	*e.decoded = n + int(l)
+	return nil
+}
@@ -0,0 +1,144 @@
+package encode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKeys(t *testing.T, ids ...uint32) KeyFunc {
+	aeads := make(map[uint32]cipher.AEAD)
+	for _, id := range ids {
+		block, err := aes.NewCipher(bytes.Repeat([]byte{byte(id)}, 32))
+		require.NoError(t, err)
+		aead, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+		aeads[id] = aead
+	}
+	return func(id uint32) (cipher.AEAD, error) {
+		aead, ok := aeads[id]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return aead, nil
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	keys := testKeys(t, 1, 2)
+
+	var id uint64
+	var ssn string
+	var email string
+	enc := New(
+		Uvarint64(&id),
+		Encrypted(LengthDelimString(&ssn), 1, keys),
+		Encrypted(LengthDelimString(&email), 2, keys),
+	)
+
+	id, ssn, email = 7, "078-05-1120", "someone@example.com"
+	b := enc.Encode()
+	require.NotContains(t, string(b), ssn)
+	require.NotContains(t, string(b), email)
+	require.Equal(t, []byte{0, 0, 0, 1}, b[1:5])
+
+	// Random nonces, so the same values encrypt differently each time.
+	require.NotEqual(t, b, enc.Encode())
+
+	id, ssn, email = 0, "", ""
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, uint64(7), id)
+	require.Equal(t, "078-05-1120", ssn)
+	require.Equal(t, "someone@example.com", email)
+
+	// Rotate the first field to key 3. Old values still decode while key 1 is around, and re-encode
+	// under the new key.
+	keys = testKeys(t, 1, 2, 3)
+	rotated := New(
+		Uvarint64(&id),
+		Encrypted(LengthDelimString(&ssn), 3, keys),
+		Encrypted(LengthDelimString(&email), 2, keys),
+	)
+	require.NoError(t, rotated.Decode(b))
+	require.Equal(t, "078-05-1120", ssn)
+	b2 := rotated.Encode()
+	require.Equal(t, []byte{0, 0, 0, 3}, b2[1:5])
+
+	err := New(Uvarint64(&id), Encrypted(LengthDelimString(&ssn), 3, testKeys(t, 3))).Decode(b)
+	require.Equal(t, ErrUnknownKey, err)
+
+	for i := 1; i < len(b); i++ {
+		tampered := append([]byte(nil), b...)
+		tampered[i] ^= 0x01
+		require.Error(t, enc.Decode(tampered))
+	}
+	for i := 0; i < len(b); i++ {
+		require.Error(t, enc.Decode(b[:i]))
+	}
+
+	// Rotate to a key whose AEAD has a longer nonce. Items after the field are still found from the
+	// length actually decoded, and re-encoding uses the new key.
+	block, err := aes.NewCipher(bytes.Repeat([]byte{4}, 32))
+	require.NoError(t, err)
+	longNonce, err := cipher.NewGCMWithNonceSize(block, 16)
+	require.NoError(t, err)
+	oldKeys := keys
+	keys = func(id uint32) (cipher.AEAD, error) {
+		if id == 4 {
+			return longNonce, nil
+		}
+		return oldKeys(id)
+	}
+	var after byte
+	ssn, after = "078-05-1120", 0x42
+	old := New(Encrypted(LengthDelimString(&ssn), 1, keys), Byte(&after)).Encode()
+	enc = New(Encrypted(LengthDelimString(&ssn), 4, keys), Byte(&after))
+	ssn, after = "", 0
+	require.NoError(t, enc.Decode(old))
+	require.Equal(t, "078-05-1120", ssn)
+	require.Equal(t, byte(0x42), after)
+	b = enc.Encode()
+	require.Equal(t, []byte{0, 0, 0, 4}, b[:4])
+	require.Len(t, b, len(old)+4)
+	ssn, after = "", 0
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, "078-05-1120", ssn)
+	require.Equal(t, byte(0x42), after)
+}
+
+func TestDeterministicEncrypted(t *testing.T) {
+	keys := testKeys(t, 1, 2)
+	nonceKey := bytes.Repeat([]byte{0xAB}, 32)
+
+	var email string
+	enc := New(DeterministicEncrypted(LengthDelimString(&email), 1, keys, nonceKey))
+
+	email = "someone@example.com"
+	b := enc.Encode()
+	require.NotContains(t, string(b), email)
+	require.Equal(t, b, enc.Encode())
+
+	email = "someone.else@example.com"
+	require.NotEqual(t, b, enc.Encode())
+
+	// Different key ID or nonce key gives a different ciphertext.
+	email = "someone@example.com"
+	require.NotEqual(
+		t,
+		b,
+		New(DeterministicEncrypted(LengthDelimString(&email), 2, keys, nonceKey)).Encode(),
+	)
+	require.NotEqual(
+		t,
+		b,
+		New(DeterministicEncrypted(LengthDelimString(&email), 1, keys, []byte("other"))).Encode(),
+	)
+
+	// Interchangeable with Encrypted.
+	email = ""
+	require.NoError(t, New(Encrypted(LengthDelimString(&email), 1, keys)).Decode(b))
+	require.Equal(t, "someone@example.com", email)
+}
@@ -0,0 +1,86 @@
+package encode
+
+import (
+	"fmt"
+	"io"
+)
+
+// Returned by Encodings configured with WithDetailedErrors when an item runs past the end of the
+// buffer. errors.Is(err, io.ErrUnexpectedEOF) is still true.
+type TruncatedError struct {
+	// The index of the item within the Encoding.
+	Index int
+	// The offset in the buffer that the item started at.
+	Offset int
+	// The number of bytes that were left in the buffer from Offset.
+	Remaining int
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf(
+		"encode: item %d at offset %d: truncated, only %d bytes remaining",
+		e.Index, e.Offset, e.Remaining,
+	)
+}
+func (e *TruncatedError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
+// Returned by Encodings configured with WithDetailedErrors when an item fails to decode for a
+// reason other than running out of bytes. Err is the item's own error, such as ErrInvalidBool, and
+// errors.Is and errors.As see through to it. Error redacts Err if the item is Sensitive, since an
+// item's error may quote what it was decoding.
+type InvalidError struct {
+	// The index of the item within the Encoding.
+	Index int
+	// The offset in the buffer that the item started at.
+	Offset int
+	Err    error
+
+	sensitive bool
+}
+
+func (e *InvalidError) Error() string {
+	return fmt.Sprintf(
+		"encode: item %d at offset %d: %s",
+		e.Index, e.Offset, errorText(e.Err, e.sensitive),
+	)
+}
+func (e *InvalidError) Unwrap() error {
+	return e.Err
+}
+
+// Returns a copy of enc whose Decode and DecodeCtx report which item failed and where, by
+// returning a *TruncatedError or *InvalidError instead of the item's error directly. Use errors.Is
+// to check for a particular error, such as io.ErrUnexpectedEOF or ErrInvalidBool, and errors.As to
+// get the details.
+//
+// Errors from WithPreDecode and WithPostDecode hooks are returned as-is.
+func (enc Encoding) WithDetailedErrors() Encoding {
+	enc.detailedErrors = true
+	return enc
+}
+
+// Returns err, from item index starting at offset, as returned from enc's Decode.
+func (enc Encoding) itemError(index int, offset int, buf []byte, err error) error {
+	if !enc.detailedErrors {
+		return err
+	}
+	if err == io.ErrUnexpectedEOF {
+		return &TruncatedError{Index: index, Offset: offset, Remaining: len(buf) - offset}
+	}
+	return &InvalidError{
+		Index:     index,
+		Offset:    offset,
+		Err:       err,
+		sensitive: isSensitive(enc.items[index]),
+	}
+}
+
+// Returns the text of err for an item's error, or redacted if the item is Sensitive.
+func errorText(err error, sensitive bool) string {
+	if sensitive {
+		return redacted
+	}
+	return err.Error()
+}
@@ -0,0 +1,40 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetailedErrors(t *testing.T) {
+	var a uint16
+	var b bool
+	var c uint32
+	enc := New(FixedUint16(&a), Bool(&b), FixedUint32(&c))
+
+	// Unchanged without WithDetailedErrors.
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode([]byte{0, 1, 1, 0}))
+
+	enc = enc.WithDetailedErrors()
+	err := enc.Decode([]byte{0, 1, 1, 0})
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	var truncated *TruncatedError
+	require.True(t, errors.As(err, &truncated))
+	require.Equal(t, &TruncatedError{Index: 2, Offset: 3, Remaining: 1}, truncated)
+	require.Equal(t, "encode: item 2 at offset 3: truncated, only 1 bytes remaining", err.Error())
+
+	err = enc.Decode([]byte{0, 1, 2, 0, 0, 0, 0})
+	require.True(t, errors.Is(err, ErrInvalidBool))
+	var invalid *InvalidError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, &InvalidError{Index: 1, Offset: 2, Err: ErrInvalidBool}, invalid)
+
+	require.NoError(t, enc.Decode([]byte{0, 1, 1, 0, 0, 0, 2}))
+
+	// Hook errors pass through untouched.
+	hookErr := errors.New("hook")
+	err = enc.WithPostDecode(func() error { return hookErr }).Decode([]byte{0, 1, 1, 0, 0, 0, 2})
+	require.Equal(t, hookErr, err)
+}
@@ -0,0 +1,42 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// The size of the suffix added by WithExpiry.
+const expirySize = 8
+
+// Returns key with expiry appended in 8 bytes, in nanoseconds since the Unix epoch in big endian with
+// the sign bit flipped, so that versions of the same key sort by when they expire. For them to sort
+// together without interleaving with other keys, no key may be a prefix of another.
+//
+// See ExpiredRange for scanning for expired keys, and SplitExpiry for reading the expiry back.
+func WithExpiry(key []byte, expiry time.Time) []byte {
+	out := make([]byte, len(key)+expirySize)
+	copy(out, key)
+	binary.BigEndian.PutUint64(out[len(key):], uint64(expiry.UnixNano())^(1<<63))
+	return out
+}
+
+// Splits a key made by WithExpiry into the original key and its expiry.
+func SplitExpiry(k []byte) ([]byte, time.Time, error) {
+	if len(k) < expirySize {
+		return nil, time.Time{}, io.ErrUnexpectedEOF
+	}
+	i := len(k) - expirySize
+	ns := int64(binary.BigEndian.Uint64(k[i:]) ^ (1 << 63))
+	return k[:i], time.Unix(0, ns), nil
+}
+
+// Returns the range [start, end) containing the keys made by WithExpiry(key, expiry) for every expiry
+// before the given time. Deleting the range removes everything under key that expired before then.
+func ExpiredRange(key []byte, before time.Time) (start []byte, end []byte) {
+	start = make([]byte, len(key)+expirySize)
+	copy(start, key)
+	// The lowest possible expiry encodes as all zeroes, which make already does.
+	end = WithExpiry(key, before)
+	return start, end
+}
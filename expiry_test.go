@@ -0,0 +1,38 @@
+package encode
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiry(t *testing.T) {
+	key := []byte("session\x00")
+	now := time.Unix(1700000000, 0)
+
+	k1 := WithExpiry(key, now.Add(-time.Hour))
+	k2 := WithExpiry(key, now.Add(-time.Second))
+	k3 := WithExpiry(key, now)
+	k4 := WithExpiry(key, now.Add(time.Hour))
+	require.True(t, bytes.Compare(k1, k2) < 0)
+	require.True(t, bytes.Compare(k2, k3) < 0)
+	require.True(t, bytes.Compare(k3, k4) < 0)
+
+	start, end := ExpiredRange(key, now)
+	inRange := func(k []byte) bool { return bytes.Compare(start, k) <= 0 && bytes.Compare(k, end) < 0 }
+	require.True(t, inRange(k1))
+	require.True(t, inRange(k2))
+	require.False(t, inRange(k3))
+	require.False(t, inRange(k4))
+	require.True(t, inRange(WithExpiry(key, time.Unix(-1, 0))))
+
+	base, expiry, err := SplitExpiry(k2)
+	require.NoError(t, err)
+	require.Equal(t, key, base)
+	require.True(t, expiry.Equal(now.Add(-time.Second)))
+
+	_, _, err = SplitExpiry([]byte{1, 2})
+	require.Error(t, err)
+}
@@ -0,0 +1,433 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrNaN = errors.New("encode: NaN not allowed")
+	ErrInf = errors.New("encode: infinity not allowed")
+)
+
+// Encode v as an IEEE 754 half-precision float in big endian order, taking 2 bytes. v is rounded to
+// the nearest representable value, ties to even. Values too large for half precision become
+// infinities, and values too small become zeroes.
+func Float16(v *float32) Item {
+	return float16{v}
+}
+
+type float16 struct{ v *float32 }
+
+func (e float16) Encode(buf []byte) {
+	binary.BigEndian.PutUint16(buf, float32ToFloat16(*e.v))
+}
+func (e float16) Size() int {
+	return 2
+}
+func (e float16) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = float16ToFloat32(binary.BigEndian.Uint16(buf))
+	return nil
+}
+
+// Encode v as a bfloat16 in big endian order, taking 2 bytes. bfloat16 is the high-order half of a
+// float32, so it has the same range but only 8 bits of precision. v is rounded to the nearest
+// representable value, ties to even.
+func BFloat16(v *float32) Item {
+	return bfloat16{v}
+}
+
+type bfloat16 struct{ v *float32 }
+
+func (e bfloat16) Encode(buf []byte) {
+	binary.BigEndian.PutUint16(buf, float32ToBFloat16(*e.v))
+}
+func (e bfloat16) Size() int {
+	return 2
+}
+func (e bfloat16) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = math.Float32frombits(uint32(binary.BigEndian.Uint16(buf)) << 16)
+	return nil
+}
+
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xFF
+	mant := bits & 0x7FFFFF
+
+	if exp == 0xFF {
+		if mant == 0 {
+			return sign | 0x7C00
+		}
+		// Keep the high bits of the payload, and make sure it's still a NaN.
+		return sign | 0x7E00 | uint16(mant>>13)
+	}
+
+	// Re-bias the exponent from float32's 127 to float16's 15.
+	e := exp - 127 + 15
+	if e >= 0x1F {
+		return sign | 0x7C00
+	}
+
+	var shift uint
+	if e <= 0 {
+		// Subnormal in float16, so shift the implicit leading one into the mantissa.
+		shift = uint(14 - e)
+		if shift > 24 {
+			return sign
+		}
+		mant |= 0x800000
+		e = 0
+	} else {
+		shift = 13
+	}
+
+	halfMant := mant >> shift
+	rem := mant & ((1 << shift) - 1)
+	halfway := uint32(1) << (shift - 1)
+	if rem > halfway || (rem == halfway && halfMant&1 == 1) {
+		// Carrying out of the mantissa correctly bumps the exponent, up to infinity.
+		halfMant++
+	}
+	return sign | (uint16(e)<<10 + uint16(halfMant))
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1F
+	mant := uint32(h & 0x3FF)
+
+	switch exp {
+	case 0x1F:
+		return math.Float32frombits(sign | 0x7F800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Subnormal in float16, but normal in float32.
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (mant&0x3FF)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+func float32ToBFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	if f != f {
+		// Make sure truncating the payload doesn't turn it into an infinity.
+		return uint16(bits>>16) | 0x0040
+	}
+	bits += 0x7FFF + (bits>>16)&1
+	return uint16(bits >> 16)
+}
+
+// Encode v as its real part followed by its imaginary part, each an IEEE 754 single-precision float
+// in big endian order, taking 8 bytes.
+func Complex64(v *complex64) Item {
+	return encComplex64{v}
+}
+
+type encComplex64 struct{ v *complex64 }
+
+func (e encComplex64) Encode(buf []byte) {
+	binary.BigEndian.PutUint32(buf, math.Float32bits(real(*e.v)))
+	binary.BigEndian.PutUint32(buf[4:], math.Float32bits(imag(*e.v)))
+}
+func (e encComplex64) Size() int {
+	return 8
+}
+func (e encComplex64) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = complex(
+		math.Float32frombits(binary.BigEndian.Uint32(buf)),
+		math.Float32frombits(binary.BigEndian.Uint32(buf[4:])),
+	)
+	return nil
+}
+
+// Encode v as its real part followed by its imaginary part, each an IEEE 754 double-precision float
+// in big endian order, taking 16 bytes.
+func Complex128(v *complex128) Item {
+	return encComplex128{v}
+}
+
+type encComplex128 struct{ v *complex128 }
+
+func (e encComplex128) Encode(buf []byte) {
+	binary.BigEndian.PutUint64(buf, math.Float64bits(real(*e.v)))
+	binary.BigEndian.PutUint64(buf[8:], math.Float64bits(imag(*e.v)))
+}
+func (e encComplex128) Size() int {
+	return 16
+}
+func (e encComplex128) Decode(buf []byte) error {
+	if len(buf) < 16 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = complex(
+		math.Float64frombits(binary.BigEndian.Uint64(buf)),
+		math.Float64frombits(binary.BigEndian.Uint64(buf[8:])),
+	)
+	return nil
+}
+
+// Where OrdFloat32 and OrdFloat64 place NaNs relative to other values.
+type NaNOrder int
+
+const (
+	// Every NaN encodes the same way, after +Inf, and decodes as a positive quiet NaN.
+	NaNLast NaNOrder = iota
+	// Every NaN encodes the same way, before -Inf, and decodes as a negative quiet NaN.
+	NaNFirst
+	// NaNs keep their sign and payload and order as in IEEE 754's totalOrder: negative NaNs before
+	// -Inf, and positive NaNs after +Inf.
+	NaNTotalOrder
+)
+
+// Encode v so that encodings lexicographically order the same as the values, taking 8 bytes. -0
+// orders before +0, and NaNs are placed according to nans.
+//
+// This flips the sign bit of non-negative numbers and every bit of negative ones, which sorts the
+// bits of an IEEE 754 double-precision float as in its totalOrder.
+func OrdFloat64(v *float64, nans NaNOrder) TupleItem {
+	return ordFloat64{v: v, nans: nans}
+}
+
+type ordFloat64 struct {
+	v    *float64
+	nans NaNOrder
+}
+
+func (e ordFloat64) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ordFloat64) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ordFloat64) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordFloat64) OrderPreserving()                        {}
+func (e ordFloat64) Encode(buf []byte) {
+	var x uint64
+	switch {
+	case e.nans == NaNLast && math.IsNaN(*e.v):
+		x = math.MaxUint64
+	case e.nans == NaNFirst && math.IsNaN(*e.v):
+		x = 0
+	default:
+		x = math.Float64bits(*e.v)
+		if x>>63 == 1 {
+			x = ^x
+		} else {
+			x |= 1 << 63
+		}
+	}
+	binary.BigEndian.PutUint64(buf, x)
+}
+func (e ordFloat64) Size() int {
+	return 8
+}
+func (e ordFloat64) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	x := binary.BigEndian.Uint64(buf)
+	if x>>63 == 1 {
+		x &^= 1 << 63
+	} else {
+		x = ^x
+	}
+	*e.v = math.Float64frombits(x)
+	return nil
+}
+
+// Encode v so that encodings lexicographically order the same as the values, taking 4 bytes. This is
+// the single-precision equivalent of OrdFloat64.
+func OrdFloat32(v *float32, nans NaNOrder) TupleItem {
+	return ordFloat32{v: v, nans: nans}
+}
+
+type ordFloat32 struct {
+	v    *float32
+	nans NaNOrder
+}
+
+func (e ordFloat32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ordFloat32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ordFloat32) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordFloat32) OrderPreserving()                        {}
+func (e ordFloat32) Encode(buf []byte) {
+	var x uint32
+	switch {
+	case e.nans == NaNLast && math.IsNaN(float64(*e.v)):
+		x = math.MaxUint32
+	case e.nans == NaNFirst && math.IsNaN(float64(*e.v)):
+		x = 0
+	default:
+		x = math.Float32bits(*e.v)
+		if x>>31 == 1 {
+			x = ^x
+		} else {
+			x |= 1 << 31
+		}
+	}
+	binary.BigEndian.PutUint32(buf, x)
+}
+func (e ordFloat32) Size() int {
+	return 4
+}
+func (e ordFloat32) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	x := binary.BigEndian.Uint32(buf)
+	if x>>31 == 1 {
+		x &^= 1 << 31
+	} else {
+		x = ^x
+	}
+	*e.v = math.Float32frombits(x)
+	return nil
+}
+
+// What CheckFloats does with non-finite values after decoding. Policies can be combined with |.
+type FloatPolicy int
+
+const (
+	// Decoding a NaN returns ErrNaN.
+	RejectNaN FloatPolicy = 1 << iota
+	// Decoding +Inf or -Inf returns ErrInf.
+	RejectInf
+	// Every NaN decodes as math.NaN(), so that payloads and signs from the encoder don't leak
+	// through to code that compares bits.
+	CanonicalNaN
+)
+
+// Returns a Middleware that applies policy to the values decoded by a float item, so that non-finite
+// values can be stopped at decoding rather than reaching code that can't handle them. Encoding is
+// unaffected.
+//
+// The wrapped item must be one of Float16, BFloat16, Complex64, Complex128, OrdFloat32, or
+// OrdFloat64, and the Middleware panics otherwise. If it's a TupleItem, so is the result, and it can
+// be used in Tuples through NewTupleChecked.
+func CheckFloats(policy FloatPolicy) Middleware {
+	return func(item Item) Item {
+		var check func() error
+		switch e := item.(type) {
+		case float16:
+			check = func() error { return checkFloat32(e.v, policy) }
+		case bfloat16:
+			check = func() error { return checkFloat32(e.v, policy) }
+		case ordFloat32:
+			check = func() error { return checkFloat32(e.v, policy) }
+		case ordFloat64:
+			check = func() error { return checkFloat64(e.v, policy) }
+		case encComplex64:
+			check = func() error {
+				re, im := real(*e.v), imag(*e.v)
+				err := checkFloat32(&re, policy)
+				if err == nil {
+					err = checkFloat32(&im, policy)
+				}
+				*e.v = complex(re, im)
+				return err
+			}
+		case encComplex128:
+			check = func() error {
+				re, im := real(*e.v), imag(*e.v)
+				err := checkFloat64(&re, policy)
+				if err == nil {
+					err = checkFloat64(&im, policy)
+				}
+				*e.v = complex(re, im)
+				return err
+			}
+		default:
+			panic(fmt.Sprintf("encode: CheckFloats can't wrap %T", item))
+		}
+		c := checkedFloat{item: item, check: check}
+		if tupleItem, ok := item.(TupleItem); ok {
+			return checkedFloatTuple{checkedFloat: c, tupleItem: tupleItem}
+		}
+		return c
+	}
+}
+
+func checkFloat32(v *float32, policy FloatPolicy) error {
+	err := floatPolicyError(float64(*v), policy)
+	if err == nil && policy&CanonicalNaN != 0 && math.IsNaN(float64(*v)) {
+		*v = float32(math.NaN())
+	}
+	return err
+}
+
+func checkFloat64(v *float64, policy FloatPolicy) error {
+	err := floatPolicyError(*v, policy)
+	if err == nil && policy&CanonicalNaN != 0 && math.IsNaN(*v) {
+		*v = math.NaN()
+	}
+	return err
+}
+
+func floatPolicyError(f float64, policy FloatPolicy) error {
+	if policy&RejectNaN != 0 && math.IsNaN(f) {
+		return ErrNaN
+	}
+	if policy&RejectInf != 0 && math.IsInf(f, 0) {
+		return ErrInf
+	}
+	return nil
+}
+
+type checkedFloat struct {
+	item  Item
+	check func() error
+}
+
+func (e checkedFloat) Encode(buf []byte) {
+	e.item.Encode(buf)
+}
+func (e checkedFloat) Size() int {
+	return e.item.Size()
+}
+func (e checkedFloat) unwrap() Item {
+	return e.item
+}
+func (e checkedFloat) Decode(buf []byte) error {
+	err := e.item.Decode(buf)
+	if err != nil {
+		return err
+	}
+	return e.check()
+}
+
+type checkedFloatTuple struct {
+	checkedFloat
+	tupleItem TupleItem
+}
+
+func (e checkedFloatTuple) OrderPreserving() {}
+func (e checkedFloatTuple) EncodeTuple(buf []byte, last bool) {
+	e.tupleItem.EncodeTuple(buf, last)
+}
+func (e checkedFloatTuple) SizeTuple(last bool) int {
+	return e.tupleItem.SizeTuple(last)
+}
+func (e checkedFloatTuple) DecodeTuple(buf []byte, last bool) error {
+	err := e.tupleItem.DecodeTuple(buf, last)
+	if err != nil {
+		return err
+	}
+	return e.check()
+}
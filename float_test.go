@@ -0,0 +1,200 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat16(t *testing.T) {
+	check := func(f float32, expected uint16) {
+		b := New(Float16(&f)).Encode()
+		require.Equal(t, expected, binary.BigEndian.Uint16(b), "%v", f)
+	}
+
+	check(0, 0x0000)
+	check(float32(math.Copysign(0, -1)), 0x8000)
+	check(1, 0x3C00)
+	check(-2, 0xC000)
+	check(0.1, 0x2E66)
+	check(65504, 0x7BFF)
+	check(65519, 0x7BFF)
+	check(65520, 0x7C00)
+	check(float32(math.Inf(-1)), 0xFC00)
+	check(float32(math.Pow(2, -24)), 0x0001)
+	check(float32(math.Pow(2, -25)), 0x0000)
+	check(float32(math.Pow(2, -25)*1.5), 0x0001)
+	check(float32(math.Pow(2, -14)), 0x0400)
+	// Halfway between 1 and the next half, ties to even.
+	check(1+float32(math.Pow(2, -11)), 0x3C00)
+	check(1+3*float32(math.Pow(2, -11)), 0x3C02)
+
+	// Every float16 survives a round trip through float32.
+	for h := 0; h <= 0xFFFF; h++ {
+		var f float32
+		enc := New(Float16(&f))
+		b := []byte{byte(h >> 8), byte(h)}
+		require.NoError(t, enc.Decode(b))
+		if f != f {
+			require.True(t, h&0x7C00 == 0x7C00 && h&0x3FF != 0)
+			continue
+		}
+		require.Equal(t, b, enc.Encode(), "%04x %v", h, f)
+	}
+}
+
+func TestBFloat16(t *testing.T) {
+	check := func(f float32, expected uint16) {
+		b := New(BFloat16(&f)).Encode()
+		require.Equal(t, expected, binary.BigEndian.Uint16(b), "%v", f)
+		var f2 float32
+		require.NoError(t, New(BFloat16(&f2)).Decode(b))
+		require.Equal(t, math.Float32frombits(uint32(expected)<<16), f2)
+	}
+
+	check(1, 0x3F80)
+	check(math.Pi, 0x4049)
+	check(-math.E, 0xC02E)
+	check(float32(math.Inf(1)), 0x7F80)
+	check(math.MaxFloat32, 0x7F80)
+	check(math.Float32frombits(0x3F808000), 0x3F80)
+	check(math.Float32frombits(0x3F818000), 0x3F82)
+
+	nan := math.Float32frombits(0x7F800001)
+	b := New(BFloat16(&nan)).Encode()
+	var f float32
+	require.NoError(t, New(BFloat16(&f)).Decode(b))
+	require.True(t, f != f)
+}
+
+func TestComplex(t *testing.T) {
+	c64 := complex64(complex(1.5, -2))
+	c128 := complex(math.Pi, math.Inf(1))
+	enc := New(Complex64(&c64), Complex128(&c128))
+	b := enc.Encode()
+	require.Equal(t, []byte{
+		0x3F, 0xC0, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00,
+		0x40, 0x09, 0x21, 0xFB, 0x54, 0x44, 0x2D, 0x18, 0x7F, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}, b)
+
+	c64, c128 = 0, 0
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, complex64(complex(1.5, -2)), c64)
+	require.Equal(t, complex(math.Pi, math.Inf(1)), c128)
+}
+
+func TestOrdFloat64(t *testing.T) {
+	negNaN := math.Float64frombits(0xFFF8000000000001)
+	posNaN := math.Float64frombits(0x7FF8000000000001)
+	ordered := []float64{
+		math.Inf(-1),
+		-math.MaxFloat64,
+		-1,
+		-math.SmallestNonzeroFloat64,
+		math.Copysign(0, -1),
+		0,
+		math.SmallestNonzeroFloat64,
+		1,
+		math.MaxFloat64,
+		math.Inf(1),
+	}
+
+	encode := func(f float64, nans NaNOrder) []byte {
+		return NewTuple(OrdFloat64(&f, nans)).Encode()
+	}
+	for _, nans := range []NaNOrder{NaNLast, NaNFirst, NaNTotalOrder} {
+		for i := range ordered {
+			b := encode(ordered[i], nans)
+			var f float64
+			require.NoError(t, NewTuple(OrdFloat64(&f, nans)).Decode(b))
+			require.Equal(t, math.Float64bits(ordered[i]), math.Float64bits(f))
+			if i > 0 {
+				require.Less(t, string(encode(ordered[i-1], nans)), string(b))
+			}
+		}
+	}
+
+	require.Less(t, string(encode(math.Inf(1), NaNLast)), string(encode(negNaN, NaNLast)))
+	require.Equal(t, encode(negNaN, NaNLast), encode(posNaN, NaNLast))
+	require.Less(t, string(encode(posNaN, NaNFirst)), string(encode(math.Inf(-1), NaNFirst)))
+	require.Equal(t, encode(negNaN, NaNFirst), encode(posNaN, NaNFirst))
+	require.Less(t, string(encode(negNaN, NaNTotalOrder)), string(encode(math.Inf(-1), NaNTotalOrder)))
+	require.Less(t, string(encode(math.Inf(1), NaNTotalOrder)), string(encode(posNaN, NaNTotalOrder)))
+
+	for _, nans := range []NaNOrder{NaNLast, NaNFirst, NaNTotalOrder} {
+		var f float64
+		require.NoError(t, New(OrdFloat64(&f, nans)).Decode(encode(negNaN, nans)))
+		require.True(t, math.IsNaN(f))
+	}
+	var f float64
+	require.NoError(t, New(OrdFloat64(&f, NaNTotalOrder)).Decode(encode(negNaN, NaNTotalOrder)))
+	require.Equal(t, math.Float64bits(negNaN), math.Float64bits(f))
+}
+
+func TestOrdFloat32(t *testing.T) {
+	ordered := []float32{
+		float32(math.Inf(-1)),
+		-math.MaxFloat32,
+		-1,
+		float32(math.Copysign(0, -1)),
+		0,
+		math.SmallestNonzeroFloat32,
+		1,
+		float32(math.Inf(1)),
+		float32(math.NaN()),
+	}
+	var prev []byte
+	for _, x := range ordered {
+		f := x
+		b := NewTuple(OrdFloat32(&f, NaNLast)).Encode()
+		require.Len(t, b, 4)
+		require.Less(t, string(prev), string(b))
+		prev = b
+
+		var out float32
+		require.NoError(t, NewTuple(OrdFloat32(&out, NaNLast)).Decode(b))
+		if x == x {
+			require.Equal(t, math.Float32bits(x), math.Float32bits(out))
+		} else {
+			require.True(t, out != out)
+		}
+	}
+}
+
+func TestCheckFloats(t *testing.T) {
+	var f float64
+	f = math.Float64frombits(0xFFF8000000000123)
+	nan := New(OrdFloat64(&f, NaNTotalOrder)).Encode()
+	f = math.Inf(-1)
+	inf := New(OrdFloat64(&f, NaNTotalOrder)).Encode()
+	f = 1.5
+	finite := New(OrdFloat64(&f, NaNTotalOrder)).Encode()
+
+	decode := func(b []byte, policy FloatPolicy) error {
+		item := Chain(OrdFloat64(&f, NaNTotalOrder), CheckFloats(policy))
+		tup, err := NewTupleChecked(item)
+		require.NoError(t, err)
+		return tup.Decode(b)
+	}
+	require.Equal(t, ErrNaN, decode(nan, RejectNaN))
+	require.NoError(t, decode(inf, RejectNaN))
+	require.Equal(t, ErrInf, decode(inf, RejectInf|RejectNaN))
+	require.NoError(t, decode(finite, RejectInf|RejectNaN))
+	require.Equal(t, 1.5, f)
+
+	require.NoError(t, decode(nan, 0))
+	require.Equal(t, uint64(0xFFF8000000000123), math.Float64bits(f))
+	require.NoError(t, decode(nan, CanonicalNaN))
+	require.Equal(t, math.Float64bits(math.NaN()), math.Float64bits(f))
+
+	var h float32 = float32(math.Inf(1))
+	require.Equal(t, ErrInf, New(Chain(Float16(&h), CheckFloats(RejectInf))).Decode(New(Float16(&h)).Encode()))
+
+	c := complex(1, math.NaN())
+	require.Equal(t, ErrNaN, New(Chain(Complex128(&c), CheckFloats(RejectNaN))).Decode(New(Complex128(&c)).Encode()))
+
+	var u uint16
+	require.Panics(t, func() { Chain(FixedUint16(&u), CheckFloats(RejectNaN)) })
+}
@@ -0,0 +1,101 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func addVarintSeeds(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x00})
+	f.Add([]byte{0x7F})
+	f.Add([]byte{0x80})
+	f.Add([]byte{0x80, 0x00})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x10})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02})
+	f.Add([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00})
+}
+
+// Uvarint64 accepts exactly the minimal varints that encoding/binary and protowire accept, decodes
+// them to the same value, and consumes the same number of bytes.
+func FuzzUvarint64(f *testing.F) {
+	addVarintSeeds(f)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var x uint64
+		item := Uvarint64(&x)
+		err := item.Decode(b)
+
+		stdX, stdN := binary.Uvarint(b)
+		pbX, pbN := protowire.ConsumeVarint(b)
+		if stdN <= 0 || pbN < 0 || stdN != uvarintSize(stdX) {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, stdX, x)
+		require.Equal(t, pbX, x)
+		require.Equal(t, stdN, item.Size())
+		require.Equal(t, pbN, item.Size())
+
+		out := make([]byte, item.Size())
+		item.Encode(out)
+		require.Equal(t, b[:stdN], out)
+	})
+}
+
+// Uvarint32 agrees with Uvarint64 for values that fit in 32 bits, and rejects the rest.
+func FuzzUvarint32(f *testing.F) {
+	addVarintSeeds(f)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var x32 uint32
+		var x64 uint64
+		err32 := Uvarint32(&x32).Decode(b)
+		err64 := Uvarint64(&x64).Decode(b)
+		if err64 != nil || x64 > math.MaxUint32 {
+			require.Error(t, err32)
+			return
+		}
+		require.NoError(t, err32)
+		require.Equal(t, x64, uint64(x32))
+		require.Equal(t, Uvarint64(&x64).Size(), Uvarint32(&x32).Size())
+	})
+}
+
+// Little-endian fixed-width integers match protobuf's fixed32 and fixed64, and big-endian ones
+// match encoding/binary.
+func FuzzFixed(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x01, 0x02, 0x03})
+	f.Add([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var x32 uint32
+		err := Uint32(&x32, binary.LittleEndian).Decode(b)
+		pb32, n := protowire.ConsumeFixed32(b)
+		if n < 0 {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, pb32, x32)
+			require.NoError(t, FixedUint32(&x32).Decode(b))
+			require.Equal(t, binary.BigEndian.Uint32(b), x32)
+		}
+
+		var x64 uint64
+		err = Uint64(&x64, binary.LittleEndian).Decode(b)
+		pb64, n := protowire.ConsumeFixed64(b)
+		if n < 0 {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, pb64, x64)
+			require.NoError(t, FixedUint64(&x64).Decode(b))
+			require.Equal(t, binary.BigEndian.Uint64(b), x64)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNoCommonVersion = errors.New("encode: no protocol version supported by both sides")
+
+// What one side of a connection supports, sent to the other side at the start of the connection so
+// that both can agree on what to use with Negotiate.
+type Hello struct {
+	// The range of protocol versions supported, inclusive.
+	MinVersion uint8
+	MaxVersion uint8
+	// A bitmask of optional features supported, such as compression codecs. What each bit means is
+	// up to the protocol.
+	Features uint64
+}
+
+// Encode h compactly with Bitpacked: one byte each for MinVersion and MaxVersion, followed by the
+// nFeatures low-order bits of Features, padded to the nearest byte.
+//
+// Panics if nFeatures is not in [0, 64]. Bits of Features past nFeatures are not sent, so both
+// sides must agree on nFeatures, and new feature bits can only be added by bumping the version.
+func HelloItem(h *Hello, nFeatures int) TupleItem {
+	if nFeatures < 0 || nFeatures > 64 {
+		panic(fmt.Sprintf("invalid nFeatures=%d, must be in [0, 64]", nFeatures))
+	}
+	items := []BitpackItem{Bits8(&h.MinVersion, 8), Bits8(&h.MaxVersion, 8)}
+	if nFeatures > 0 {
+		items = append(items, Bits64(&h.Features, nFeatures))
+	}
+	if nFeatures%8 != 0 {
+		items = append(items, BitPadding(8-nFeatures%8))
+	}
+	return Bitpacked(items...)
+}
+
+// The result of Negotiate.
+type Negotiated struct {
+	// The highest version that both sides support.
+	Version uint8
+	// The features that both sides support.
+	Features uint64
+}
+
+// Returns what to use on a connection where this side sent local and the other sent remote.
+// Both sides reach the same result. Returns ErrNoCommonVersion if their version ranges don't
+// overlap.
+func Negotiate(local Hello, remote Hello) (Negotiated, error) {
+	lo := local.MinVersion
+	if remote.MinVersion > lo {
+		lo = remote.MinVersion
+	}
+	hi := local.MaxVersion
+	if remote.MaxVersion < hi {
+		hi = remote.MaxVersion
+	}
+	if lo > hi {
+		return Negotiated{}, ErrNoCommonVersion
+	}
+	return Negotiated{Version: hi, Features: local.Features & remote.Features}, nil
+}
@@ -0,0 +1,38 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshake(t *testing.T) {
+	const (
+		featureGzip = 1 << iota
+		featureZstd
+		featureTLS
+	)
+	client := Hello{MinVersion: 2, MaxVersion: 5, Features: featureGzip | featureZstd}
+	server := Hello{MinVersion: 1, MaxVersion: 3, Features: featureZstd | featureTLS}
+
+	b := New(HelloItem(&client, 3)).Encode()
+	require.Equal(t, []byte{0x02, 0x05, 0x03 << 5}, b)
+
+	var received Hello
+	require.NoError(t, New(HelloItem(&received, 3)).Decode(b))
+	require.Equal(t, client, received)
+
+	n1, err := Negotiate(server, received)
+	require.NoError(t, err)
+	n2, err := Negotiate(client, server)
+	require.NoError(t, err)
+	require.Equal(t, n1, n2)
+	require.Equal(t, Negotiated{Version: 3, Features: featureZstd}, n1)
+
+	_, err = Negotiate(client, Hello{MinVersion: 6, MaxVersion: 7})
+	require.Equal(t, ErrNoCommonVersion, err)
+
+	require.Len(t, New(HelloItem(&client, 0)).Encode(), 2)
+	require.Len(t, New(HelloItem(&client, 64)).Encode(), 10)
+	require.Panics(t, func() { HelloItem(&client, 65) })
+}
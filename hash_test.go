@@ -0,0 +1,30 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash64(t *testing.T) {
+	// Reference values from the XXH64 implementation.
+	require.Equal(t, uint64(0xef46db3751d8e999), Hash64(nil))
+	require.Equal(t, uint64(0xd24ec4f1a98c6e5b), Hash64([]byte("a")))
+	require.Equal(t, uint64(0x44bc2cf5ad770999), Hash64([]byte("abc")))
+	require.Equal(
+		t,
+		uint64(0xdecc246523920dba),
+		Hash64([]byte("this is a longer input that takes the thirty-two byte path")),
+	)
+}
+
+func TestTupleHashPrefix(t *testing.T) {
+	a := uint16(1)
+	b := true
+	c := uint64(300)
+	tuple := NewTuple(FixedUint16(&a), Bool(&b), OrdUvarint64(&c))
+
+	require.Equal(t, Hash64(tuple.EncodePrefix(2)), tuple.HashPrefix(2))
+	require.Equal(t, Hash64(tuple.Encode()), tuple.HashPrefix(3))
+	require.NotEqual(t, tuple.HashPrefix(1), tuple.HashPrefix(2))
+}
@@ -0,0 +1,99 @@
+package encode
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHilbert(t *testing.T) {
+	// Walk the entire curve for small spaces, checking that it visits every point exactly once and
+	// that each step moves to an adjacent point.
+	for _, tc := range []struct{ dims, bits int }{
+		{2, 1}, {2, 4}, {3, 1}, {3, 3}, {4, 2}, {4, 3},
+	} {
+		coords := make([]uint32, tc.dims)
+		ptrs := make([]*uint32, tc.dims)
+		for i := range coords {
+			ptrs[i] = &coords[i]
+		}
+		item := Hilbert(tc.bits, ptrs...)
+		enc := New(item)
+		pad := item.Size()*8 - tc.dims*tc.bits
+
+		seen := make(map[[4]uint32]struct{})
+		var prev []uint32
+		for h := uint64(0); h < 1<<(tc.dims*tc.bits); h++ {
+			b := make([]byte, item.Size())
+			v := h << pad
+			for i := len(b) - 1; i >= 0; i-- {
+				b[i] = byte(v)
+				v >>= 8
+			}
+			require.NoError(t, enc.Decode(b))
+			require.Equal(t, b, enc.Encode())
+
+			var key [4]uint32
+			copy(key[:], coords)
+			_, ok := seen[key]
+			require.False(t, ok)
+			seen[key] = struct{}{}
+
+			if prev == nil {
+				require.Equal(t, make([]uint32, tc.dims), coords)
+			} else {
+				dist := 0
+				for i := range coords {
+					if coords[i] > prev[i] {
+						dist += int(coords[i] - prev[i])
+					} else {
+						dist += int(prev[i] - coords[i])
+					}
+				}
+				require.Equal(t, 1, dist, "%v -> %v", prev, coords)
+			}
+			prev = append(prev[:0], coords...)
+		}
+	}
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		dims := 2 + r.Intn(3)
+		bits := 1 + r.Intn(32)
+		coords := make([]uint32, dims)
+		ptrs := make([]*uint32, dims)
+		for i := range coords {
+			coords[i] = uint32(r.Uint64() & (1<<bits - 1))
+			ptrs[i] = &coords[i]
+		}
+		expected := append([]uint32(nil), coords...)
+		enc := New(Hilbert(bits, ptrs...))
+		b := enc.Encode()
+		require.Len(t, b, (dims*bits+7)/8)
+		for i := range coords {
+			coords[i] = 0
+		}
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, expected, coords)
+	})
+
+	x, y := uint32(16), uint32(0)
+	require.Panics(t, func() { New(Hilbert(4, &x, &y)).Encode() })
+	require.Panics(t, func() { Hilbert(4, &x) })
+	require.Panics(t, func() { Hilbert(33, &x, &y) })
+
+	// An aligned square is a contiguous run of the curve, so it can be read with a single range scan.
+	var keys [][]byte
+	for x = 8; x < 12; x++ {
+		for y = 8; y < 12; y++ {
+			keys = append(keys, New(Hilbert(8, &x, &y)).Encode())
+		}
+	}
+	SortEncoded(keys)
+	for i := 1; i < len(keys); i++ {
+		h1 := int(keys[i-1][0])<<8 | int(keys[i-1][1])
+		h2 := int(keys[i][0])<<8 | int(keys[i][1])
+		require.Equal(t, h1+1, h2)
+	}
+}
@@ -0,0 +1,58 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// A hybrid logical clock timestamp, which orders events across machines whose physical clocks are
+// only loosely synchronized.
+type HLC struct {
+	// Physical time, in nanoseconds since the Unix epoch.
+	WallTime int64
+	// Breaks ties between events with the same WallTime.
+	Logical uint32
+}
+
+// Returns -1 if h is before other, 1 if h is after other, and 0 if they're the same.
+func (h HLC) Compare(other HLC) int {
+	switch {
+	case h.WallTime < other.WallTime:
+		return -1
+	case h.WallTime > other.WallTime:
+		return 1
+	case h.Logical < other.Logical:
+		return -1
+	case h.Logical > other.Logical:
+		return 1
+	}
+	return 0
+}
+
+// Encode v in 12 bytes such that the encoding sorts in the same order as Compare: WallTime in big
+// endian with the sign bit flipped, followed by Logical in big endian.
+func HLCItem(v *HLC) TupleItem {
+	return hlcItem{v}
+}
+
+type hlcItem struct{ v *HLC }
+
+func (e hlcItem) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e hlcItem) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e hlcItem) SizeTuple(last bool) int                 { return e.Size() }
+func (e hlcItem) OrderPreserving()                        {}
+func (e hlcItem) Encode(buf []byte) {
+	binary.BigEndian.PutUint64(buf, uint64(e.v.WallTime)^(1<<63))
+	binary.BigEndian.PutUint32(buf[8:], e.v.Logical)
+}
+func (e hlcItem) Size() int {
+	return 12
+}
+func (e hlcItem) Decode(buf []byte) error {
+	if len(buf) < 12 {
+		return io.ErrUnexpectedEOF
+	}
+	e.v.WallTime = int64(binary.BigEndian.Uint64(buf) ^ (1 << 63))
+	e.v.Logical = binary.BigEndian.Uint32(buf[8:])
+	return nil
+}
@@ -0,0 +1,31 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHLC(t *testing.T) {
+	h := HLC{WallTime: 1700000000000000000, Logical: 3}
+	enc := NewTuple(HLCItem(&h))
+	b := enc.Encode()
+	require.Len(t, b, 12)
+
+	h = HLC{}
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, HLC{WallTime: 1700000000000000000, Logical: 3}, h)
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(b[:11]))
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		h1 := HLC{WallTime: r.Int63n(8) - 4, Logical: uint32(r.Intn(4))}
+		h2 := HLC{WallTime: r.Int63n(8) - 4, Logical: uint32(r.Intn(4))}
+		b1 := NewTuple(HLCItem(&h1)).Encode()
+		b2 := NewTuple(HLCItem(&h2)).Encode()
+		require.Equal(t, h1.Compare(h2), bytes.Compare(b1, b2))
+	})
+}
@@ -0,0 +1,59 @@
+package encode
+
+// Returns a copy of enc that calls f with the whole buffer before decoding any of its items. If f
+// returns an error, Decode returns it without decoding anything.
+func (enc Encoding) WithPreDecode(f func(buf []byte) error) Encoding {
+	enc.preDecode = f
+	return enc
+}
+
+// Returns a copy of enc that calls f after all of its items have been decoded successfully. This is
+// the place to check constraints that span several fields, for example that a length field matches
+// the length of a payload. If f returns an error, Decode returns it.
+func (enc Encoding) WithPostDecode(f func() error) Encoding {
+	enc.postDecode = f
+	return enc
+}
+
+// Calls f with the item's buffer before item is decoded. If f returns an error, item is not decoded
+// and the error is returned.
+func PreDecode(item Item, f func(buf []byte) error) Item {
+	return hookedItem{item: item, pre: f}
+}
+
+// Calls f after item is decoded successfully. If f returns an error, it is returned from Decode.
+func PostDecode(item Item, f func() error) Item {
+	return hookedItem{item: item, post: f}
+}
+
+type hookedItem struct {
+	item Item
+	pre  func(buf []byte) error
+	post func() error
+}
+
+func (e hookedItem) Encode(buf []byte) {
+	e.item.Encode(buf)
+}
+func (e hookedItem) Size() int {
+	return e.item.Size()
+}
+func (e hookedItem) unwrap() Item {
+	return e.item
+}
+func (e hookedItem) Decode(buf []byte) error {
+	if e.pre != nil {
+		err := e.pre(buf)
+		if err != nil {
+			return err
+		}
+	}
+	err := e.item.Decode(buf)
+	if err != nil {
+		return err
+	}
+	if e.post != nil {
+		return e.post()
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package encode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	errMismatch := errors.New("length mismatch")
+
+	var n uint16
+	var payload [16]byte
+	var flag bool
+	var calls []string
+	enc := New(
+		FixedUint16(&n),
+		Bytes16(&payload),
+		PostDecode(Bool(&flag), func() error {
+			calls = append(calls, "flag")
+			return nil
+		}),
+	).WithPreDecode(func(buf []byte) error {
+		calls = append(calls, "pre")
+		return nil
+	}).WithPostDecode(func() error {
+		calls = append(calls, "post")
+		if n != uint16(len(payload)) {
+			return errMismatch
+		}
+		return nil
+	})
+
+	n = 16
+	flag = true
+	b := enc.Encode()
+	n = 0
+	flag = false
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, uint16(16), n)
+	require.True(t, flag)
+	require.Equal(t, []string{"pre", "flag", "post"}, calls)
+
+	b[1] = 15
+	require.Equal(t, errMismatch, enc.Decode(b))
+
+	errShort := errors.New("short")
+	item := PreDecode(Bool(&flag), func(buf []byte) error {
+		if len(buf) < 2 {
+			return errShort
+		}
+		return nil
+	})
+	require.Equal(t, errShort, New(item).Decode([]byte{1}))
+	require.NoError(t, New(item).Decode([]byte{1, 0}))
+
+	// Wrapping in a hook doesn't hide what the wrapped item needs from the Encoding around it.
+	noop := func() error { return nil }
+	var x byte
+	var y uint32
+	aligned := New(Byte(&x), PostDecode(AlignTo(4), noop), FixedUint32(&y))
+	x, y = 1, 2
+	b = aligned.Encode()
+	require.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 2}, b)
+	x, y = 0, 0
+	require.NoError(t, aligned.Decode(b))
+	require.Equal(t, uint32(2), y)
+
+	var z uint32
+	sparse := New(SparseStruct(
+		OptionalField(&z, PostDecode(Default(FixedUint32(&z), func() { z = 9 }), noop)),
+	))
+	z = 0
+	b = sparse.Encode()
+	z = 1
+	require.NoError(t, sparse.Decode(b))
+	require.Equal(t, uint32(0), z)
+	require.NoError(t, sparse.Decode([]byte{0x00}))
+	require.Equal(t, uint32(9), z)
+}
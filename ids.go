@@ -0,0 +1,135 @@
+package encode
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+)
+
+// A ULID, a 16 byte identifier made of a 48-bit millisecond Unix timestamp followed by 80 random
+// bits. ULIDs sort by the time they were made.
+//
+// See https://github.com/ulid/spec
+type ULID [16]byte
+
+// Makes a ULID for the time t with randomness from entropy, or from crypto/rand if entropy is nil.
+//
+// Panics if t is before the Unix epoch or past the range of 48 bits of milliseconds.
+func NewULID(t time.Time, entropy io.Reader) (ULID, error) {
+	ms := t.UnixMilli()
+	if ms < 0 || ms >= 1<<48 {
+		panic(fmt.Sprintf("%s is out of range for a ULID", t))
+	}
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	var u ULID
+	binary.BigEndian.PutUint16(u[:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+	_, err := io.ReadFull(entropy, u[6:])
+	if err != nil {
+		return ULID{}, err
+	}
+	return u, nil
+}
+
+// The time that u was made, to the millisecond.
+func (u ULID) Time() time.Time {
+	ms := uint64(binary.BigEndian.Uint16(u[:2]))<<32 | uint64(binary.BigEndian.Uint32(u[2:6]))
+	return time.UnixMilli(int64(ms))
+}
+
+// Returns the canonical 26 character Crockford base32 form of u.
+func (u ULID) String() string {
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	var out [26]byte
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = alphabet[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// Encode v as its 16 bytes, which sort in the order the ULIDs were made.
+func ULIDItem(v *ULID) TupleItem {
+	return Bytes16((*[16]byte)(v))
+}
+
+// The start of KSUID time, 2014-05-13T16:53:20Z, in seconds since the Unix epoch.
+const ksuidEpoch = 1400000000
+
+// A KSUID, a 20 byte identifier made of a 32-bit timestamp in seconds since 2014-05-13T16:53:20Z
+// followed by 128 random bits. KSUIDs sort by the time they were made.
+//
+// See https://github.com/segmentio/ksuid
+type KSUID [20]byte
+
+// Makes a KSUID for the time t with randomness from entropy, or from crypto/rand if entropy is nil.
+//
+// Panics if t is outside of the roughly 136 years that KSUIDs can represent.
+func NewKSUID(t time.Time, entropy io.Reader) (KSUID, error) {
+	s := t.Unix() - ksuidEpoch
+	if s < 0 || s >= 1<<32 {
+		panic(fmt.Sprintf("%s is out of range for a KSUID", t))
+	}
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	var k KSUID
+	binary.BigEndian.PutUint32(k[:4], uint32(s))
+	_, err := io.ReadFull(entropy, k[4:])
+	if err != nil {
+		return KSUID{}, err
+	}
+	return k, nil
+}
+
+// The time that k was made, to the second.
+func (k KSUID) Time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(k[:4]))+ksuidEpoch, 0)
+}
+
+// Returns the canonical 27 character base62 form of k.
+func (k KSUID) String() string {
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	var out [27]byte
+	x := new(big.Int).SetBytes(k[:])
+	base := big.NewInt(62)
+	rem := new(big.Int)
+	for i := len(out) - 1; i >= 0; i-- {
+		x.QuoRem(x, base, rem)
+		out[i] = alphabet[rem.Int64()]
+	}
+	return string(out[:])
+}
+
+// Encode v as its 20 bytes, which sort in the order the KSUIDs were made.
+func KSUIDItem(v *KSUID) TupleItem {
+	return ksuidItem{v}
+}
+
+type ksuidItem struct{ v *KSUID }
+
+func (e ksuidItem) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ksuidItem) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ksuidItem) SizeTuple(last bool) int                 { return e.Size() }
+func (e ksuidItem) OrderPreserving()                        {}
+func (e ksuidItem) Encode(buf []byte) {
+	copy(buf, e.v[:])
+}
+func (e ksuidItem) Size() int {
+	return 20
+}
+func (e ksuidItem) Decode(buf []byte) error {
+	if len(buf) < 20 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(e.v[:], buf)
+	return nil
+}
@@ -0,0 +1,54 @@
+package encode
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestULID(t *testing.T) {
+	ts := time.UnixMilli(1469918176385)
+	u, err := NewULID(ts, bytes.NewReader(make([]byte, 10)))
+	require.NoError(t, err)
+	require.Equal(t, "01ARYZ6S410000000000000000", u.String())
+	require.True(t, u.Time().Equal(ts))
+
+	u2, err := NewULID(ts.Add(time.Millisecond), nil)
+	require.NoError(t, err)
+	b := NewTuple(ULIDItem(&u)).Encode()
+	b2 := NewTuple(ULIDItem(&u2)).Encode()
+	require.Len(t, b, 16)
+	require.True(t, bytes.Compare(b, b2) < 0)
+
+	var u3 ULID
+	require.NoError(t, New(ULIDItem(&u3)).Decode(b2))
+	require.Equal(t, u2, u3)
+}
+
+func TestKSUID(t *testing.T) {
+	var zero KSUID
+	require.Equal(t, "000000000000000000000000000", zero.String())
+	max := KSUID{}
+	for i := range max {
+		max[i] = 0xFF
+	}
+	require.Equal(t, "aWgEPTl1tmebfsQzFP4bxwgy80V", max.String())
+
+	ts := time.Unix(1600000000, 0)
+	k, err := NewKSUID(ts, nil)
+	require.NoError(t, err)
+	require.True(t, k.Time().Equal(ts))
+
+	k2, err := NewKSUID(ts.Add(time.Second), nil)
+	require.NoError(t, err)
+	b := NewTuple(KSUIDItem(&k)).Encode()
+	b2 := NewTuple(KSUIDItem(&k2)).Encode()
+	require.Len(t, b, 20)
+	require.True(t, bytes.Compare(b, b2) < 0)
+
+	var k3 KSUID
+	require.NoError(t, New(KSUIDItem(&k3)).Decode(b2))
+	require.Equal(t, k2, k3)
+}
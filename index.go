@@ -0,0 +1,38 @@
+package encode
+
+// Returns the key of a secondary index entry: the encoding of indexed followed by the encoding of
+// primary. Index entries sort by the indexed fields, and then by primary key, so entries with equal
+// indexed fields are still distinct. PrimaryKey inverts this.
+//
+// indexed is encoded with every item self-delimiting, including the last, so that the primary key
+// can be found again.
+func IndexKey(primary Tuple, indexed Tuple) []byte {
+	size := 0
+	for _, item := range indexed.items {
+		size += item.SizeTuple(false)
+	}
+	primaryBuf := primary.Encode()
+	buf := make([]byte, size, size+len(primaryBuf))
+	j := 0
+	for _, item := range indexed.items {
+		size := item.SizeTuple(false)
+		item.EncodeTuple(buf[j:j+size:j+size], false)
+		j += size
+	}
+	return append(buf, primaryBuf...)
+}
+
+// Decodes the indexed fields of indexKey, made by IndexKey, into indexed, and returns the encoded
+// primary key that follows them. The result can be decoded with the primary key's Tuple, or used
+// directly to look up the primary record.
+func PrimaryKey(indexKey []byte, indexed Tuple) ([]byte, error) {
+	j := 0
+	for _, item := range indexed.items {
+		err := item.DecodeTuple(indexKey[j:], false)
+		if err != nil {
+			return nil, err
+		}
+		j += item.SizeTuple(false)
+	}
+	return indexKey[j:], nil
+}
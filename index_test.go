@@ -0,0 +1,41 @@
+package encode
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexKey(t *testing.T) {
+	var id uint64
+	var email string
+	primary := NewTuple(OrdUvarint64(&id))
+	indexed := NewTuple(EscapedString(&email))
+
+	id, email = 7, "b@example.com"
+	k1 := IndexKey(primary, indexed)
+	id, email = 3, "b@example.com"
+	k2 := IndexKey(primary, indexed)
+	id, email = 1, "c@example.com"
+	k3 := IndexKey(primary, indexed)
+	id, email = 9, "b"
+	k4 := IndexKey(primary, indexed)
+
+	require.True(t, bytes.Compare(k4, k2) < 0)
+	require.True(t, bytes.Compare(k2, k1) < 0)
+	require.True(t, bytes.Compare(k1, k3) < 0)
+
+	id, email = 0, ""
+	pk, err := PrimaryKey(k1, indexed)
+	require.NoError(t, err)
+	require.Equal(t, "b@example.com", email)
+	require.NoError(t, primary.Decode(pk))
+	require.Equal(t, uint64(7), id)
+
+	id = 7
+	require.Equal(t, primary.Encode(), pk)
+
+	_, err = PrimaryKey(k1[:3], indexed)
+	require.Error(t, err)
+}
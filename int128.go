@@ -0,0 +1,112 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// An unsigned 128-bit integer, as used for IPv6 addresses and some databases' row IDs.
+type Uint128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+// Returns the Uint128 whose big endian representation is b, such as an IPv6 address.
+func Uint128FromBytes(b [16]byte) Uint128 {
+	return Uint128{Hi: binary.BigEndian.Uint64(b[:8]), Lo: binary.BigEndian.Uint64(b[8:])}
+}
+
+// Returns the big endian representation of x.
+func (x Uint128) Bytes() [16]byte {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], x.Hi)
+	binary.BigEndian.PutUint64(b[8:], x.Lo)
+	return b
+}
+
+// A signed 128-bit integer in two's complement.
+type Int128 struct {
+	Hi int64
+	Lo uint64
+}
+
+// Encode v in big endian order, taking 16 bytes.
+func FixedUint128(v *Uint128) TupleItem {
+	return fixedUint128{v}
+}
+
+type fixedUint128 struct{ v *Uint128 }
+
+func (e fixedUint128) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e fixedUint128) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e fixedUint128) SizeTuple(last bool) int                 { return e.Size() }
+func (e fixedUint128) OrderPreserving()                        {}
+func (e fixedUint128) Encode(buf []byte) {
+	binary.BigEndian.PutUint64(buf, e.v.Hi)
+	binary.BigEndian.PutUint64(buf[8:], e.v.Lo)
+}
+func (e fixedUint128) Size() int {
+	return 16
+}
+func (e fixedUint128) Decode(buf []byte) error {
+	if len(buf) < 16 {
+		return io.ErrUnexpectedEOF
+	}
+	e.v.Hi = binary.BigEndian.Uint64(buf)
+	e.v.Lo = binary.BigEndian.Uint64(buf[8:])
+	return nil
+}
+
+// Encode v as big endian two's complement, taking 16 bytes.
+//
+// This does not preserve ordering since negative numbers sort after positive ones, use OrdInt128 for
+// that.
+func FixedInt128(v *Int128) Item {
+	return fixedInt128{v}
+}
+
+type fixedInt128 struct{ v *Int128 }
+
+func (e fixedInt128) Encode(buf []byte) {
+	binary.BigEndian.PutUint64(buf, uint64(e.v.Hi))
+	binary.BigEndian.PutUint64(buf[8:], e.v.Lo)
+}
+func (e fixedInt128) Size() int {
+	return 16
+}
+func (e fixedInt128) Decode(buf []byte) error {
+	if len(buf) < 16 {
+		return io.ErrUnexpectedEOF
+	}
+	e.v.Hi = int64(binary.BigEndian.Uint64(buf))
+	e.v.Lo = binary.BigEndian.Uint64(buf[8:])
+	return nil
+}
+
+// Encode v in 16 bytes such that the encoding sorts in the same order as the value. This is big
+// endian two's complement with the sign bit flipped.
+func OrdInt128(v *Int128) TupleItem {
+	return ordInt128{v}
+}
+
+type ordInt128 struct{ v *Int128 }
+
+func (e ordInt128) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ordInt128) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ordInt128) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordInt128) OrderPreserving()                        {}
+func (e ordInt128) Encode(buf []byte) {
+	binary.BigEndian.PutUint64(buf, uint64(e.v.Hi)^(1<<63))
+	binary.BigEndian.PutUint64(buf[8:], e.v.Lo)
+}
+func (e ordInt128) Size() int {
+	return 16
+}
+func (e ordInt128) Decode(buf []byte) error {
+	if len(buf) < 16 {
+		return io.ErrUnexpectedEOF
+	}
+	e.v.Hi = int64(binary.BigEndian.Uint64(buf) ^ (1 << 63))
+	e.v.Lo = binary.BigEndian.Uint64(buf[8:])
+	return nil
+}
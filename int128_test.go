@@ -0,0 +1,58 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUint128(t *testing.T) {
+	b := [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 0x01}
+	x := Uint128FromBytes(b)
+	require.Equal(t, Uint128{Hi: 0x20010db800000000, Lo: 1}, x)
+	require.Equal(t, b, x.Bytes())
+
+	enc := NewTuple(FixedUint128(&x))
+	buf := enc.Encode()
+	require.Equal(t, b[:], buf)
+	x = Uint128{}
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, b, x.Bytes())
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(buf[:15]))
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		x1 := Uint128{Hi: r.Uint64() >> uint(r.Intn(64)), Lo: r.Uint64()}
+		x2 := Uint128{Hi: r.Uint64() >> uint(r.Intn(64)), Lo: r.Uint64()}
+		if x2.Hi < x1.Hi || (x2.Hi == x1.Hi && x2.Lo < x1.Lo) {
+			x1, x2 = x2, x1
+		}
+		require.True(t, bytes.Compare(NewTuple(FixedUint128(&x1)).Encode(), NewTuple(FixedUint128(&x2)).Encode()) <= 0)
+	})
+}
+
+func TestInt128(t *testing.T) {
+	x := Int128{Hi: -1, Lo: ^uint64(0)}
+	require.Equal(t, bytes.Repeat([]byte{0xFF}, 16), New(FixedInt128(&x)).Encode())
+	require.Equal(t, append([]byte{0x7F}, bytes.Repeat([]byte{0xFF}, 15)...), NewTuple(OrdInt128(&x)).Encode())
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		x1 := Int128{Hi: int64(r.Uint64()) >> uint(r.Intn(64)), Lo: r.Uint64()}
+		x2 := Int128{Hi: int64(r.Uint64()) >> uint(r.Intn(64)), Lo: r.Uint64()}
+		if x2.Hi < x1.Hi || (x2.Hi == x1.Hi && x2.Lo < x1.Lo) {
+			x1, x2 = x2, x1
+		}
+
+		for _, item := range []Item{FixedInt128(&x1), OrdInt128(&x1)} {
+			expected := x1
+			b := New(item).Encode()
+			x1 = Int128{}
+			require.NoError(t, New(item).Decode(b))
+			require.Equal(t, expected, x1)
+		}
+		require.True(t, bytes.Compare(NewTuple(OrdInt128(&x1)).Encode(), NewTuple(OrdInt128(&x2)).Encode()) <= 0)
+	})
+}
@@ -0,0 +1,164 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+var ErrUnknownType = errors.New("encode: unknown type name")
+
+// A type that knows how to encode itself, as in the usage described in the package documentation.
+type Encodable interface {
+	Encode() []byte
+	Decode(b []byte) error
+}
+
+var (
+	typesMu     sync.RWMutex
+	typesByName = map[string]reflect.Type{}
+	namesByType = map[reflect.Type]string{}
+)
+
+// Records v's concrete type under name, so that it can be encoded and decoded by Interface. v must
+// be a pointer, since decoding has to create a new value and mutate it.
+//
+// Like gob.RegisterName, the name is what gets encoded, so it must stay the same for as long as
+// encoded values are around. Panics if either name or v's type is already registered to something
+// else.
+func RegisterType(name string, v Encodable) {
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("encode: RegisterType requires a pointer, got %s", t))
+	}
+	if name == "" {
+		panic("encode: RegisterType requires a non-empty name")
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	if existing, ok := typesByName[name]; ok && existing != t {
+		panic(fmt.Sprintf("encode: name %q already registered for %s", name, existing))
+	}
+	if existing, ok := namesByType[t]; ok && existing != name {
+		panic(fmt.Sprintf("encode: %s already registered as %q", t, existing))
+	}
+	typesByName[name] = t
+	namesByType[t] = name
+}
+
+// Encode v as the name its concrete type was registered with by RegisterType, followed by the
+// result of its Encode method, each prefixed with a uvarint length. Decoding creates a new value of
+// the named type and replaces *v with it. A nil *v is encoded as an empty name.
+//
+// Encoding calls v's Encode method twice, once to find the size and once to write it.
+func Interface(v *Encodable) Item {
+	return encInterface{v}
+}
+
+type encInterface struct{ v *Encodable }
+
+func (e encInterface) name() string {
+	if *e.v == nil {
+		return ""
+	}
+	t := reflect.TypeOf(*e.v)
+	typesMu.RLock()
+	name, ok := namesByType[t]
+	typesMu.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("encode: %s is not registered, see RegisterType", t))
+	}
+	return name
+}
+func (e encInterface) Encode(buf []byte) {
+	name := e.name()
+	n := binary.PutUvarint(buf, uint64(len(name)))
+	n += copy(buf[n:], name)
+	if *e.v == nil {
+		return
+	}
+	b := (*e.v).Encode()
+	n += binary.PutUvarint(buf[n:], uint64(len(b)))
+	copy(buf[n:], b)
+}
+func (e encInterface) Size() int {
+	name := e.name()
+	n := uvarintSize(uint64(len(name))) + len(name)
+	if *e.v == nil {
+		return n
+	}
+	l := len((*e.v).Encode())
+	return n + uvarintSize(uint64(l)) + l
+}
+func (e encInterface) Decode(buf []byte) error {
+	name, n, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	if len(name) == 0 {
+		*e.v = nil
+		return nil
+	}
+	typesMu.RLock()
+	t, ok := typesByName[string(name)]
+	typesMu.RUnlock()
+	if !ok {
+		return ErrUnknownType
+	}
+	b, _, err := decodeLengthDelim(buf[n:])
+	if err != nil {
+		return err
+	}
+	x := reflect.New(t.Elem()).Interface().(Encodable)
+	err = x.Decode(b)
+	if err != nil {
+		return err
+	}
+	*e.v = x
+	return nil
+}
+
+// Implemented by items that wrap another item and encode exactly as it does, so that the unexported
+// interfaces that other items look for, such as offsetter and defaulter, are found through them.
+type wrapper interface {
+	unwrap() Item
+}
+
+// Returns item or the first item it wraps that implements T.
+func findItem[T any](item Item) (T, bool) {
+	for {
+		if t, ok := item.(T); ok {
+			return t, true
+		}
+		w, ok := item.(wrapper)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		item = w.unwrap()
+	}
+}
+
+// Reads a uvarint length followed by that many bytes from the front of buf, returning those bytes
+// and the total number of bytes consumed.
+func decodeLengthDelim(buf []byte) ([]byte, int, error) {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return nil, 0, ErrOverflowVarint
+	}
+	if n != uvarintSize(l) {
+		// Size is the minimal length, so accepting padding would leave the next item misaligned.
+		return nil, 0, ErrNonCanonical
+	}
+	if uint64(len(buf[n:])) < l {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	return buf[n : n+int(l)], n + int(l), nil
+}
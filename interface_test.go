@@ -0,0 +1,48 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCircle struct{ r uint16 }
+
+func (c *testCircle) Encode() []byte        { return New(FixedUint16(&c.r)).Encode() }
+func (c *testCircle) Decode(b []byte) error { return New(FixedUint16(&c.r)).Decode(b) }
+
+type testSquare struct{ side uint32 }
+
+func (s *testSquare) Encode() []byte        { return New(FixedUint32(&s.side)).Encode() }
+func (s *testSquare) Decode(b []byte) error { return New(FixedUint32(&s.side)).Decode(b) }
+
+func TestInterface(t *testing.T) {
+	RegisterType("circle", (*testCircle)(nil))
+	RegisterType("square", (*testSquare)(nil))
+	// Registering the same thing twice is fine.
+	RegisterType("circle", (*testCircle)(nil))
+	require.Panics(t, func() { RegisterType("circle", (*testSquare)(nil)) })
+
+	var shape Encodable = &testSquare{side: 5}
+	var flag bool
+	enc := New(Interface(&shape), Bool(&flag))
+	b := enc.Encode()
+	require.Equal(t, []byte{
+		6, 's', 'q', 'u', 'a', 'r', 'e',
+		4, 0, 0, 0, 5,
+		0,
+	}, b)
+
+	shape = &testCircle{r: 1}
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, &testSquare{side: 5}, shape)
+
+	shape = nil
+	b = enc.Encode()
+	require.Equal(t, []byte{0, 0}, b)
+	shape = &testCircle{r: 1}
+	require.NoError(t, enc.Decode(b))
+	require.Nil(t, shape)
+
+	require.Equal(t, ErrUnknownType, enc.Decode([]byte{3, 'h', 'e', 'x', 0, 0}))
+}
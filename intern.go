@@ -0,0 +1,60 @@
+package encode
+
+// Deduplicates strings decoded across many records, so that equal strings share one allocation
+// instead of each record holding its own copy. This cuts memory substantially when decoding many
+// records with low-cardinality string fields, and the strings it holds are kept alive for as long as
+// the Interner is, so use one per batch rather than one forever.
+//
+// An Interner is not safe for concurrent use.
+type Interner struct {
+	m map[string]string
+}
+
+func NewInterner() *Interner {
+	return &Interner{m: make(map[string]string)}
+}
+
+// Returns a string equal to s, reusing an earlier one if there is one.
+func (in *Interner) Intern(s string) string {
+	interned, ok := in.m[s]
+	if !ok {
+		in.m[s] = s
+		return s
+	}
+	return interned
+}
+
+func (in *Interner) intern(b []byte) string {
+	// The compiler recognizes this lookup and doesn't allocate for the conversion.
+	s, ok := in.m[string(b)]
+	if !ok {
+		s = string(b)
+		in.m[s] = s
+	}
+	return s
+}
+
+// The number of distinct strings held.
+func (in *Interner) Len() int {
+	return len(in.m)
+}
+
+// Like LengthDelimString, but decoding returns strings interned by in, and doesn't allocate for
+// strings it has already seen.
+func (in *Interner) String(v *string) Item {
+	return internedString{in: in, lengthDelimString: lengthDelimString{v}}
+}
+
+type internedString struct {
+	in *Interner
+	lengthDelimString
+}
+
+func (e internedString) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	*e.v = e.in.intern(b)
+	return nil
+}
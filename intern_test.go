@@ -0,0 +1,31 @@
+package encode
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterner(t *testing.T) {
+	in := NewInterner()
+	service := "frontend"
+	b := New(LengthDelimString(&service)).Encode()
+	require.Equal(t, b, New(in.String(&service)).Encode())
+
+	var s1, s2 string
+	require.NoError(t, New(in.String(&s1)).Decode(b))
+	require.NoError(t, New(in.String(&s2)).Decode(b))
+	require.Equal(t, "frontend", s1)
+	require.Equal(t, "frontend", s2)
+	require.Equal(t, unsafe.StringData(s1), unsafe.StringData(s2))
+	require.Equal(t, 1, in.Len())
+	require.Equal(t, unsafe.StringData(s1), unsafe.StringData(in.Intern(string([]byte("frontend")))))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = New(in.String(&s1)).Decode(b)
+	})
+	require.LessOrEqual(t, allocs, 1.0)
+
+	require.Error(t, New(in.String(&s1)).Decode(b[:3]))
+}
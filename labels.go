@@ -0,0 +1,59 @@
+package encode
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"time"
+)
+
+const (
+	opEncode = "Encode"
+	opDecode = "Decode"
+)
+
+// Returns a copy of enc that attributes its work to name, typically the name of the record type,
+// in profiles and execution traces. Encoding and decoding run inside a runtime/trace region named
+// "encode.Encode name" or "encode.Decode name".
+//
+// EncodeCtx and DecodeCtx also run with the pprof label encode=name added to the labels in their
+// context, so CPU profiles can be filtered or grouped by record type with pprof's -tagfocus and
+// -tagroot flags. As with pprof.Do, the goroutine is left with the context's labels afterward.
+// Encode and Decode have no context to take labels from, so they leave the goroutine's labels
+// alone.
+func (enc Encoding) WithName(name string) Encoding {
+	enc.name = name
+	return enc
+}
+
+// Runs f, which does op and returns the number of bytes encoded or decoded, applying enc's name and
+// stats. If label is set, the goroutine's pprof labels are set from ctx for the duration.
+func (enc Encoding) instrument(
+	ctx context.Context,
+	op string,
+	label bool,
+	f func(ctx context.Context) (int, error),
+) error {
+	if enc.name != "" {
+		if label {
+			// Equivalent to pprof.Do, which would force f and everything it references onto the
+			// heap. Likewise, cloning the name keeps enc itself, including items, from escaping.
+			labeled := pprof.WithLabels(ctx, pprof.Labels("encode", strings.Clone(enc.name)))
+			pprof.SetGoroutineLabels(labeled)
+			defer pprof.SetGoroutineLabels(ctx)
+			ctx = labeled
+		}
+		defer trace.StartRegion(ctx, "encode."+op+" "+enc.name).End()
+	}
+	start := time.Now()
+	n, err := f(ctx)
+	if enc.stats != nil {
+		if op == opEncode {
+			enc.stats.recordEncode(start, n)
+		} else {
+			enc.stats.recordDecode(start, n, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package encode
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Records the pprof labels of the context it's decoded with.
+type labelItem struct {
+	labels map[string]string
+}
+
+func (e labelItem) Encode(buf []byte)       {}
+func (e labelItem) Decode(buf []byte) error { return nil }
+func (e labelItem) Size() int               { return 0 }
+func (e labelItem) EncodeCtx(ctx context.Context, buf []byte) error {
+	return e.DecodeCtx(ctx, buf)
+}
+func (e labelItem) DecodeCtx(ctx context.Context, buf []byte) error {
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		e.labels[key] = value
+		return true
+	})
+	return nil
+}
+
+func TestWithName(t *testing.T) {
+	var x uint16
+	item := labelItem{labels: make(map[string]string)}
+	var s Stats
+	enc := New(FixedUint16(&x), item).WithName("foo").WithStats(&s)
+
+	x = 5
+	b := enc.Encode()
+	require.NoError(t, enc.Decode(b))
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "bar"))
+	require.NoError(t, enc.DecodeCtx(ctx, b))
+	require.Equal(t, map[string]string{"encode": "foo", "request": "bar"}, item.labels)
+
+	_, err := enc.EncodeCtx(context.Background())
+	require.NoError(t, err)
+
+	snap := s.Snapshot()
+	require.Equal(t, int64(2), snap.Encodes)
+	require.Equal(t, int64(2), snap.Decodes)
+
+	item = labelItem{labels: make(map[string]string)}
+	require.NoError(t, New(FixedUint16(&x), item).DecodeCtx(ctx, b))
+	require.Equal(t, map[string]string{"request": "bar"}, item.labels)
+}
@@ -0,0 +1,74 @@
+package encode
+
+// The layout of a record of type T, kept apart from any particular record. Since an Encoding's items
+// point at the values they encode, a single Encoding can't be shared between goroutines or defined
+// once as a package-level variable. A Layout can: it only describes how to build an Encoding, and
+// each Bind builds a new one for the given record, so it is immutable and safe to use concurrently.
+//
+//   var userLayout = encode.NewLayout(func(u *User) encode.Encoding {
+//   	return encode.New(
+//   		encode.Uvarint64(&u.ID),
+//   		encode.LengthDelimString(&u.Name),
+//   	)
+//   })
+//
+//   b := userLayout.Encode(&user)
+//
+// Bind has the signature Records expects, so Records(&users, userLayout.Bind) encodes a slice of
+// them.
+type Layout[T any] struct {
+	bind func(v *T) Encoding
+}
+
+// Returns a Layout that binds records with bind, which must return a new Encoding of v each time
+// it's called.
+func NewLayout[T any](bind func(v *T) Encoding) Layout[T] {
+	return Layout[T]{bind: bind}
+}
+
+// Returns an Encoding of v.
+func (l Layout[T]) Bind(v *T) Encoding {
+	return l.bind(v)
+}
+
+// Returns the encoding of v.
+func (l Layout[T]) Encode(v *T) []byte {
+	return l.bind(v).Encode()
+}
+
+// Decodes buf into v.
+func (l Layout[T]) Decode(buf []byte, v *T) error {
+	return l.bind(v).Decode(buf)
+}
+
+// One field of a Layout built with LayoutOf.
+type LayoutField[T any] struct {
+	item func(v *T) Item
+}
+
+// Returns a field of T that is found with get and encoded with item, which is typically one of this
+// package's constructors, such as Uvarint64 or LengthDelimString:
+//
+//   encode.Field(func(u *User) *uint64 { return &u.ID }, encode.Uvarint64)
+//
+// get must return a pointer into v.
+func Field[T any, F any, I Item](get func(v *T) *F, item func(f *F) I) LayoutField[T] {
+	return LayoutField[T]{item: func(v *T) Item { return item(get(v)) }}
+}
+
+// Returns a Layout of fields, in order. This is equivalent to NewLayout with a function returning
+// New of each field's item, but refers to fields through their accessors:
+//
+//   var userLayout = encode.LayoutOf(
+//   	encode.Field(func(u *User) *uint64 { return &u.ID }, encode.Uvarint64),
+//   	encode.Field(func(u *User) *string { return &u.Name }, encode.LengthDelimString),
+//   )
+func LayoutOf[T any](fields ...LayoutField[T]) Layout[T] {
+	return NewLayout(func(v *T) Encoding {
+		items := make([]Item, len(fields))
+		for i, f := range fields {
+			items[i] = f.item(v)
+		}
+		return New(items...)
+	})
+}
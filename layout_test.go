@@ -0,0 +1,63 @@
+package encode
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type layoutTestRecord struct {
+	ID   uint64
+	Name string
+	Tag  uint16
+}
+
+var layoutTestLayout = NewLayout(func(r *layoutTestRecord) Encoding {
+	return New(
+		Uvarint64(&r.ID),
+		LengthDelimString(&r.Name),
+		AlignTo(4),
+		FixedUint16(&r.Tag),
+	)
+})
+
+func TestLayout(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r := layoutTestRecord{ID: uint64(i), Name: string(make([]byte, j)), Tag: uint16(j)}
+				b := layoutTestLayout.Encode(&r)
+				var out layoutTestRecord
+				require.NoError(t, layoutTestLayout.Decode(b, &out))
+				require.Equal(t, r, out)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	rs := []layoutTestRecord{{ID: 1, Name: "a", Tag: 2}, {ID: 3, Name: "bcd", Tag: 4}}
+	b := New(Records(&rs, layoutTestLayout.Bind)).Encode()
+	var out []layoutTestRecord
+	require.NoError(t, New(Records(&out, layoutTestLayout.Bind)).Decode(b))
+	require.Equal(t, rs, out)
+}
+
+func TestLayoutOf(t *testing.T) {
+	l := LayoutOf(
+		Field(func(r *layoutTestRecord) *uint64 { return &r.ID }, Uvarint64),
+		Field(func(r *layoutTestRecord) *string { return &r.Name }, LengthDelimString),
+		Field(func(r *layoutTestRecord) *uint16 { return &r.Tag }, FixedUint16),
+	)
+
+	r := layoutTestRecord{ID: 300, Name: "foo", Tag: 0xBEEF}
+	b := l.Encode(&r)
+	require.Equal(t, New(Uvarint64(&r.ID), LengthDelimString(&r.Name), FixedUint16(&r.Tag)).Encode(), b)
+
+	var out layoutTestRecord
+	require.NoError(t, l.Decode(b, &out))
+	require.Equal(t, r, out)
+}
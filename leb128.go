@@ -0,0 +1,169 @@
+package encode
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrNonCanonical = errors.New("encode: non-canonical encoding")
+
+// Encode v as unsigned LEB128, as used by DWARF and WebAssembly. The minimal encoding is the same as
+// Uvarint64's.
+//
+// Decoding accepts non-minimal encodings, padded with extra 0x80 bytes, as some linkers emit. The
+// padded width is remembered and used again if this same Item is encoded afterwards, unless the new
+// value needs more room, so padded fields round-trip byte-for-byte.
+func ULEB128(v *uint64) Item {
+	return uleb128{v: v, width: new(int)}
+}
+
+// Like ULEB128, but decoding returns ErrNonCanonical for non-minimal encodings.
+func ULEB128Canonical(v *uint64) Item {
+	return uleb128{v: v}
+}
+
+type uleb128 struct {
+	v *uint64
+	// The width of the last decoded value, or nil if only canonical encodings are allowed.
+	width *int
+}
+
+func (e uleb128) Encode(buf []byte) {
+	x := *e.v
+	n := e.Size()
+	for i := 0; i < n; i++ {
+		b := byte(x & 0x7F)
+		x >>= 7
+		if i < n-1 {
+			b |= 0x80
+		}
+		buf[i] = b
+	}
+}
+func (e uleb128) Size() int {
+	n := uvarintSize(*e.v)
+	if e.width != nil && *e.width > n {
+		return *e.width
+	}
+	return n
+}
+func (e uleb128) Decode(buf []byte) error {
+	x := uint64(0)
+	shift := uint(0)
+	for i, b := range buf {
+		low := uint64(b & 0x7F)
+		if shift < 64 {
+			if shift == 63 && low > 1 {
+				return ErrOverflowVarint
+			}
+			x |= low << shift
+		} else if low != 0 {
+			return ErrOverflowVarint
+		}
+		shift += 7
+		if b&0x80 != 0 {
+			continue
+		}
+		if e.width == nil {
+			if i > 0 && b == 0 {
+				return ErrNonCanonical
+			}
+		} else {
+			*e.width = i + 1
+		}
+		*e.v = x
+		return nil
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// Encode v as signed LEB128, as used by DWARF and WebAssembly. Unlike Varint64, this is two's
+// complement sign extension rather than zigzag encoding.
+//
+// Decoding accepts non-minimal encodings, padded with extra sign-extension bytes. The padded width is
+// remembered and used again if this same Item is encoded afterwards, unless the new value needs more
+// room, so padded fields round-trip byte-for-byte.
+func SLEB128(v *int64) Item {
+	return sleb128{v: v, width: new(int)}
+}
+
+// Like SLEB128, but decoding returns ErrNonCanonical for non-minimal encodings.
+func SLEB128Canonical(v *int64) Item {
+	return sleb128{v: v}
+}
+
+type sleb128 struct {
+	v *int64
+	// The width of the last decoded value, or nil if only canonical encodings are allowed.
+	width *int
+}
+
+func (e sleb128) Encode(buf []byte) {
+	x := *e.v
+	n := e.Size()
+	for i := 0; i < n; i++ {
+		b := byte(x & 0x7F)
+		x >>= 7
+		if i < n-1 {
+			b |= 0x80
+		}
+		buf[i] = b
+	}
+}
+func (e sleb128) Size() int {
+	x := *e.v
+	n := 1
+	for {
+		b := byte(x & 0x7F)
+		x >>= 7
+		if (x == 0 && b&0x40 == 0) || (x == -1 && b&0x40 != 0) {
+			break
+		}
+		n++
+	}
+	if e.width != nil && *e.width > n {
+		return *e.width
+	}
+	return n
+}
+func (e sleb128) Decode(buf []byte) error {
+	x := uint64(0)
+	shift := uint(0)
+	// The bits of bytes at or past bit 63 must all be copies of the sign bit.
+	var high []byte
+	for i, b := range buf {
+		low := b & 0x7F
+		if shift < 64 {
+			x |= uint64(low) << shift
+		}
+		if shift >= 63 {
+			high = append(high, low)
+		}
+		shift += 7
+		if b&0x80 != 0 {
+			continue
+		}
+		if shift < 64 && b&0x40 != 0 {
+			x |= ^uint64(0) << shift
+		}
+		extension := byte(0)
+		if int64(x) < 0 {
+			extension = 0x7F
+		}
+		for _, h := range high {
+			if h != extension {
+				return ErrOverflowVarint
+			}
+		}
+		if e.width == nil {
+			if i > 0 && ((b == 0x00 && buf[i-1]&0x40 == 0) || (b == 0x7F && buf[i-1]&0x40 != 0)) {
+				return ErrNonCanonical
+			}
+		} else {
+			*e.width = i + 1
+		}
+		*e.v = int64(x)
+		return nil
+	}
+	return io.ErrUnexpectedEOF
+}
@@ -0,0 +1,100 @@
+package encode
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULEB128(t *testing.T) {
+	check := func(x uint64, expected []byte) {
+		enc := New(ULEB128Canonical(&x))
+		b := enc.Encode()
+		require.Equal(t, expected, b)
+		x2 := x
+		x = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x2, x)
+	}
+	// Examples from the DWARF specification.
+	check(2, []byte{2})
+	check(127, []byte{127})
+	check(128, []byte{0x80, 1})
+	check(129, []byte{1 + 0x80, 1})
+	check(130, []byte{2 + 0x80, 1})
+	check(12857, []byte{57 + 0x80, 100})
+	check(math.MaxUint64, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		x := r.Uint64() >> uint(r.Intn(64))
+		require.Equal(t, New(Uvarint64(&x)).Encode(), New(ULEB128(&x)).Encode())
+	})
+
+	var x uint64
+	padded := []byte{0x85, 0x80, 0x80, 0x00}
+	require.Equal(t, ErrNonCanonical, New(ULEB128Canonical(&x)).Decode(padded))
+
+	var y bool
+	enc := New(ULEB128(&x), Bool(&y))
+	require.NoError(t, enc.Decode(append(padded, 0x01)))
+	require.Equal(t, uint64(5), x)
+	require.True(t, y)
+	require.Equal(t, append(padded, 0x01), enc.Encode())
+
+	require.Equal(t, io.ErrUnexpectedEOF, New(ULEB128(&x)).Decode([]byte{0x80}))
+	require.Equal(
+		t,
+		ErrOverflowVarint,
+		New(ULEB128(&x)).Decode([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02}),
+	)
+	require.NoError(t, New(ULEB128(&x)).Decode(
+		[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x81, 0x00},
+	))
+	require.Equal(t, uint64(math.MaxUint64), x)
+}
+
+func TestSLEB128(t *testing.T) {
+	check := func(x int64, expected []byte) {
+		enc := New(SLEB128Canonical(&x))
+		b := enc.Encode()
+		require.Equal(t, expected, b)
+		x2 := x
+		x = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x2, x)
+	}
+	// Examples from the DWARF specification.
+	check(2, []byte{2})
+	check(-2, []byte{0x7e})
+	check(127, []byte{127 + 0x80, 0})
+	check(-127, []byte{1 + 0x80, 0x7f})
+	check(128, []byte{0 + 0x80, 1})
+	check(-128, []byte{0 + 0x80, 0x7f})
+	check(129, []byte{1 + 0x80, 1})
+	check(-129, []byte{0x7f + 0x80, 0x7e})
+	check(math.MaxInt64, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00})
+	check(math.MinInt64, []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x7F})
+
+	trand.RandomN(t, 10000, func(t *testing.T, r *rand.Rand) {
+		x := int64(r.Uint64()) >> uint(r.Intn(64))
+		check(x, New(SLEB128(&x)).Encode())
+	})
+
+	var x int64
+	padded := []byte{0xFF, 0xFF, 0x7F}
+	require.Equal(t, ErrNonCanonical, New(SLEB128Canonical(&x)).Decode(padded))
+	enc := New(SLEB128(&x))
+	require.NoError(t, enc.Decode(padded))
+	require.Equal(t, int64(-1), x)
+	require.Equal(t, padded, enc.Encode())
+
+	require.Equal(
+		t,
+		ErrOverflowVarint,
+		New(SLEB128(&x)).Decode([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}),
+	)
+}
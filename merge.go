@@ -0,0 +1,153 @@
+package encode
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	ErrUnsorted      = errors.New("encode: merge input is not sorted")
+	ErrFrameTooLarge = errors.New("encode: frame is larger than the maximum frame size")
+)
+
+const (
+	// The largest frame FrameReader will read, to avoid huge allocations from a corrupted length.
+	maxFrameSize = 1 << 30
+	// Frames longer than this are read in pieces, so that a corrupted length can only cause as
+	// large an allocation as there is actually data.
+	frameReadChunk = 64 << 10
+)
+
+// Write b to w prefixed with its uvarint length, which is the framing read by FrameReader and
+// Merge.
+func WriteFrame(w io.Writer, b []byte) error {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+	_, err := w.Write(lenBuf[:n])
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Reads frames written by WriteFrame.
+type FrameReader struct {
+	r *bufio.Reader
+}
+
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: bufio.NewReader(r)}
+}
+
+// Returns the next frame. Returns io.EOF when r ends between frames, io.ErrUnexpectedEOF if it
+// ends in the middle of one, and ErrFrameTooLarge if a frame's length is over 1GiB.
+func (f *FrameReader) Next() ([]byte, error) {
+	l, err := binary.ReadUvarint(f.r)
+	if err != nil {
+		return nil, err
+	}
+	if l > maxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+	if l <= frameReadChunk {
+		b := make([]byte, l)
+		_, err = io.ReadFull(f.r, b)
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return b, err
+	}
+	var b bytes.Buffer
+	b.Grow(frameReadChunk)
+	_, err = io.CopyN(&b, f.r, int64(l))
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Merge the frames from each of rs, which must already be in sorted order, and write them to w as a
+// single sorted stream of frames. Since Tuple encodings are order-preserving, this is the merge step
+// of an external sort or compaction over encoded keys. Frames that compare equal are written in the
+// order of the readers they came from.
+//
+// Returns ErrUnsorted if any of rs is out of order.
+func Merge(w io.Writer, rs ...io.Reader) error {
+	h := make(mergeHeap, 0, len(rs))
+	for i, r := range rs {
+		c := &mergeCursor{r: NewFrameReader(r), i: i}
+		ok, err := c.advance()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+
+	for len(h) > 0 {
+		c := h[0]
+		err := WriteFrame(w, c.head)
+		if err != nil {
+			return err
+		}
+		ok, err := c.advance()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return nil
+}
+
+type mergeCursor struct {
+	r    *FrameReader
+	i    int
+	head []byte
+}
+
+// Moves to the next frame, returning false at the end of the stream.
+func (c *mergeCursor) advance() (bool, error) {
+	b, err := c.r.Next()
+	if err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if c.head != nil && bytes.Compare(b, c.head) < 0 {
+		return false, ErrUnsorted
+	}
+	c.head = b
+	return true, nil
+}
+
+type mergeHeap []*mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	c := bytes.Compare(h[i].head, h[j].head)
+	if c == 0 {
+		return h[i].i < h[j].i
+	}
+	return c < 0
+}
+func (h mergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(*mergeCursor)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	trand.RandomN(t, 100, func(t *testing.T, r *rand.Rand) {
+		var all [][]byte
+		readers := make([]io.Reader, r.Intn(6))
+		for i := range readers {
+			keys := make([][]byte, r.Intn(50))
+			for j := range keys {
+				x := r.Uint64() >> uint(r.Intn(64))
+				keys[j] = New(OrdUvarint64(&x)).Encode()
+			}
+			SortEncoded(keys)
+			all = append(all, keys...)
+
+			var buf bytes.Buffer
+			for _, key := range keys {
+				require.NoError(t, WriteFrame(&buf, key))
+			}
+			readers[i] = &buf
+		}
+		sort.Sort(EncodedSlice(all))
+
+		var out bytes.Buffer
+		require.NoError(t, Merge(&out, readers...))
+
+		fr := NewFrameReader(&out)
+		var merged [][]byte
+		for {
+			b, err := fr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			merged = append(merged, b)
+		}
+		require.Equal(t, len(all), len(merged))
+		for i := range all {
+			require.Equal(t, all[i], merged[i])
+		}
+	})
+}
+
+func TestMergeErrors(t *testing.T) {
+	var unsorted bytes.Buffer
+	require.NoError(t, WriteFrame(&unsorted, []byte{0x02}))
+	require.NoError(t, WriteFrame(&unsorted, []byte{0x01}))
+	require.Equal(t, ErrUnsorted, Merge(io.Discard, &unsorted))
+
+	truncated := bytes.NewReader([]byte{0x03, 0x01})
+	require.Equal(t, io.ErrUnexpectedEOF, Merge(io.Discard, truncated))
+
+	// A corrupted length is caught before allocating for it.
+	huge := bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x40})
+	require.Equal(t, ErrFrameTooLarge, Merge(io.Discard, huge))
+	long := bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x01, 0x01, 0x02})
+	require.Equal(t, io.ErrUnexpectedEOF, Merge(io.Discard, long))
+
+	var big bytes.Buffer
+	frame := bytes.Repeat([]byte{0x07}, 3*frameReadChunk+1)
+	require.NoError(t, WriteFrame(&big, frame))
+	b, err := NewFrameReader(&big).Next()
+	require.NoError(t, err)
+	require.Equal(t, frame, b)
+}
@@ -0,0 +1,121 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+var ErrChecksumMismatch = errors.New("encode: checksum mismatch")
+
+// Wraps an Item to add behavior, such as Sensitive, PreDecode, or a Transformer, in the same way
+// that HTTP middleware wraps a handler.
+type Middleware func(item Item) Item
+
+// Wraps item in each of middleware, with the first outermost. That is, Chain(item, a, b) is
+// a(b(item)), so a sees the result of b when encoding and decodes first.
+func Chain(item Item, middleware ...Middleware) Item {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		item = middleware[i](item)
+	}
+	return item
+}
+
+// A reversible transformation of bytes, such as compression, encryption, or adding a checksum. Use
+// Transform to apply one to an Item.
+type Transformer interface {
+	// Returns the transformed form of b. Must not modify b.
+	Transform(b []byte) []byte
+	// Reverses Transform. Returns an error if b is not something Transform could have returned.
+	// Must not modify b.
+	Untransform(b []byte) ([]byte, error)
+}
+
+// Returns a Middleware that transforms the encoding of the item it wraps with t. The result is
+// encoded as the uvarint length of the transformed bytes followed by the transformed bytes, so
+// Transformers don't need to record their own length.
+//
+// Finding the Size requires encoding and transforming the item, and the result is reused by Encode
+// when the item's encoding hasn't changed in between, so that expensive transformations only run
+// once.
+func Transform(t Transformer) Middleware {
+	return func(item Item) Item {
+		return transformed{t: t, item: item, cache: &transformCache{}}
+	}
+}
+
+type transformed struct {
+	t     Transformer
+	item  Item
+	cache *transformCache
+}
+
+type transformCache struct {
+	plain []byte
+	out   []byte
+}
+
+func (e transformed) transform() []byte {
+	plain := make([]byte, e.item.Size())
+	e.item.Encode(plain)
+	if e.cache.out == nil || !bytes.Equal(plain, e.cache.plain) {
+		e.cache.plain = plain
+		e.cache.out = e.t.Transform(plain)
+	}
+	return e.cache.out
+}
+func (e transformed) Encode(buf []byte) {
+	out := e.transform()
+	n := binary.PutUvarint(buf, uint64(len(out)))
+	copy(buf[n:], out)
+}
+func (e transformed) Size() int {
+	out := e.transform()
+	return uvarintSize(uint64(len(out))) + len(out)
+}
+func (e transformed) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	plain, err := e.t.Untransform(b)
+	if err != nil {
+		return err
+	}
+	err = e.item.Decode(plain)
+	if err != nil {
+		return err
+	}
+	// So that Size is right for what was just decoded without having to transform again.
+	e.cache.plain = plain
+	e.cache.out = append([]byte(nil), b...)
+	return nil
+}
+
+// Returns a Transformer that appends a CRC-32C checksum to the bytes, and on the way back checks
+// it and returns ErrChecksumMismatch if it's wrong.
+func CRC32C() Transformer {
+	return crc32cTransformer{}
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+type crc32cTransformer struct{}
+
+func (crc32cTransformer) Transform(b []byte) []byte {
+	out := make([]byte, len(b)+4)
+	copy(out, b)
+	binary.BigEndian.PutUint32(out[len(b):], crc32.Checksum(b, castagnoli))
+	return out
+}
+func (crc32cTransformer) Untransform(b []byte) ([]byte, error) {
+	if len(b) < 4 {
+		return nil, ErrChecksumMismatch
+	}
+	data := b[:len(b)-4]
+	if crc32.Checksum(data, castagnoli) != binary.BigEndian.Uint32(b[len(data):]) {
+		return nil, ErrChecksumMismatch
+	}
+	return data, nil
+}
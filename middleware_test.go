@@ -0,0 +1,83 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Counts calls to Transform.
+type countingTransformer struct {
+	Transformer
+	n *int
+}
+
+func (t countingTransformer) Transform(b []byte) []byte {
+	*t.n++
+	return t.Transformer.Transform(b)
+}
+
+// Reverses the bytes, so that the order of middleware is visible.
+type reverseTransformer struct{}
+
+func (reverseTransformer) Transform(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+func (t reverseTransformer) Untransform(b []byte) ([]byte, error) {
+	return t.Transform(b), nil
+}
+
+func TestChain(t *testing.T) {
+	var s string
+	var order []string
+	record := func(name string) Middleware {
+		return func(item Item) Item {
+			return PreDecode(item, func([]byte) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+	}
+	item := Chain(LengthDelimString(&s), record("a"), record("b"), Sensitive)
+	require.Equal(t, redacted, item.(hookedItem).item.(hookedItem).item.(sensitive).String())
+
+	s = "hello"
+	b := New(item).Encode()
+	s = ""
+	require.NoError(t, New(item).Decode(b))
+	require.Equal(t, "hello", s)
+	require.Equal(t, []string{"a", "b"}, order)
+}
+
+func TestTransform(t *testing.T) {
+	var s string
+	n := 0
+	item := Chain(
+		LengthDelimString(&s),
+		Transform(countingTransformer{CRC32C(), &n}),
+		Transform(reverseTransformer{}),
+	)
+	enc := New(item)
+
+	s = "hello"
+	b := enc.Encode()
+	// Transformed once, despite Size and Encode both needing it.
+	require.Equal(t, 1, n)
+	require.Equal(t, 1+1+(1+5+4), len(b))
+	// The CRC is applied to the reversed bytes.
+	require.Equal(t, []byte("olleh\x05"), b[2:8])
+
+	s = ""
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, "hello", s)
+	require.Equal(t, b, enc.Encode())
+	require.Equal(t, 1, n)
+
+	b[3] ^= 0x01
+	require.Equal(t, ErrChecksumMismatch, enc.Decode(b))
+	require.NoError(t, New(item).Check())
+}
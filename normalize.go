@@ -0,0 +1,71 @@
+package encode
+
+import "golang.org/x/text/unicode/norm"
+
+// Returns a Middleware that puts *v in the Unicode normalization form f before the item it wraps is
+// encoded, and after it's decoded, so that logically equal strings always produce the same bytes
+// for deduplication and key matching. Usually f is norm.NFC, or norm.NFKC to also fold
+// compatibility characters such as ligatures and full-width forms.
+//
+// *v is normalized in place. The wrapped item must encode from and decode into v. If the wrapped
+// item is a TupleItem, so is the result, and it can be used in Tuples through NewTupleChecked.
+func Normalize(v *string, f norm.Form) Middleware {
+	return func(item Item) Item {
+		n := normalized{v: v, f: f, item: item}
+		if tupleItem, ok := item.(TupleItem); ok {
+			return normalizedTuple{normalized: n, tupleItem: tupleItem}
+		}
+		return n
+	}
+}
+
+type normalized struct {
+	v    *string
+	f    norm.Form
+	item Item
+}
+
+func (e normalized) normalize() {
+	if !e.f.IsNormalString(*e.v) {
+		*e.v = e.f.String(*e.v)
+	}
+}
+func (e normalized) Encode(buf []byte) {
+	e.normalize()
+	e.item.Encode(buf)
+}
+func (e normalized) Size() int {
+	e.normalize()
+	return e.item.Size()
+}
+func (e normalized) Decode(buf []byte) error {
+	err := e.item.Decode(buf)
+	if err != nil {
+		return err
+	}
+	e.normalize()
+	return nil
+}
+
+type normalizedTuple struct {
+	normalized
+	tupleItem TupleItem
+}
+
+func (e normalizedTuple) OrderPreserving() {}
+func (e normalizedTuple) EncodeTuple(buf []byte, last bool) {
+	e.normalize()
+	e.tupleItem.EncodeTuple(buf, last)
+}
+func (e normalizedTuple) SizeTuple(last bool) int {
+	e.normalize()
+	return e.tupleItem.SizeTuple(last)
+}
+func (e normalizedTuple) DecodeTuple(buf []byte, last bool) error {
+	err := e.tupleItem.DecodeTuple(buf, last)
+	if err != nil {
+		return err
+	}
+	e.normalize()
+	return nil
+}
@@ -0,0 +1,34 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalize(t *testing.T) {
+	composed := "café"
+	decomposed := "café"
+
+	var s string
+	enc := New(Chain(LengthDelimString(&s), Normalize(&s, norm.NFC)))
+	s = decomposed
+	b1 := enc.Encode()
+	s = composed
+	b2 := enc.Encode()
+	require.Equal(t, b1, b2)
+	require.Equal(t, "\x05"+composed, string(b1))
+
+	s = ""
+	require.NoError(t, New(LengthDelimString(&s)).Decode([]byte("\x06"+decomposed)))
+	require.Equal(t, decomposed, s)
+	require.NoError(t, enc.Decode([]byte("\x06"+decomposed)))
+	require.Equal(t, composed, s)
+
+	item := Chain(EscapedString(&s), Normalize(&s, norm.NFKC))
+	tup, err := NewTupleChecked(item, item)
+	require.NoError(t, err)
+	s = "ﬁle"
+	require.Equal(t, []byte("file\x00\x00file"), tup.Encode())
+}
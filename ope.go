@@ -0,0 +1,106 @@
+package encode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+)
+
+// Encrypt v with order-preserving encryption under key, producing 8 bytes that sort in the same order
+// as the plaintexts. This lets an encrypted value be part of an order-preserving Tuple key and still
+// support range scans.
+//
+// The ciphertext is the image of v under a keyed, strictly increasing function from 32-bit to 64-bit
+// integers, built by recursively splitting the domain in half and choosing where in the range each
+// half lands with HMAC-SHA256. Decoding walks the same splits, and returns ErrDecryptField for values
+// that aren't the image of any plaintext under key.
+//
+// This is much weaker than Encrypted and should only be used when ordering is a requirement.
+// Anyone who can see ciphertexts learns the order of the plaintexts, which of them are equal, and
+// roughly how far apart they are: for values spread across the domain, about the upper half of
+// each plaintext's bits should be considered revealed. It is not authenticated beyond rejecting
+// values that no plaintext maps to. key should be a secret of at least 32 bytes.
+func EncryptedOrdUint32(v *uint32, key []byte) TupleItem {
+	return encryptedOrdUint32{v: v, key: key}
+}
+
+type encryptedOrdUint32 struct {
+	v   *uint32
+	key []byte
+}
+
+func (e encryptedOrdUint32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e encryptedOrdUint32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e encryptedOrdUint32) SizeTuple(last bool) int                 { return e.Size() }
+func (e encryptedOrdUint32) OrderPreserving()                        {}
+func (e encryptedOrdUint32) Encode(buf []byte) {
+	x := uint64(*e.v)
+	mac := hmac.New(sha256.New, e.key)
+	dlo, dhi := uint64(0), uint64(1<<32-1)
+	rlo, rhi := uint64(0), uint64(1<<64-1)
+	for dlo < dhi {
+		dmid, split := opeSplit(mac, dlo, dhi, rlo, rhi)
+		if x <= dmid {
+			dhi, rhi = dmid, split
+		} else {
+			dlo, rlo = dmid+1, split+1
+		}
+	}
+	binary.BigEndian.PutUint64(buf, opeLeaf(mac, dlo, rlo, rhi))
+}
+func (e encryptedOrdUint32) Size() int {
+	return 8
+}
+func (e encryptedOrdUint32) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	c := binary.BigEndian.Uint64(buf)
+	mac := hmac.New(sha256.New, e.key)
+	dlo, dhi := uint64(0), uint64(1<<32-1)
+	rlo, rhi := uint64(0), uint64(1<<64-1)
+	for dlo < dhi {
+		dmid, split := opeSplit(mac, dlo, dhi, rlo, rhi)
+		if c <= split {
+			dhi, rhi = dmid, split
+		} else {
+			dlo, rlo = dmid+1, split+1
+		}
+	}
+	if c != opeLeaf(mac, dlo, rlo, rhi) {
+		return ErrDecryptField
+	}
+	*e.v = uint32(dlo)
+	return nil
+}
+
+// Splits the domain [dlo, dhi] into [dlo, dmid] and [dmid+1, dhi], and chooses split so that they map
+// into [rlo, split] and [split+1, rhi] respectively. split is chosen pseudorandomly among the values
+// that leave each half of the range at least as large as the corresponding half of the domain.
+func opeSplit(mac hash.Hash, dlo, dhi, rlo, rhi uint64) (dmid uint64, split uint64) {
+	dmid = dlo + (dhi-dlo)/2
+	lo := rlo + (dmid - dlo)
+	hi := rhi - (dhi - dmid)
+	return dmid, lo + opePRF(mac, dlo, dhi)%(hi-lo+1)
+}
+
+// Chooses the ciphertext for plaintext x within [rlo, rhi].
+func opeLeaf(mac hash.Hash, x, rlo, rhi uint64) uint64 {
+	width := rhi - rlo + 1
+	if width == 0 {
+		// The whole 64-bit range, which only happens if the domain has one value.
+		return opePRF(mac, x, x)
+	}
+	return rlo + opePRF(mac, x, x)%width
+}
+
+func opePRF(mac hash.Hash, dlo, dhi uint64) uint64 {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], dlo)
+	binary.BigEndian.PutUint64(b[8:], dhi)
+	mac.Reset()
+	mac.Write(b[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
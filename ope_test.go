@@ -0,0 +1,56 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedOrdUint32(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	check := func(x uint32) []byte {
+		enc := New(EncryptedOrdUint32(&x, key))
+		b := enc.Encode()
+		require.Len(t, b, 8)
+		x2 := x
+		x = ^x
+		require.NoError(t, enc.Decode(b))
+		require.Equal(t, x2, x)
+		return b
+	}
+
+	check(0)
+	check(1)
+	check(math.MaxUint32)
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		x1 := r.Uint32() >> uint(r.Intn(32))
+		x2 := r.Uint32() >> uint(r.Intn(32))
+		b1 := check(x1)
+		b2 := check(x2)
+		require.Equal(t, x1 < x2, bytes.Compare(b1, b2) < 0)
+		require.Equal(t, x1 == x2, bytes.Equal(b1, b2))
+
+		// Adjacent plaintexts are still strictly ordered.
+		if x1 < math.MaxUint32 {
+			require.Equal(t, -1, bytes.Compare(b1, check(x1+1)))
+		}
+	})
+
+	// A different key gives a different function, and ciphertexts from one key don't decode under
+	// another.
+	x := uint32(12345)
+	b := New(EncryptedOrdUint32(&x, key)).Encode()
+	require.NotEqual(t, b, New(EncryptedOrdUint32(&x, []byte("other key"))).Encode())
+	require.Equal(t, ErrDecryptField, New(EncryptedOrdUint32(&x, []byte("other key"))).Decode(b))
+
+	b[7]++
+	require.Equal(t, ErrDecryptField, New(EncryptedOrdUint32(&x, key)).Decode(b))
+	require.Equal(t, io.ErrUnexpectedEOF, New(EncryptedOrdUint32(&x, key)).Decode(b[:7]))
+}
@@ -0,0 +1,142 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrBytesTooLong = errors.New("encode: bytes longer than their maximum length")
+
+// Configures an Item made by Bytes or String.
+type Option func(o *options)
+
+type options struct {
+	maxLen     int
+	noCopy     bool
+	validate   func() error
+	setDefault func()
+}
+
+// Limits the value to n bytes. Decoding a longer one returns ErrBytesTooLong or ErrStringTooLong
+// before anything is copied, so that a corrupt or hostile length can't cause a large allocation, and
+// encoding a longer one panics.
+//
+// Panics if n is negative.
+func WithMaxLen(n int) Option {
+	if n < 0 {
+		panic(fmt.Sprintf("invalid n=%d, must be non-negative", n))
+	}
+	return func(o *options) { o.maxLen = n }
+}
+
+// Calls f after the value is decoded, as in PostDecode. If f returns an error, Decode returns it.
+func WithValidation(f func() error) Option {
+	return func(o *options) { o.validate = f }
+}
+
+// Calls set if the buffer ends before the value, as in Default.
+func WithDefault(set func()) Option {
+	return func(o *options) { o.setDefault = set }
+}
+
+// Decodes to a subslice of the decoded buffer instead of a copy, as in LengthDelimBytesNoCopy. Only
+// valid for Bytes.
+func NoCopy() Option {
+	return func(o *options) { o.noCopy = true }
+}
+
+func applyOptions(opts []Option) options {
+	o := options{maxLen: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Wraps item according to the options that apply to any item.
+func (o options) wrap(item Item) Item {
+	if o.validate != nil {
+		item = PostDecode(item, o.validate)
+	}
+	if o.setDefault != nil {
+		item = Default(item, o.setDefault)
+	}
+	return item
+}
+
+// Encode v as in LengthDelimBytes, configured by opts. This is for when any of the options are
+// needed; with none, it is the same as LengthDelimBytes.
+func Bytes(v *[]byte, opts ...Option) Item {
+	o := applyOptions(opts)
+	return o.wrap(optBytes{v: v, maxLen: o.maxLen, noCopy: o.noCopy})
+}
+
+type optBytes struct {
+	v      *[]byte
+	maxLen int
+	noCopy bool
+}
+
+func (e optBytes) Encode(buf []byte) {
+	if e.maxLen >= 0 && len(*e.v) > e.maxLen {
+		panic(fmt.Sprintf("encode: %d bytes is longer than the maximum of %d", len(*e.v), e.maxLen))
+	}
+	lengthDelimBytes{e.v}.Encode(buf)
+}
+func (e optBytes) Size() int {
+	return lengthDelimBytes{e.v}.Size()
+}
+func (e optBytes) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	if e.maxLen >= 0 && len(b) > e.maxLen {
+		return ErrBytesTooLong
+	}
+	if e.noCopy {
+		*e.v = b[:len(b):len(b)]
+	} else {
+		*e.v = make([]byte, len(b))
+		copy(*e.v, b)
+	}
+	return nil
+}
+
+// Encode v as in LengthDelimString, configured by opts. This is for when any of the options are
+// needed; with none, it is the same as LengthDelimString.
+//
+// Panics if given NoCopy.
+func String(v *string, opts ...Option) Item {
+	o := applyOptions(opts)
+	if o.noCopy {
+		panic("encode: NoCopy is only valid for Bytes")
+	}
+	return o.wrap(optString{v: v, maxLen: o.maxLen})
+}
+
+type optString struct {
+	v      *string
+	maxLen int
+}
+
+func (e optString) Encode(buf []byte) {
+	if e.maxLen >= 0 && len(*e.v) > e.maxLen {
+		panic(fmt.Sprintf("encode: %d bytes is longer than the maximum of %d", len(*e.v), e.maxLen))
+	}
+	lengthDelimString{e.v}.Encode(buf)
+}
+func (e optString) Size() int {
+	return lengthDelimString{e.v}.Size()
+}
+func (e optString) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	if e.maxLen >= 0 && len(b) > e.maxLen {
+		return ErrStringTooLong
+	}
+	*e.v = string(b)
+	return nil
+}
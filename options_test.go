@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytesOptions(t *testing.T) {
+	v := []byte("hello")
+	b := New(Bytes(&v)).Encode()
+	require.Equal(t, New(LengthDelimBytes(&v)).Encode(), b)
+
+	var out []byte
+	require.NoError(t, New(Bytes(&out, WithMaxLen(5))).Decode(b))
+	require.Equal(t, v, out)
+	require.Equal(t, ErrBytesTooLong, New(Bytes(&out, WithMaxLen(4))).Decode(b))
+	require.Panics(t, func() { New(Bytes(&v, WithMaxLen(4))).Encode() })
+
+	require.NoError(t, New(Bytes(&out, NoCopy())).Decode(b))
+	require.Equal(t, &b[1], &out[0])
+
+	errBad := errors.New("bad")
+	require.Equal(t, errBad, New(Bytes(&out, WithValidation(func() error { return errBad }))).Decode(b))
+
+	var n uint16
+	enc := New(FixedUint16(&n), Bytes(&out, WithDefault(func() { out = []byte("default") })))
+	require.NoError(t, enc.Decode([]byte{0x00, 0x01}))
+	require.Equal(t, []byte("default"), out)
+	require.Equal(t, io.ErrUnexpectedEOF, New(Bytes(&out)).Decode(b[:3]))
+}
+
+func TestStringOptions(t *testing.T) {
+	v := "hello"
+	b := New(String(&v)).Encode()
+	require.Equal(t, New(LengthDelimString(&v)).Encode(), b)
+
+	var out string
+	require.NoError(t, New(String(&out, WithMaxLen(5))).Decode(b))
+	require.Equal(t, v, out)
+	require.Equal(t, ErrStringTooLong, New(String(&out, WithMaxLen(4))).Decode(b))
+	require.Panics(t, func() { String(&out, NoCopy()) })
+
+	var n uint16
+	enc := New(FixedUint16(&n), String(&out, WithDefault(func() { out = "default" })))
+	require.NoError(t, enc.Decode([]byte{0x00, 0x01}))
+	require.Equal(t, "default", out)
+}
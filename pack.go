@@ -0,0 +1,41 @@
+package encode
+
+// Returns a copy of enc with each run of two or more consecutive Bool items packed into one
+// Bitpacked BitFlags item, so that they take one bit each instead of a byte, rounded up to a whole
+// byte per run. For example, five Bools in a row take one byte rather than five.
+//
+// This changes the encoding, so records written by enc can't be read by enc.PackBools() or vice
+// versa. Use UnpackedBool for bools that must keep their own byte, for example ones that other
+// programs read at a fixed offset; it also separates runs.
+func (enc Encoding) PackBools() Encoding {
+	items := make([]Item, 0, len(enc.items))
+	var run []*bool
+	flush := func() {
+		switch len(run) {
+		case 0:
+		case 1:
+			items = append(items, Bool(run[0]))
+		default:
+			items = append(items, Bitpacked(BitFlags(run...)))
+		}
+		run = nil
+	}
+	for _, item := range enc.items {
+		if b, ok := item.(encBool); ok {
+			run = append(run, b.v)
+			continue
+		}
+		flush()
+		items = append(items, item)
+	}
+	flush()
+	enc.items = items
+	return enc
+}
+
+// Encode v as in Bool, but never packed by PackBools.
+func UnpackedBool(v *bool) TupleItem {
+	return unpackedBool{encBool{v}}
+}
+
+type unpackedBool struct{ encBool }
@@ -0,0 +1,35 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackBools(t *testing.T) {
+	var a, b, c, d, e, f bool
+	var x uint16
+	enc := New(
+		Bool(&a),
+		Bool(&b),
+		Bool(&c),
+		FixedUint16(&x),
+		Bool(&d),
+		UnpackedBool(&e),
+		Bool(&f),
+	)
+	packed := enc.PackBools()
+
+	a, b, c, d, e, f, x = true, false, true, true, true, false, 0xBEEF
+	require.Equal(t, []byte{0x01, 0x00, 0x01, 0xBE, 0xEF, 0x01, 0x01, 0x00}, enc.Encode())
+	buf := packed.Encode()
+	require.Equal(t, []byte{0xA0, 0xBE, 0xEF, 0x01, 0x01, 0x00}, buf)
+
+	a, b, c, d, e, f, x = false, true, false, false, false, true, 0
+	require.NoError(t, packed.Decode(buf))
+	require.Equal(t, []bool{true, false, true, true, true, false}, []bool{a, b, c, d, e, f})
+	require.Equal(t, uint16(0xBEEF), x)
+
+	// The original is left as it was.
+	require.Len(t, enc.Items(), 7)
+}
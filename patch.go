@@ -0,0 +1,94 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidPatch = errors.New("encode: invalid patch")
+
+// Encode diffs as a patch that can be applied with ApplyPatch. Only the index and new encoding of
+// each item are included, so patches are small when few items changed.
+//
+// The patch is a uvarint count of diffs, then for each the uvarint index of the item, followed by
+// the uvarint length of its new encoding and the new encoding itself.
+func MakePatch(diffs []FieldDiff) []byte {
+	size := uvarintSize(uint64(len(diffs)))
+	for _, d := range diffs {
+		size += uvarintSize(uint64(d.Index)) + uvarintSize(uint64(len(d.New))) + len(d.New)
+	}
+	buf := make([]byte, size)
+	i := binary.PutUvarint(buf, uint64(len(diffs)))
+	for _, d := range diffs {
+		i += binary.PutUvarint(buf[i:], uint64(d.Index))
+		i += binary.PutUvarint(buf[i:], uint64(len(d.New)))
+		i += copy(buf[i:], d.New)
+	}
+	return buf
+}
+
+// Decode buf with enc, apply patch as made by MakePatch, and return the new encoding. Afterwards,
+// enc's destinations hold the patched values, or if the patch doesn't apply, the values decoded
+// from buf.
+//
+// The patch is applied by replacing the bytes of each item it names and decoding the result, so a
+// patch is only valid if each new encoding decodes to exactly its length in place of the old one.
+func ApplyPatch(buf []byte, patch []byte, enc Encoding) ([]byte, error) {
+	err := enc.Decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	offsets := enc.itemOffsets(true)
+	values := make([][]byte, len(offsets))
+	for i, o := range offsets {
+		values[i] = buf[o.Offset : o.Offset+o.Size]
+	}
+
+	count, i := binary.Uvarint(patch)
+	if i <= 0 {
+		return nil, ErrInvalidPatch
+	}
+	for ; count > 0; count-- {
+		index, n := binary.Uvarint(patch[i:])
+		if n <= 0 {
+			return nil, ErrInvalidPatch
+		}
+		i += n
+		if index >= uint64(len(enc.items)) {
+			return nil, ErrInvalidPatch
+		}
+		value, n, err := decodeLengthDelim(patch[i:])
+		if err != nil {
+			return nil, ErrInvalidPatch
+		}
+		i += n
+		values[index] = value
+	}
+	if i != len(patch) {
+		return nil, ErrInvalidPatch
+	}
+
+	var patched []byte
+	for _, value := range values {
+		patched = append(patched, value...)
+	}
+	err = enc.Decode(patched)
+	if err == nil {
+		for j, o := range enc.itemOffsets(true) {
+			if o.Size != len(values[j]) {
+				err = ErrInvalidPatch
+				break
+			}
+		}
+	}
+	if err != nil {
+		// Put back the values from buf, which is known to decode, rather than leave some of the
+		// patch applied.
+		restoreErr := enc.Decode(buf)
+		if restoreErr != nil {
+			return nil, restoreErr
+		}
+		return nil, err
+	}
+	return enc.Encode(), nil
+}
@@ -0,0 +1,64 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidPrefixCompression = errors.New("encode: invalid prefix-compressed keys")
+
+// Compresses keys by storing each as the uvarint length of the prefix it shares with the key before
+// it, the uvarint length of the remainder, and then the remainder. This works on any
+// keys, but is most useful when they are sorted, since sorted keys, especially Tuple keys whose
+// leading items repeat, tend to share long prefixes with their neighbors.
+//
+// Decompressing requires starting from the beginning. For random access into a large number of
+// keys, compress them in chunks, or see package block.
+func PrefixCompress(keys [][]byte) []byte {
+	var buf []byte
+	var prev []byte
+	for _, key := range keys {
+		shared := sharedPrefixLen(prev, key)
+		buf = binary.AppendUvarint(buf, uint64(shared))
+		buf = binary.AppendUvarint(buf, uint64(len(key)-shared))
+		buf = append(buf, key[shared:]...)
+		prev = key
+	}
+	return buf
+}
+
+// Returns the keys compressed by PrefixCompress.
+func PrefixDecompress(b []byte) ([][]byte, error) {
+	var keys [][]byte
+	var prev []byte
+	for len(b) > 0 {
+		shared, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, ErrInvalidPrefixCompression
+		}
+		b = b[n:]
+		suffix, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, ErrInvalidPrefixCompression
+		}
+		b = b[n:]
+		if shared > uint64(len(prev)) || suffix > uint64(len(b)) {
+			return nil, ErrInvalidPrefixCompression
+		}
+		key := make([]byte, int(shared)+int(suffix))
+		copy(key, prev[:shared])
+		copy(key[shared:], b[:suffix])
+		b = b[suffix:]
+		keys = append(keys, key)
+		prev = key
+	}
+	return keys, nil
+}
+
+func sharedPrefixLen(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package encode
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixCompress(t *testing.T) {
+	keys := [][]byte{
+		[]byte("apple"),
+		[]byte("applesauce"),
+		[]byte("applet"),
+		[]byte("banana"),
+		[]byte(""),
+		[]byte("b"),
+	}
+	b := PrefixCompress(keys)
+	require.Equal(t, []byte{
+		0, 5, 'a', 'p', 'p', 'l', 'e',
+		5, 5, 's', 'a', 'u', 'c', 'e',
+		5, 1, 't',
+		0, 6, 'b', 'a', 'n', 'a', 'n', 'a',
+		0, 0,
+		0, 1, 'b',
+	}, b)
+	actual, err := PrefixDecompress(b)
+	require.NoError(t, err)
+	require.Equal(t, keys, actual)
+
+	trand.RandomN(t, 100, func(t *testing.T, r *rand.Rand) {
+		keys := make([][]byte, r.Intn(100))
+		for i := range keys {
+			x := r.Uint64() >> uint(r.Intn(64))
+			s := string(make([]byte, r.Intn(3)))
+			keys[i] = NewTuple(EscapedString(&s), OrdUvarint64(&x)).Encode()
+		}
+		SortEncoded(keys)
+		actual, err := PrefixDecompress(PrefixCompress(keys))
+		require.NoError(t, err)
+		if len(keys) == 0 {
+			require.Empty(t, actual)
+		} else {
+			require.Equal(t, keys, actual)
+		}
+	})
+
+	for _, b := range [][]byte{
+		{0x80},
+		{1, 0},
+		{0, 2, 'a'},
+		{0, 1, 'a', 2, 0},
+	} {
+		_, err := PrefixDecompress(b)
+		require.Equal(t, ErrInvalidPrefixCompression, err)
+	}
+}
@@ -0,0 +1,95 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var ErrInvalidRat = errors.New("encode: invalid or non-canonical rational")
+
+// Encode v exactly as its numerator and denominator. big.Rat keeps itself in lowest terms with a
+// positive denominator, so equal values always encode the same way. Decoding rejects anything else
+// with ErrInvalidRat, so that decoding and re-encoding gives back the same bytes.
+//
+// The numerator is a uvarint of the length of its big endian magnitude shifted left by one, with
+// the low bit set if it is negative, followed by the magnitude. The denominator is a uvarint of its
+// length followed by its magnitude.
+func Rat(v *big.Rat) Item {
+	return encRat{v}
+}
+
+type encRat struct{ v *big.Rat }
+
+func (e encRat) Encode(buf []byte) {
+	e.encodeScratch(buf, new(scratch))
+}
+func (e encRat) encodeScratch(buf []byte, s *scratch) {
+	num := e.v.Num()
+	denom := e.v.Denom()
+	numLen := byteLen(num)
+	header := uint64(numLen) << 1
+	if num.Sign() < 0 {
+		header |= 1
+	}
+	i := binary.PutUvarint(buf, header)
+	s.n.Abs(num).FillBytes(buf[i : i+numLen])
+	i += numLen
+	denomLen := byteLen(denom)
+	i += binary.PutUvarint(buf[i:], uint64(denomLen))
+	denom.FillBytes(buf[i : i+denomLen])
+}
+func (e encRat) Size() int {
+	numLen := byteLen(e.v.Num())
+	denomLen := byteLen(e.v.Denom())
+	return uvarintSize(uint64(numLen)<<1) + numLen + uvarintSize(uint64(denomLen)) + denomLen
+}
+func (e encRat) Decode(buf []byte) error {
+	header, i := binary.Uvarint(buf)
+	if i == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if i < 0 {
+		return ErrOverflowVarint
+	}
+	numLen := header >> 1
+	negative := header&1 == 1
+	if uint64(len(buf[i:])) < numLen {
+		return io.ErrUnexpectedEOF
+	}
+	numBytes := buf[i : i+int(numLen)]
+	i += int(numLen)
+	denomBytes, _, err := decodeLengthDelim(buf[i:])
+	if err != nil {
+		return err
+	}
+
+	// Leading zeroes, negative zero, zero denominators, and fractions not in lowest terms all have
+	// a shorter or more canonical encoding.
+	if (len(numBytes) > 0 && numBytes[0] == 0) || len(denomBytes) == 0 || denomBytes[0] == 0 {
+		return ErrInvalidRat
+	}
+	if negative && len(numBytes) == 0 {
+		return ErrInvalidRat
+	}
+	num := new(big.Int).SetBytes(numBytes)
+	denom := new(big.Int).SetBytes(denomBytes)
+	if num.Sign() == 0 {
+		if denom.Cmp(big.NewInt(1)) != 0 {
+			return ErrInvalidRat
+		}
+	} else if new(big.Int).GCD(nil, nil, num, denom).Cmp(big.NewInt(1)) != 0 {
+		return ErrInvalidRat
+	}
+	if negative {
+		num.Neg(num)
+	}
+	e.v.SetFrac(num, denom)
+	return nil
+}
+
+// The number of bytes in the big endian magnitude of x.
+func byteLen(x *big.Int) int {
+	return (x.BitLen() + 7) / 8
+}
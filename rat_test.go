@@ -0,0 +1,44 @@
+package encode
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRat(t *testing.T) {
+	check := func(x *big.Rat, expected []byte) {
+		enc := New(Rat(x))
+		b := enc.Encode()
+		require.Equal(t, expected, b)
+
+		y := new(big.Rat)
+		require.NoError(t, New(Rat(y)).Decode(b))
+		require.Equal(t, 0, x.Cmp(y))
+		require.Equal(t, b, New(Rat(y)).Encode())
+	}
+
+	check(big.NewRat(0, 1), []byte{0x00, 0x01, 0x01})
+	check(big.NewRat(1, 3), []byte{0x02, 0x01, 0x01, 0x03})
+	check(big.NewRat(-2, 4), []byte{0x03, 0x01, 0x01, 0x02})
+	check(big.NewRat(300, 7), []byte{0x04, 0x01, 0x2C, 0x01, 0x07})
+
+	huge, ok := new(big.Rat).SetString("123456789012345678901234567890/7")
+	require.True(t, ok)
+	enc := New(Rat(huge))
+	b := enc.Encode()
+	y := new(big.Rat)
+	require.NoError(t, New(Rat(y)).Decode(b))
+	require.Equal(t, 0, huge.Cmp(y))
+
+	for _, invalid := range [][]byte{
+		{0x02, 0x02, 0x01, 0x04},       // 2/4, not in lowest terms
+		{0x02, 0x01, 0x00},             // zero denominator
+		{0x04, 0x00, 0x01, 0x01, 0x03}, // leading zero
+		{0x01, 0x01, 0x01},             // negative zero
+		{0x00, 0x01, 0x02},             // 0/2
+	} {
+		require.Equal(t, ErrInvalidRat, New(Rat(y)).Decode(invalid), "%x", invalid)
+	}
+}
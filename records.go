@@ -0,0 +1,104 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Encode the elements of *v as a uvarint count followed by each element encoded with the Encoding
+// returned by encoding, one after another. This is for payloads that are an array of messages, so
+// that the message's own Encoding can be reused rather than writing an Item for it.
+//
+// Decoding replaces *v with a slice of the decoded elements. Since elements are not individually
+// delimited, encoding must be self-delimiting, as every Encoding that doesn't end with Rest is.
+// Elements that decode from no bytes, such as those of an empty Encoding, are only accepted while
+// there are at most as many left as there are bytes remaining, and otherwise decoding returns
+// io.ErrUnexpectedEOF.
+//
+// Records can be appended to an existing encoding with AppendRecord.
+func Records[T any](v *[]T, encoding func(elem *T) Encoding) Item {
+	return records[T]{v: v, encoding: encoding}
+}
+
+type records[T any] struct {
+	v        *[]T
+	encoding func(elem *T) Encoding
+}
+
+func (e records[T]) Encode(buf []byte) {
+	i := binary.PutUvarint(buf, uint64(len(*e.v)))
+	for j := range *e.v {
+		enc := e.encoding(&(*e.v)[j])
+		size := enc.size()
+		enc.encodeTo(buf[i : i+size])
+		i += size
+	}
+}
+func (e records[T]) Size() int {
+	size := uvarintSize(uint64(len(*e.v)))
+	for j := range *e.v {
+		size += e.encoding(&(*e.v)[j]).size()
+	}
+	return size
+}
+func (e records[T]) Decode(buf []byte) error {
+	count, i := binary.Uvarint(buf)
+	if i == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if i < 0 {
+		return ErrOverflowVarint
+	}
+	// Not preallocated, since count hasn't been checked against the size of buf and elements may be
+	// empty.
+	var elems []T
+	for j := uint64(0); j < count; j++ {
+		var zero T
+		elems = append(elems, zero)
+		n, err := e.encoding(&elems[len(elems)-1]).decode(buf[i:])
+		if err != nil {
+			return err
+		}
+		if n == 0 && count-j > uint64(len(buf)-i) {
+			// The rest will be empty too, since they're decoded from the same bytes. Bound them by
+			// what's left of buf, as if they took a byte each, so that a corrupted count can't keep
+			// this looping and allocating.
+			return io.ErrUnexpectedEOF
+		}
+		i += n
+	}
+	*e.v = elems
+	return nil
+}
+
+// Appends rec to buf, which must be empty or hold the encoding of a Records, without decoding the
+// records already there. This allows building up a Records encoding as a stream of records arrives.
+//
+// The count at the front of buf is rewritten in place, so buf only needs to be copied when the count
+// grows to need another byte.
+func AppendRecord(buf []byte, rec Encoding) ([]byte, error) {
+	var count uint64
+	n := 0
+	if len(buf) > 0 {
+		count, n = binary.Uvarint(buf)
+		if n == 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if n < 0 {
+			return nil, ErrOverflowVarint
+		}
+	}
+	count++
+	newN := uvarintSize(count)
+	size := rec.size()
+	if newN != n {
+		out := make([]byte, newN+len(buf)-n, newN+len(buf)-n+size)
+		copy(out[newN:], buf[n:])
+		buf = out
+	}
+	binary.PutUvarint(buf, count)
+	start := len(buf)
+	buf = append(buf, make([]byte, size)...)
+	rec.encodeTo(buf[start:])
+	return buf, nil
+}
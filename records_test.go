@@ -0,0 +1,55 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordsMsg struct {
+	id   uint64
+	name string
+}
+
+func (m *recordsMsg) encoding() Encoding {
+	return New(Uvarint64(&m.id), LengthDelimString(&m.name))
+}
+
+func TestRecords(t *testing.T) {
+	msgs := []recordsMsg{{id: 1, name: "a"}, {id: 300, name: "bc"}}
+	enc := New(Records(&msgs, (*recordsMsg).encoding))
+	b := enc.Encode()
+	require.Equal(t, []byte("\x02\x01\x01a\xac\x02\x02bc"), b)
+
+	msgs = nil
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, []recordsMsg{{id: 1, name: "a"}, {id: 300, name: "bc"}}, msgs)
+
+	require.Error(t, enc.Decode(b[:len(b)-1]))
+
+	var streamed []byte
+	var err error
+	var expected []recordsMsg
+	for i := 0; i < 200; i++ {
+		m := recordsMsg{id: uint64(i), name: "x"}
+		expected = append(expected, m)
+		streamed, err = AppendRecord(streamed, m.encoding())
+		require.NoError(t, err)
+	}
+	require.Equal(t, New(Records(&expected, (*recordsMsg).encoding)).Encode(), streamed)
+	require.NoError(t, enc.Decode(streamed))
+	require.Equal(t, expected, msgs)
+
+	// A huge count of empty elements is rejected rather than looping.
+	type empty struct{}
+	var empties []empty
+	emptyEnc := New(Records(&empties, func(*empty) Encoding { return New() }))
+	huge := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F}
+	require.Equal(t, io.ErrUnexpectedEOF, emptyEnc.Decode(huge))
+	empties = make([]empty, 3)
+	b = emptyEnc.Encode()
+	require.NoError(t, emptyEnc.Decode(append(b, 0, 0, 0)))
+	require.Equal(t, io.ErrUnexpectedEOF, emptyEnc.Decode(append(b, 0, 0)))
+	require.Len(t, empties, 3)
+}
@@ -0,0 +1,119 @@
+package encode
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownTransformer = errors.New("encode: unknown transformer name")
+	ErrInflatedTooLarge   = errors.New("encode: inflated data is larger than the maximum size")
+)
+
+// The most that Flate will inflate to, so that a small, highly compressed input can't exhaust
+// memory.
+const maxInflatedSize = 1 << 30
+
+var (
+	transformersMu sync.RWMutex
+	transformers   = map[string]Transformer{}
+)
+
+func init() {
+	RegisterTransformer("crc32c", CRC32C())
+	RegisterTransformer("flate", Flate(flate.DefaultCompression))
+}
+
+// Records t under name, so that pipelines of Transformers can be assembled at runtime from
+// configuration by name with ParsePipeline. "crc32c" and "flate" are registered already. Other
+// packages can register additional codecs, for example zstd, in an init function.
+//
+// Panics if name is already registered.
+func RegisterTransformer(name string, t Transformer) {
+	if name == "" {
+		panic("encode: RegisterTransformer requires a non-empty name")
+	}
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	if _, ok := transformers[name]; ok {
+		panic(fmt.Sprintf("encode: transformer %q already registered", name))
+	}
+	transformers[name] = t
+}
+
+// Returns the Transformer registered under name, or ErrUnknownTransformer.
+func LookupTransformer(name string) (Transformer, error) {
+	transformersMu.RLock()
+	defer transformersMu.RUnlock()
+	t, ok := transformers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTransformer, name)
+	}
+	return t, nil
+}
+
+// Returns a Middleware that applies the registered Transformers named in spec, a comma-separated
+// list such as "compression=flate, checksum=crc32c". Each entry is either a name or key=name, where
+// the key is only for the benefit of human readers. The Transformers are applied to the encoding
+// in the order listed, so in the example the encoding is compressed and then the compressed bytes
+// are checksummed. An empty spec applies no Transformers.
+func ParsePipeline(spec string) (Middleware, error) {
+	var middleware []Middleware
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if i := strings.IndexByte(entry, '='); i >= 0 {
+			entry = strings.TrimSpace(entry[i+1:])
+		}
+		t, err := LookupTransformer(entry)
+		if err != nil {
+			return nil, err
+		}
+		// Chain puts the first outermost, but the first listed should be applied first.
+		middleware = append([]Middleware{Transform(t)}, middleware...)
+	}
+	return func(item Item) Item {
+		return Chain(item, middleware...)
+	}, nil
+}
+
+// Returns a Transformer that compresses with DEFLATE at the given level, as in compress/flate.
+// Untransform returns ErrInflatedTooLarge for anything that inflates to more than 1GiB.
+// Panics if level is invalid.
+func Flate(level int) Transformer {
+	_, err := flate.NewWriter(io.Discard, level)
+	if err != nil {
+		panic(err)
+	}
+	return flateTransformer{level: level, max: maxInflatedSize}
+}
+
+type flateTransformer struct {
+	level int
+	max   int64
+}
+
+func (t flateTransformer) Transform(b []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, t.level)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes()
+}
+func (t flateTransformer) Untransform(b []byte) ([]byte, error) {
+	out, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(b)), t.max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > t.max {
+		return nil, ErrInflatedTooLarge
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package encode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipeline(t *testing.T) {
+	pipeline, err := ParsePipeline("compression=flate, checksum=crc32c")
+	require.NoError(t, err)
+
+	var s string
+	enc := New(pipeline(LengthDelimString(&s)))
+	s = strings.Repeat("abc", 1000)
+	b := enc.Encode()
+	require.Less(t, len(b), 100)
+
+	// Checksummed outermost, so corruption is caught before decompressing.
+	b[len(b)-6] ^= 0x01
+	require.Equal(t, ErrChecksumMismatch, enc.Decode(b))
+	b[len(b)-6] ^= 0x01
+	s = ""
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, strings.Repeat("abc", 1000), s)
+
+	expected := New(Chain(LengthDelimString(&s), Transform(CRC32C()), Transform(Flate(-1)))).Encode()
+	require.Equal(t, expected, b)
+
+	empty, err := ParsePipeline("")
+	require.NoError(t, err)
+	require.Equal(t, New(LengthDelimString(&s)).Encode(), New(empty(LengthDelimString(&s))).Encode())
+
+	_, err = ParsePipeline("compression=zstd")
+	require.True(t, errors.Is(err, ErrUnknownTransformer))
+
+	require.Panics(t, func() { RegisterTransformer("crc32c", CRC32C()) })
+	RegisterTransformer("test-reverse", reverseTransformer{})
+	tr, err := LookupTransformer("test-reverse")
+	require.NoError(t, err)
+	require.Equal(t, reverseTransformer{}, tr)
+}
+
+func TestFlateLimit(t *testing.T) {
+	f := flateTransformer{level: -1, max: 3000}
+	b := f.Transform([]byte(strings.Repeat("abc", 1000)))
+	out, err := f.Untransform(b)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("abc", 1000), string(out))
+
+	b = f.Transform([]byte(strings.Repeat("abc", 1001)))
+	_, err = f.Untransform(b)
+	require.Equal(t, ErrInflatedTooLarge, err)
+}
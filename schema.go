@@ -0,0 +1,191 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrIncompatibleSchema = errors.New("encode: incompatible schema change")
+
+// A description of the byte layout of an Encoding, for checking that changes to it are compatible
+// with data that has already been written. See NewSchema and AssertCompatible.
+type Schema struct {
+	Fields []SchemaField
+}
+
+// One item of a Schema.
+type SchemaField struct {
+	// The name given to NewSchema, or empty.
+	Name string
+	// A description of the item's layout, made from the kind of item and its parameters, such as
+	// widths and delimiters, but not from its value. Two items with the same Layout encode the same
+	// values the same way.
+	Layout string
+}
+
+// Returns the Schema of enc. names, if given, must have one name for each of enc's items, and are used
+// by AssertCompatible to tell when fields have been moved.
+//
+// The layouts are found by inspecting the items' parameters with reflection, so NewSchema is meant
+// to be called at startup or in tests rather than on every encode.
+func NewSchema(enc Encoding, names ...string) Schema {
+	if len(names) != 0 && len(names) != len(enc.items) {
+		panic(fmt.Sprintf("encode: got %d names for %d items", len(names), len(enc.items)))
+	}
+	s := Schema{Fields: make([]SchemaField, len(enc.items))}
+	for i, item := range enc.items {
+		if len(names) != 0 {
+			s.Fields[i].Name = names[i]
+		}
+		s.Fields[i].Layout = describeLayout(reflect.ValueOf(item))
+	}
+	return s
+}
+
+// Returns nil if data written with old can be read with new. Otherwise, returns an error wrapping
+// ErrIncompatibleSchema for each field of old that was removed, moved, or had its layout changed.
+// Adding fields to the end is compatible, as long as older data is decoded with Default for them.
+//
+// Meant to be used in tests, against a Schema of the Encoding as it was when data was first
+// persisted, to catch breaking changes before they reach production.
+func AssertCompatible(old, new Schema) error {
+	newIndex := make(map[string]int, len(new.Fields))
+	for i, f := range new.Fields {
+		if f.Name != "" {
+			newIndex[f.Name] = i
+		}
+	}
+	var errs []error
+	for i, f := range old.Fields {
+		if j, ok := newIndex[f.Name]; ok && j != i {
+			errs = append(errs, fmt.Errorf("%w: field %s moved from %d to %d", ErrIncompatibleSchema, f.describe(i), i, j))
+			continue
+		}
+		if i >= len(new.Fields) {
+			errs = append(errs, fmt.Errorf("%w: field %s removed", ErrIncompatibleSchema, f.describe(i)))
+			continue
+		}
+		if f.Layout != new.Fields[i].Layout {
+			errs = append(errs, fmt.Errorf(
+				"%w: field %s changed from %s to %s",
+				ErrIncompatibleSchema, f.describe(i), f.Layout, new.Fields[i].Layout,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f SchemaField) describe(i int) string {
+	if f.Name != "" {
+		return strconv.Quote(f.Name)
+	}
+	return strconv.Itoa(i)
+}
+
+// Returns a 64-bit hash of the layouts of s's fields, which is stable across processes and versions
+// of this package as long as the layouts are. Names are left out since they don't affect the
+// encoding.
+//
+// Producers can stamp records with the fingerprint, for example with FixedUint64, so that consumers
+// can tell which layout a record was written with and pick the Encoding to decode it with.
+func (s Schema) Fingerprint() uint64 {
+	var b []byte
+	for _, f := range s.Fields {
+		b = append(b, f.Layout...)
+		b = append(b, 0)
+	}
+	return Hash64(b)
+}
+
+var (
+	layoutsMu sync.RWMutex
+	layouts   = map[string]map[int]uint64{}
+)
+
+// Records that version of the record called name is encoded with enc, and panics if enc's layout
+// doesn't have the given fingerprint. This is meant to be called at startup, for example from init,
+// with a fingerprint copied from the panic message the first time, so that any later change to enc's
+// layout fails immediately rather than silently breaking what's already been written. A changed
+// layout needs a new version.
+//
+// Also panics if name and version are already registered with a different fingerprint.
+func RegisterLayout(name string, version int, enc Encoding, fingerprint uint64) {
+	actual := NewSchema(enc).Fingerprint()
+	if actual != fingerprint {
+		panic(fmt.Sprintf(
+			"encode: layout of %s version %d has fingerprint %#016x, expected %#016x",
+			name, version, actual, fingerprint,
+		))
+	}
+
+	layoutsMu.Lock()
+	defer layoutsMu.Unlock()
+	versions, ok := layouts[name]
+	if !ok {
+		versions = make(map[int]uint64)
+		layouts[name] = versions
+	}
+	if existing, ok := versions[version]; ok && existing != fingerprint {
+		panic(fmt.Sprintf("encode: %s version %d already registered with fingerprint %#016x", name, version, existing))
+	}
+	versions[version] = fingerprint
+}
+
+// Returns the fingerprint registered for version of name by RegisterLayout, and whether there was
+// one.
+func LayoutFingerprint(name string, version int) (uint64, bool) {
+	layoutsMu.RLock()
+	defer layoutsMu.RUnlock()
+	fingerprint, ok := layouts[name][version]
+	return fingerprint, ok
+}
+
+var reflectValueType = reflect.TypeOf(reflect.Value{})
+
+// Describes the layout of an item from its type and the non-pointer values inside of it. Pointers
+// are where items keep the values they encode, so they're skipped, as are funcs.
+func describeLayout(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return describeLayout(v.Elem())
+	case reflect.Struct:
+		if v.Type() == reflectValueType {
+			return ""
+		}
+		var parts []string
+		for i := 0; i < v.NumField(); i++ {
+			d := describeLayout(v.Field(i))
+			if d != "" {
+				parts = append(parts, d)
+			}
+		}
+		if len(parts) == 0 {
+			return v.Type().Name()
+		}
+		return v.Type().Name() + "(" + strings.Join(parts, ",") + ")"
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = describeLayout(v.Index(i))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return ""
+}
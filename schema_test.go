@@ -0,0 +1,78 @@
+package encode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	var a uint16
+	var b string
+	var c, d byte
+	var e uint64
+
+	s := NewSchema(New(FixedUint16(&a), LengthDelimString(&b), Bitpacked(Bits8(&c, 3), Bits8(&d, 5))))
+	require.Equal(t, []SchemaField{
+		{Layout: "fixedUint16"},
+		{Layout: "lengthDelimString"},
+		{Layout: "bitpacked([bits8(3),bits8(5)])"},
+	}, s.Fields)
+
+	v1 := NewSchema(New(FixedUint16(&a), LengthDelimString(&b)), "a", "b")
+
+	require.NoError(t, AssertCompatible(v1, v1))
+	require.NoError(t, AssertCompatible(v1, NewSchema(
+		New(FixedUint16(&a), LengthDelimString(&b), Uvarint64(&e)),
+		"a", "b", "e",
+	)))
+	// Renaming without changing the layout is fine.
+	require.NoError(t, AssertCompatible(v1, NewSchema(New(FixedUint16(&a), LengthDelimString(&b)), "a", "name")))
+
+	err := AssertCompatible(v1, NewSchema(New(LengthDelimString(&b), FixedUint16(&a)), "b", "a"))
+	require.True(t, errors.Is(err, ErrIncompatibleSchema))
+	require.Contains(t, err.Error(), `field "a" moved from 0 to 1`)
+	require.Contains(t, err.Error(), `field "b" moved from 1 to 0`)
+
+	err = AssertCompatible(v1, NewSchema(New(FixedUint32(new(uint32)), LengthDelimString(&b)), "a", "b"))
+	require.True(t, errors.Is(err, ErrIncompatibleSchema))
+	require.Contains(t, err.Error(), `field "a" changed from fixedUint16 to fixedUint32`)
+
+	err = AssertCompatible(v1, NewSchema(New(FixedUint16(&a)), "a"))
+	require.True(t, errors.Is(err, ErrIncompatibleSchema))
+	require.Contains(t, err.Error(), `field "b" removed`)
+
+	require.Panics(t, func() { NewSchema(New(FixedUint16(&a)), "a", "b") })
+}
+
+func TestRegisterLayout(t *testing.T) {
+	var a uint16
+	var b string
+	enc := New(FixedUint16(&a), LengthDelimString(&b))
+	fingerprint := NewSchema(enc).Fingerprint()
+
+	RegisterLayout("TestRegisterLayout", 1, enc, fingerprint)
+	RegisterLayout("TestRegisterLayout", 1, enc, fingerprint)
+	got, ok := LayoutFingerprint("TestRegisterLayout", 1)
+	require.True(t, ok)
+	require.Equal(t, fingerprint, got)
+	_, ok = LayoutFingerprint("TestRegisterLayout", 2)
+	require.False(t, ok)
+
+	changed := New(FixedUint32(new(uint32)), LengthDelimString(&b))
+	require.Panics(t, func() { RegisterLayout("TestRegisterLayout", 1, changed, fingerprint) })
+	require.Panics(t, func() {
+		RegisterLayout("TestRegisterLayout", 1, changed, NewSchema(changed).Fingerprint())
+	})
+	RegisterLayout("TestRegisterLayout", 2, changed, NewSchema(changed).Fingerprint())
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	var a uint16
+	var b string
+	s := NewSchema(New(FixedUint16(&a), LengthDelimString(&b)))
+	require.Equal(t, Hash64([]byte("fixedUint16\x00lengthDelimString\x00")), s.Fingerprint())
+	require.Equal(t, s.Fingerprint(), NewSchema(New(FixedUint16(&a), LengthDelimString(&b)), "a", "b").Fingerprint())
+	require.NotEqual(t, s.Fingerprint(), NewSchema(New(LengthDelimString(&b), FixedUint16(&a))).Fingerprint())
+}
@@ -0,0 +1,49 @@
+package encode
+
+// A type that owns its own byte format, in the same way as encoding.BinaryMarshaler but without
+// allocating. Use Self to include one in an Encoding.
+type SelfEncodable interface {
+	// Encode into buf, which will be at least EncodedSize() bytes.
+	EncodeSelf(buf []byte)
+	// Decode from the front of buf, which may be longer than the encoding.
+	DecodeSelf(buf []byte) error
+	// The number of bytes that EncodeSelf will use.
+	EncodedSize() int
+}
+
+// A SelfEncodable whose encoding sorts in the same order as its values, and is self-delimiting, so
+// that it can be used in a Tuple.
+type OrderedSelfEncodable interface {
+	SelfEncodable
+	OrderPreserving()
+}
+
+// Encode v with its own methods. If v is an OrderedSelfEncodable, the result is a TupleItem and can
+// be used in Tuples through NewTupleChecked.
+//
+// v must be a pointer, or otherwise able to modify itself in DecodeSelf.
+func Self(v SelfEncodable) Item {
+	if _, ok := v.(OrderedSelfEncodable); ok {
+		return selfTupleItem{selfItem{v}}
+	}
+	return selfItem{v}
+}
+
+type selfItem struct{ v SelfEncodable }
+
+func (e selfItem) Encode(buf []byte) {
+	e.v.EncodeSelf(buf)
+}
+func (e selfItem) Size() int {
+	return e.v.EncodedSize()
+}
+func (e selfItem) Decode(buf []byte) error {
+	return e.v.DecodeSelf(buf)
+}
+
+type selfTupleItem struct{ selfItem }
+
+func (e selfTupleItem) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e selfTupleItem) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e selfTupleItem) SizeTuple(last bool) int                 { return e.Size() }
+func (e selfTupleItem) OrderPreserving()                        {}
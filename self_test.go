@@ -0,0 +1,63 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A calendar date, encoded as a 2-byte year followed by a byte each of month and day.
+type selfDate struct {
+	year       uint16
+	month, day byte
+}
+
+func (d *selfDate) EncodeSelf(buf []byte) {
+	buf[0] = byte(d.year >> 8)
+	buf[1] = byte(d.year)
+	buf[2] = d.month
+	buf[3] = d.day
+}
+func (d *selfDate) DecodeSelf(buf []byte) error {
+	if len(buf) < 4 {
+		return io.ErrUnexpectedEOF
+	}
+	d.year = uint16(buf[0])<<8 | uint16(buf[1])
+	d.month = buf[2]
+	d.day = buf[3]
+	return nil
+}
+func (d *selfDate) EncodedSize() int { return 4 }
+func (d *selfDate) OrderPreserving() {}
+
+type selfBlob struct{ b []byte }
+
+func (s *selfBlob) EncodeSelf(buf []byte) { LengthDelimBytes(&s.b).Encode(buf) }
+func (s *selfBlob) DecodeSelf(buf []byte) error {
+	return LengthDelimBytes(&s.b).Decode(buf)
+}
+func (s *selfBlob) EncodedSize() int { return LengthDelimBytes(&s.b).Size() }
+
+func TestSelf(t *testing.T) {
+	d := selfDate{year: 2024, month: 2, day: 29}
+	blob := selfBlob{b: []byte("xy")}
+	enc := New(Self(&d), Self(&blob))
+	b := enc.Encode()
+	require.Equal(t, []byte{0x07, 0xE8, 0x02, 0x1D, 0x02, 'x', 'y'}, b)
+
+	d, blob = selfDate{}, selfBlob{}
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, selfDate{year: 2024, month: 2, day: 29}, d)
+	require.Equal(t, []byte("xy"), blob.b)
+
+	_, err := NewTupleChecked(Self(&blob))
+	require.ErrorIs(t, err, ErrNotOrderPreserving)
+
+	tup, err := NewTupleChecked(Self(&d))
+	require.NoError(t, err)
+	k1 := tup.Encode()
+	d.month = 3
+	require.True(t, bytes.Compare(k1, tup.Encode()) < 0)
+}
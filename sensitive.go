@@ -0,0 +1,26 @@
+package encode
+
+// Encodes and decodes exactly like item, but marks it as holding a secret, such as a credential or
+// personal information. Diagnostic output produced by this package, namely FieldDiff's String,
+// Encoding.Dump, and the messages of InvalidError and FieldError, shows the value as redacted, and
+// formatting the Item itself with the fmt package does not print the value it points to.
+func Sensitive(item Item) Item {
+	return sensitive{item}
+}
+
+type sensitive struct{ item Item }
+
+func (e sensitive) Encode(buf []byte)       { e.item.Encode(buf) }
+func (e sensitive) Decode(buf []byte) error { return e.item.Decode(buf) }
+func (e sensitive) Size() int               { return e.item.Size() }
+func (e sensitive) String() string          { return redacted }
+func (e sensitive) GoString() string        { return redacted }
+func (e sensitive) unwrap() Item            { return e.item }
+
+const redacted = "<redacted>"
+
+// Returns whether item is Sensitive, or wraps an item that is.
+func isSensitive(item Item) bool {
+	_, ok := findItem[sensitive](item)
+	return ok
+}
@@ -0,0 +1,85 @@
+package encode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitive(t *testing.T) {
+	var id uint16
+	var token string
+	enc := New(FixedUint16(&id), Sensitive(LengthDelimString(&token)))
+
+	id, token = 1, "hunter2"
+	before := enc.Encode()
+	id, token = 2, "correct horse"
+	after := enc.Encode()
+	require.Equal(t, "correct horse", string(after[len(after)-len(token):]))
+
+	id, token = 0, ""
+	require.NoError(t, enc.Decode(after))
+	require.Equal(t, "correct horse", token)
+
+	diffs, err := Diff(before, after, enc)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	require.False(t, diffs[0].Sensitive)
+	require.True(t, diffs[1].Sensitive)
+
+	require.Equal(t, "item 0: 0001 -> 0002", diffs[0].String())
+	for _, s := range []string{
+		diffs[1].String(),
+		fmt.Sprintf("%v", diffs),
+		fmt.Sprintf("%+v", diffs),
+		fmt.Sprintf("%#v", diffs),
+		fmt.Sprintf("%v %#v", enc.items[1], enc.items[1]),
+	} {
+		require.NotContains(t, s, "hunter2")
+		require.NotContains(t, s, fmt.Sprintf("%x", "hunter2"))
+		require.NotContains(t, s, "correct horse")
+		require.NotContains(t, s, fmt.Sprintf("%x", "correct horse"))
+		require.Contains(t, s, redacted)
+	}
+
+	// Also when Sensitive is wrapped in something else.
+	noop := func() error { return nil }
+	hooked := New(FixedUint16(&id), PostDecode(Sensitive(LengthDelimString(&token)), noop))
+	diffs, err = Diff(before, after, hooked)
+	require.NoError(t, err)
+	require.True(t, diffs[1].Sensitive)
+	require.NotContains(t, diffs[1].String(), "correct horse")
+
+	dump := enc.Dump(after)
+	require.Contains(t, dump, "item 0 [0, 2): 0002")
+	require.Contains(t, dump, redacted)
+	require.NotContains(t, dump, fmt.Sprintf("%x", "correct horse"))
+	// Once decoding fails, the rest may include the Sensitive item's bytes.
+	dump = enc.Dump(after[:len(after)-1])
+	require.Contains(t, dump, "item 1 failed")
+	require.NotContains(t, dump, fmt.Sprintf("%x", "correct horse"[:5]))
+
+	// Patches still carry the value, since they're needed to reproduce the change.
+	patched, err := ApplyPatch(before, MakePatch(diffs), enc)
+	require.NoError(t, err)
+	require.Equal(t, after, patched)
+}
+
+func TestSensitiveErrors(t *testing.T) {
+	var id uint16
+	var flag bool
+	enc := New(FixedUint16(&id), Sensitive(Bool(&flag)))
+	buf := []byte{0x00, 0x01, 0x02}
+
+	err := enc.WithDetailedErrors().Decode(buf)
+	require.ErrorIs(t, err, ErrInvalidBool)
+	require.Equal(t, "encode: item 1 at offset 2: "+redacted, err.Error())
+
+	err = enc.DecodeTolerant(buf)
+	require.ErrorIs(t, err, ErrInvalidBool)
+	require.Equal(t, "encode: item 1 at offset 2: "+redacted, err.Error())
+
+	err = New(FixedUint16(&id), Bool(&flag)).WithDetailedErrors().Decode(buf)
+	require.Equal(t, "encode: item 1 at offset 2: "+ErrInvalidBool.Error(), err.Error())
+}
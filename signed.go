@@ -0,0 +1,129 @@
+package encode
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var ErrInvalidSignature = errors.New("encode: invalid signature")
+
+// Produces and checks signatures for Signed.
+type Signer interface {
+	// Returns the signature of msg, which must be SignatureSize() bytes.
+	Sign(msg []byte) []byte
+	// Returns whether sig is a valid signature of msg.
+	Verify(msg []byte, sig []byte) bool
+	SignatureSize() int
+}
+
+// Returns a Signer that uses HMAC-SHA256 with the given secret key.
+func HMACSHA256(key []byte) Signer {
+	return hmacSHA256{key}
+}
+
+type hmacSHA256 struct{ key []byte }
+
+func (s hmacSHA256) Sign(msg []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+func (s hmacSHA256) Verify(msg []byte, sig []byte) bool {
+	return hmac.Equal(s.Sign(msg), sig)
+}
+func (s hmacSHA256) SignatureSize() int {
+	return sha256.Size
+}
+
+// Returns a Signer that uses Ed25519. priv may be nil if only verifying, in which case Sign panics.
+func Ed25519(pub ed25519.PublicKey, priv ed25519.PrivateKey) Signer {
+	return ed25519Signer{pub: pub, priv: priv}
+}
+
+type ed25519Signer struct {
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func (s ed25519Signer) Sign(msg []byte) []byte {
+	if s.priv == nil {
+		panic("encode: Ed25519 Signer has no private key")
+	}
+	return ed25519.Sign(s.priv, msg)
+}
+func (s ed25519Signer) Verify(msg []byte, sig []byte) bool {
+	return ed25519.Verify(s.pub, msg, sig)
+}
+func (s ed25519Signer) SignatureSize() int {
+	return ed25519.SignatureSize
+}
+
+// Encode items one after another, as Encoding does, followed by a signature by s over them, to make
+// the result tamper-evident.
+//
+// The encoding is the uvarint length of the encoded items, then the items, then the signature, which
+// covers the length and the items. Decoding checks the signature before decoding any of the items,
+// and returns ErrInvalidSignature without modifying them if it doesn't match, and returns
+// ErrLengthMismatch if the items don't decode to exactly the signed length.
+func Signed(s Signer, items ...Item) Item {
+	return signed{s: s, items: items}
+}
+
+type signed struct {
+	s     Signer
+	items []Item
+}
+
+func (e signed) contentSize() int {
+	size := 0
+	for _, item := range e.items {
+		size += item.Size()
+	}
+	return size
+}
+func (e signed) Encode(buf []byte) {
+	contentSize := e.contentSize()
+	n := binary.PutUvarint(buf, uint64(contentSize))
+	for _, item := range e.items {
+		size := item.Size()
+		item.Encode(buf[n : n+size : n+size])
+		n += size
+	}
+	copy(buf[n:], e.s.Sign(buf[:n]))
+}
+func (e signed) Size() int {
+	contentSize := e.contentSize()
+	return uvarintSize(uint64(contentSize)) + contentSize + e.s.SignatureSize()
+}
+func (e signed) Decode(buf []byte) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return ErrOverflowVarint
+	}
+	if n != uvarintSize(l) {
+		// Size is the minimal length, so accepting padding would leave the next item misaligned.
+		return ErrNonCanonical
+	}
+	if uint64(len(buf)-n) < l || len(buf)-n-int(l) < e.s.SignatureSize() {
+		return io.ErrUnexpectedEOF
+	}
+	end := n + int(l)
+	if !e.s.Verify(buf[:end], buf[end:end+e.s.SignatureSize()]) {
+		return ErrInvalidSignature
+	}
+	consumed, err := New(e.items...).decode(buf[n:end])
+	if err != nil {
+		return err
+	}
+	if consumed != int(l) {
+		return ErrLengthMismatch
+	}
+	return nil
+}
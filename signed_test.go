@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"crypto/ed25519"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigned(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		signer Signer
+		wrong  Signer
+	}{
+		{"HMACSHA256", HMACSHA256([]byte("secret")), HMACSHA256([]byte("other secret"))},
+		{"Ed25519", Ed25519(pub, priv), Ed25519(otherPub, nil)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var user string
+			var expiry uint64
+			var after bool
+			enc := New(Signed(tc.signer, LengthDelimString(&user), FixedUint64(&expiry)), Bool(&after))
+
+			user, expiry, after = "alice", 1700000000, true
+			b := enc.Encode()
+			require.Len(t, b, 1+1+5+8+tc.signer.SignatureSize()+1)
+
+			user, expiry, after = "", 0, false
+			require.NoError(t, enc.Decode(b))
+			require.Equal(t, "alice", user)
+			require.Equal(t, uint64(1700000000), expiry)
+			require.True(t, after)
+
+			// Verify-only is enough to decode.
+			if tc.name == "Ed25519" {
+				verifier := Ed25519(pub, nil)
+				err := New(Signed(verifier, LengthDelimString(&user), FixedUint64(&expiry))).Decode(b)
+				require.NoError(t, err)
+			}
+
+			user, expiry = "", 0
+			err := New(Signed(tc.wrong, LengthDelimString(&user), FixedUint64(&expiry))).Decode(b)
+			require.Equal(t, ErrInvalidSignature, err)
+			require.Equal(t, "", user)
+
+			for i := 0; i < len(b)-1; i++ {
+				tampered := append([]byte(nil), b...)
+				tampered[i] ^= 0x04
+				require.Error(t, enc.Decode(tampered))
+			}
+			for i := 0; i < len(b)-1; i++ {
+				require.Equal(t, io.ErrUnexpectedEOF, enc.Decode(b[:i]))
+			}
+		})
+	}
+
+	// A validly signed value with more items than expected doesn't leave the extra bytes to be
+	// decoded as whatever comes after.
+	var x, y, after bool
+	x, y = true, true
+	s := HMACSHA256([]byte("secret"))
+	b := New(Signed(s, Bool(&x), Bool(&y)), Bool(&after)).Encode()
+	require.Equal(t, ErrLengthMismatch, New(Signed(s, Bool(&x)), Bool(&after)).Decode(b))
+
+	require.Panics(t, func() {
+		var x bool
+		New(Signed(Ed25519(pub, nil), Bool(&x))).Encode()
+	})
+}
@@ -0,0 +1,244 @@
+// Package snapshot reads and writes immutable files of key-value records sorted by key, such as
+// Tuple-encoded keys from package encode, with an index that allows looking up a key without reading
+// the whole file.
+//
+// The file is the records, each a uvarint-length-prefixed key followed by a
+// uvarint-length-prefixed value, then the index, then a fixed-size footer:
+//
+//   records
+//   index      for every Nth record: uvarint-length-prefixed key, uvarint offset of the record
+//   footer     8-byte big endian offset of the index, 8-byte big endian number of index entries,
+//              4-byte magic number
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrCorrupt = errors.New("snapshot: corrupt file")
+
+const (
+	magic      = 0x534E4150 // "SNAP"
+	footerSize = 8 + 8 + 4
+	// The largest key or value that will be read back, to avoid huge allocations from a corrupted
+	// length.
+	maxRecordSize = 1 << 30
+)
+
+type indexEntry struct {
+	key    []byte
+	offset uint64
+}
+
+func (e *indexEntry) encoding() encode.Encoding {
+	return encode.New(encode.LengthDelimBytes(&e.key), encode.Uvarint64(&e.offset))
+}
+
+type record struct {
+	key   []byte
+	value []byte
+}
+
+func (r *record) encoding() encode.Encoding {
+	return encode.New(encode.LengthDelimBytes(&r.key), encode.LengthDelimBytes(&r.value))
+}
+
+// Writes a snapshot file.
+type Writer struct {
+	w       *bufio.Writer
+	every   int
+	offset  uint64
+	n       int
+	lastKey []byte
+	index   []indexEntry
+}
+
+// Returns a Writer that writes a snapshot to w, indexing every Nth record. Larger values of every
+// make the index smaller and lookups read more of the file.
+func NewWriter(w io.Writer, every int) *Writer {
+	if every < 1 {
+		panic("snapshot: every must be at least 1")
+	}
+	return &Writer{w: bufio.NewWriter(w), every: every}
+}
+
+// Adds a record. Keys must be added in strictly increasing order, otherwise returns
+// encode.ErrUnsorted.
+func (w *Writer) Add(key []byte, value []byte) error {
+	if w.n > 0 && bytes.Compare(w.lastKey, key) >= 0 {
+		return encode.ErrUnsorted
+	}
+	if w.n%w.every == 0 {
+		w.index = append(w.index, indexEntry{key: append([]byte(nil), key...), offset: w.offset})
+	}
+	r := record{key: key, value: value}
+	b := r.encoding().Encode()
+	_, err := w.w.Write(b)
+	if err != nil {
+		return err
+	}
+	w.offset += uint64(len(b))
+	w.n++
+	w.lastKey = append(w.lastKey[:0], key...)
+	return nil
+}
+
+// Writes the index and footer and flushes. Does not close the underlying writer.
+func (w *Writer) Close() error {
+	indexOffset := w.offset
+	for i := range w.index {
+		_, err := w.w.Write(w.index[i].encoding().Encode())
+		if err != nil {
+			return err
+		}
+	}
+	var footer [footerSize]byte
+	binary.BigEndian.PutUint64(footer[0:], indexOffset)
+	binary.BigEndian.PutUint64(footer[8:], uint64(len(w.index)))
+	binary.BigEndian.PutUint32(footer[16:], magic)
+	_, err := w.w.Write(footer[:])
+	if err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Reads a snapshot file. Only the index is kept in memory.
+type Reader struct {
+	r           io.ReaderAt
+	indexOffset int64
+	index       []indexEntry
+}
+
+// Reads the index of the snapshot in r, which is size bytes long.
+func Open(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < footerSize {
+		return nil, ErrCorrupt
+	}
+	var footer [footerSize]byte
+	_, err := r.ReadAt(footer[:], size-footerSize)
+	if err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(footer[16:]) != magic {
+		return nil, ErrCorrupt
+	}
+	indexOffset := binary.BigEndian.Uint64(footer[0:])
+	n := binary.BigEndian.Uint64(footer[8:])
+	if indexOffset > uint64(size-footerSize) {
+		return nil, ErrCorrupt
+	}
+	indexBytes := make([]byte, uint64(size-footerSize)-indexOffset)
+	_, err = r.ReadAt(indexBytes, int64(indexOffset))
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(bytes.NewReader(indexBytes))
+	var index []indexEntry
+	for i := uint64(0); i < n; i++ {
+		key, err := readLengthDelim(br)
+		if err != nil {
+			return nil, ErrCorrupt
+		}
+		offset, err := binary.ReadUvarint(br)
+		if err != nil || offset > indexOffset {
+			return nil, ErrCorrupt
+		}
+		index = append(index, indexEntry{key: key, offset: offset})
+	}
+	return &Reader{r: r, indexOffset: int64(indexOffset), index: index}, nil
+}
+
+// Returns the value for key, or false if there isn't one. Reads at most one indexed run of records.
+func (r *Reader) Get(key []byte) ([]byte, bool, error) {
+	it := r.Seek(key)
+	k, v, err := it.Next()
+	if err == io.EOF || (err == nil && !bytes.Equal(k, key)) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// Returns an Iterator positioned at the first record with a key at or after key.
+func (r *Reader) Seek(key []byte) *Iterator {
+	// The last indexed record at or before key.
+	i := sort.Search(len(r.index), func(i int) bool {
+		return bytes.Compare(r.index[i].key, key) > 0
+	}) - 1
+	offset := int64(0)
+	if i >= 0 {
+		offset = int64(r.index[i].offset)
+	}
+	it := r.iterAt(offset)
+	it.skipBefore = key
+	return it
+}
+
+// Returns an Iterator over every record, in order.
+func (r *Reader) Iter() *Iterator {
+	return r.iterAt(0)
+}
+
+func (r *Reader) iterAt(offset int64) *Iterator {
+	return &Iterator{
+		r: bufio.NewReader(io.NewSectionReader(r.r, offset, r.indexOffset-offset)),
+	}
+}
+
+// Iterates over records of a snapshot in key order.
+type Iterator struct {
+	r          *bufio.Reader
+	skipBefore []byte
+}
+
+// Returns the next record's key and value. Returns io.EOF after the last one.
+func (it *Iterator) Next() ([]byte, []byte, error) {
+	for {
+		key, err := readLengthDelim(it.r)
+		if err == io.EOF {
+			return nil, nil, io.EOF
+		} else if err != nil {
+			return nil, nil, err
+		}
+		value, err := readLengthDelim(it.r)
+		if err == io.EOF {
+			return nil, nil, ErrCorrupt
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if it.skipBefore != nil {
+			if bytes.Compare(key, it.skipBefore) < 0 {
+				continue
+			}
+			it.skipBefore = nil
+		}
+		return key, value, nil
+	}
+}
+
+func readLengthDelim(r *bufio.Reader) ([]byte, error) {
+	l, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, ErrCorrupt
+	}
+	if l > maxRecordSize {
+		return nil, ErrCorrupt
+	}
+	b := make([]byte, l)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrCorrupt
+	}
+	return b, err
+}
@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bradenaw/encode"
+)
+
+func key(i uint64) []byte {
+	return encode.New(encode.OrdUvarint64(&i)).Encode()
+}
+
+func TestSnapshot(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 16)
+	for i := uint64(0); i < 1000; i += 2 {
+		require.NoError(t, w.Add(key(i), []byte(fmt.Sprintf("value %d", i))))
+	}
+	require.Equal(t, encode.ErrUnsorted, w.Add(key(4), nil))
+	require.NoError(t, w.Close())
+
+	r, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, r.index, 32)
+
+	for i := uint64(0); i < 1002; i++ {
+		v, ok, err := r.Get(key(i))
+		require.NoError(t, err)
+		if i%2 == 0 && i < 1000 {
+			require.True(t, ok)
+			require.Equal(t, fmt.Sprintf("value %d", i), string(v))
+		} else {
+			require.False(t, ok)
+		}
+	}
+
+	it := r.Seek(key(501))
+	k, v, err := it.Next()
+	require.NoError(t, err)
+	require.Equal(t, key(502), k)
+	require.Equal(t, "value 502", string(v))
+
+	n := 0
+	it = r.Iter()
+	for {
+		_, _, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		n++
+	}
+	require.Equal(t, 500, n)
+
+	_, err = Open(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), int64(buf.Len()-1))
+	require.Equal(t, ErrCorrupt, err)
+}
+
+func TestEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewWriter(&buf, 1).Close())
+	r, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	_, ok, err := r.Get([]byte("x"))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,86 @@
+package encode
+
+import "fmt"
+
+// The widths of the parts of a Snowflake ID, most significant first. They must add up to at most 64,
+// and the remaining high bits are zero.
+type SnowflakeLayout struct {
+	TimestampBits int
+	WorkerBits    int
+	SequenceBits  int
+}
+
+// The layout of Twitter's Snowflake IDs: a 41-bit millisecond timestamp, a 10-bit worker ID, and a
+// 12-bit sequence number, leaving the sign bit zero.
+var TwitterSnowflake = SnowflakeLayout{TimestampBits: 41, WorkerBits: 10, SequenceBits: 12}
+
+// The components of a Snowflake ID. What the timestamp counts from and in what units is up to the
+// user.
+type Snowflake struct {
+	Timestamp uint64
+	Worker    uint64
+	Sequence  uint64
+}
+
+func (l SnowflakeLayout) check() {
+	for _, n := range []int{l.TimestampBits, l.WorkerBits, l.SequenceBits} {
+		if n < 0 || n > 64 {
+			panic(fmt.Sprintf("invalid SnowflakeLayout %+v, widths must be in [0, 64]", l))
+		}
+	}
+	if l.TimestampBits+l.WorkerBits+l.SequenceBits > 64 {
+		panic(fmt.Sprintf("invalid SnowflakeLayout %+v, widths must add up to at most 64", l))
+	}
+}
+
+// Encode v as a 64-bit big endian ID laid out according to l, built on Bitpacked. IDs sort by
+// timestamp, then worker, then sequence.
+//
+// Panics if l is invalid. As with the other BitpackItems, bits of the components beyond their width
+// are dropped.
+func (l SnowflakeLayout) Item(v *Snowflake) TupleItem {
+	l.check()
+	items := make([]BitpackItem, 0, 4)
+	if pad := 64 - l.TimestampBits - l.WorkerBits - l.SequenceBits; pad > 0 {
+		items = append(items, BitPadding(pad))
+	}
+	for _, part := range []struct {
+		v *uint64
+		n int
+	}{
+		{&v.Timestamp, l.TimestampBits},
+		{&v.Worker, l.WorkerBits},
+		{&v.Sequence, l.SequenceBits},
+	} {
+		if part.n > 0 {
+			items = append(items, Bits64(part.v, part.n))
+		}
+	}
+	return Bitpacked(items...)
+}
+
+// Returns the ID made of the components of s, the same as the big endian value of l.Item(&s).
+func (l SnowflakeLayout) ID(s Snowflake) uint64 {
+	l.check()
+	return s.Timestamp&mask64(l.TimestampBits)<<(l.WorkerBits+l.SequenceBits) |
+		s.Worker&mask64(l.WorkerBits)<<l.SequenceBits |
+		s.Sequence&mask64(l.SequenceBits)
+}
+
+// Splits id into its components according to l.
+func (l SnowflakeLayout) Split(id uint64) Snowflake {
+	l.check()
+	return Snowflake{
+		Timestamp: id >> (l.WorkerBits + l.SequenceBits) & mask64(l.TimestampBits),
+		Worker:    id >> l.SequenceBits & mask64(l.WorkerBits),
+		Sequence:  id & mask64(l.SequenceBits),
+	}
+}
+
+// Returns a uint64 with the low n bits set.
+func mask64(n int) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<n - 1
+}
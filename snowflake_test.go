@@ -0,0 +1,36 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnowflake(t *testing.T) {
+	id := uint64(288990614469)<<22 | 361<<12 | 7
+	s := TwitterSnowflake.Split(id)
+	require.Equal(t, Snowflake{Timestamp: 288990614469, Worker: 361, Sequence: 7}, s)
+	require.Equal(t, id, TwitterSnowflake.ID(s))
+
+	trand.RandomN(t, 1000, func(t *testing.T, r *rand.Rand) {
+		l := SnowflakeLayout{TimestampBits: 1 + r.Intn(42), WorkerBits: r.Intn(11), SequenceBits: r.Intn(11)}
+		s := Snowflake{Timestamp: r.Uint64(), Worker: r.Uint64(), Sequence: r.Uint64()}
+		id := l.ID(s)
+		s = l.Split(id)
+		require.Equal(t, id, l.ID(s))
+
+		enc := NewTuple(l.Item(&s))
+		b := enc.Encode()
+		require.Len(t, b, 8)
+		require.Equal(t, id, binary.BigEndian.Uint64(b))
+
+		var s2 Snowflake
+		require.NoError(t, NewTuple(l.Item(&s2)).Decode(b))
+		require.Equal(t, s, s2)
+	})
+
+	require.Panics(t, func() { SnowflakeLayout{TimestampBits: 42, WorkerBits: 11, SequenceBits: 12}.Split(0) })
+}
@@ -0,0 +1,82 @@
+package encode
+
+import (
+	"bytes"
+)
+
+// Attaches the methods of sort.Interface to [][]byte, sorting in lexicographic byte order. Since
+// Tuple encodings are order-preserving, this is the same order as the values they encode.
+type EncodedSlice [][]byte
+
+func (s EncodedSlice) Len() int           { return len(s) }
+func (s EncodedSlice) Less(i, j int) bool { return bytes.Compare(s[i], s[j]) < 0 }
+func (s EncodedSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Sort keys in lexicographic byte order, the same order as sort.Sort(EncodedSlice(keys)) but
+// usually much faster for large numbers of keys.
+//
+// This is an American flag sort, an in-place most-significant-digit radix sort. Rather than
+// comparing whole keys, which for Tuple keys often share long prefixes, it distributes keys into
+// buckets by one byte at a time, so each byte of each key is looked at about once.
+func SortEncoded(keys [][]byte) {
+	americanFlagSort(keys, 0)
+}
+
+// Below this many keys, insertion sort is faster than another round of bucketing.
+const americanFlagCutoff = 32
+
+// Sorts keys, all of which share their first depth bytes.
+func americanFlagSort(keys [][]byte, depth int) {
+	if len(keys) < americanFlagCutoff {
+		insertionSortEncoded(keys, depth)
+		return
+	}
+
+	// Bucket 0 holds keys that end at depth, and bucket b+1 holds keys with b at depth.
+	var counts [257]int
+	for _, key := range keys {
+		counts[radixBucket(key, depth)]++
+	}
+	var starts, ends, next [257]int
+	sum := 0
+	for b := range counts {
+		starts[b] = sum
+		sum += counts[b]
+		ends[b] = sum
+	}
+	next = starts
+
+	for b := range counts {
+		for next[b] < ends[b] {
+			other := radixBucket(keys[next[b]], depth)
+			if other == b {
+				next[b]++
+				continue
+			}
+			keys[next[b]], keys[next[other]] = keys[next[other]], keys[next[b]]
+			next[other]++
+		}
+	}
+
+	// Everything in bucket 0 is equal, so it's already sorted.
+	for b := 1; b < len(counts); b++ {
+		if counts[b] > 1 {
+			americanFlagSort(keys[starts[b]:ends[b]], depth+1)
+		}
+	}
+}
+
+func radixBucket(key []byte, depth int) int {
+	if len(key) <= depth {
+		return 0
+	}
+	return int(key[depth]) + 1
+}
+
+func insertionSortEncoded(keys [][]byte, depth int) {
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && bytes.Compare(keys[j][depth:], keys[j-1][depth:]) < 0; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package encode
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/bradenaw/trand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortEncoded(t *testing.T) {
+	trand.RandomN(t, 100, func(t *testing.T, r *rand.Rand) {
+		// Draw from a small alphabet with a shared prefix so that there are lots of equal bytes,
+		// prefixes of other keys, and empty keys.
+		keys := make([][]byte, r.Intn(2000))
+		for i := range keys {
+			key := make([]byte, r.Intn(6))
+			for j := range key {
+				key[j] = byte(r.Intn(4)) * 0x55
+			}
+			if r.Intn(2) == 0 {
+				key = append([]byte{0x01, 0x02}, key...)
+			}
+			keys[i] = key
+		}
+
+		expected := make([][]byte, len(keys))
+		copy(expected, keys)
+		sort.Sort(EncodedSlice(expected))
+
+		SortEncoded(keys)
+		require.Equal(t, expected, keys)
+		require.True(t, sort.SliceIsSorted(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i], keys[j]) < 0
+		}))
+	})
+}
+
+func BenchmarkSortEncoded(b *testing.B) {
+	keys := make([][]byte, 100000)
+	for i := range keys {
+		x := rand.Uint64() >> uint(rand.Intn(64))
+		keys[i] = New(OrdUvarint64(&x)).Encode()
+	}
+	scratch := make([][]byte, len(keys))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(scratch, keys)
+		SortEncoded(scratch)
+	}
+}
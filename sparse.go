@@ -0,0 +1,109 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var ErrUnknownSparseField = errors.New("encode: presence bit set for unknown sparse field")
+
+// A field of SparseStruct, see OptionalField.
+type SparseField struct {
+	v    reflect.Value
+	item Item
+}
+
+// Make a field for SparseStruct. v must be the pointer that item encodes from and decodes into. It's
+// used to check whether the field is set, meaning it isn't its type's zero value, and to reset the
+// field to its zero value when it's absent.
+//
+// If item was made with Default, the field is always encoded, since otherwise a zero value would
+// decode as the default. It's only absent in encodings from a version of the struct that didn't
+// have it.
+func OptionalField(v interface{}, item Item) SparseField {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic(fmt.Sprintf("encode: OptionalField requires a non-nil pointer, got %T", v))
+	}
+	return SparseField{v: rv.Elem(), item: item}
+}
+
+// Whether f is encoded.
+func (f SparseField) present() bool {
+	if _, ok := findItem[defaulter](f.item); ok {
+		return true
+	}
+	return !f.v.IsZero()
+}
+
+// Encodes a presence bitmap with one bit per field, high-order first and padded to the nearest byte,
+// followed by only the fields that are set. Decoding sets each absent field to its zero value, or
+// for fields whose item was made with Default, to its default.
+//
+// This is much smaller than encoding every field for wide structs where most fields are usually
+// zero.
+func SparseStruct(fields ...SparseField) Item {
+	return sparseStruct{fields}
+}
+
+type sparseStruct struct{ fields []SparseField }
+
+func (e sparseStruct) bitmapSize() int {
+	return (len(e.fields) + 7) / 8
+}
+func (e sparseStruct) Encode(buf []byte) {
+	bitmap := bitBuffer{b: buf[:e.bitmapSize()]}
+	i := e.bitmapSize()
+	for _, f := range e.fields {
+		if !f.present() {
+			bitmap.writeBits(0, 1)
+			continue
+		}
+		bitmap.writeBits(1, 1)
+		size := f.item.Size()
+		f.item.Encode(buf[i : i+size : i+size])
+		i += size
+	}
+}
+func (e sparseStruct) Size() int {
+	size := e.bitmapSize()
+	for _, f := range e.fields {
+		if f.present() {
+			size += f.item.Size()
+		}
+	}
+	return size
+}
+func (e sparseStruct) Decode(buf []byte) error {
+	if len(buf) < e.bitmapSize() {
+		return io.ErrUnexpectedEOF
+	}
+	bitmap := bitBuffer{b: buf[:e.bitmapSize()]}
+	i := e.bitmapSize()
+	for _, f := range e.fields {
+		present, _ := bitmap.readBits(1)
+		if present == 0 {
+			if d, ok := findItem[defaulter](f.item); ok {
+				d.setDefault()
+			} else {
+				f.v.Set(reflect.Zero(f.v.Type()))
+			}
+			continue
+		}
+		err := f.item.Decode(buf[i:])
+		if err != nil {
+			return err
+		}
+		i += decodedSize(f.item)
+	}
+	extra := bitmap.lenBits() - bitmap.i
+	if extra > 0 {
+		rest, _ := bitmap.readBits(extra)
+		if rest != 0 {
+			return ErrUnknownSparseField
+		}
+	}
+	return nil
+}
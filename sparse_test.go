@@ -0,0 +1,43 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseStruct(t *testing.T) {
+	var a, b, c uint16
+	var d bool
+	var e [16]byte
+	var f, g, h, i uint64
+	enc := New(SparseStruct(
+		OptionalField(&a, FixedUint16(&a)),
+		OptionalField(&b, FixedUint16(&b)),
+		OptionalField(&c, FixedUint16(&c)),
+		OptionalField(&d, Bool(&d)),
+		OptionalField(&e, Bytes16(&e)),
+		OptionalField(&f, Uvarint64(&f)),
+		OptionalField(&g, Uvarint64(&g)),
+		OptionalField(&h, Uvarint64(&h)),
+		OptionalField(&i, OrdUvarint64(&i)),
+	))
+
+	b = 0x0102
+	d = true
+	i = 5
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x50, 0x80, 0x01, 0x02, 0x01, 0x05}, buf)
+
+	a, c, f = 1, 2, 3
+	b, d, i = 0, false, 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint16(0), a)
+	require.Equal(t, uint16(0x0102), b)
+	require.Equal(t, uint16(0), c)
+	require.True(t, d)
+	require.Equal(t, uint64(0), f)
+	require.Equal(t, uint64(5), i)
+
+	require.Equal(t, ErrUnknownSparseField, enc.Decode([]byte{0x00, 0x01}))
+}
@@ -0,0 +1,140 @@
+package encode
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Counts the work done by Encodings configured with WithStats, or by items wrapped with Item, to
+// track serialization cost per record type. All methods are safe for concurrent use.
+//
+// *Stats implements expvar.Var, so it can be published directly:
+//
+//   var fooStats encode.Stats
+//
+//   func init() {
+//   	expvar.Publish("encode_foo", &fooStats)
+//   }
+//
+// For other metrics systems, read the counters with Snapshot.
+type Stats struct {
+	encodes      atomic.Int64
+	decodes      atomic.Int64
+	decodeErrors atomic.Int64
+	bytesEncoded atomic.Int64
+	bytesDecoded atomic.Int64
+	encodeNanos  atomic.Int64
+	decodeNanos  atomic.Int64
+}
+
+// A point-in-time copy of the counters in a Stats. All are cumulative since the Stats was created.
+type StatsSnapshot struct {
+	Encodes int64
+	Decodes int64
+	// The number of calls to Decode that returned an error, included in Decodes.
+	DecodeErrors int64
+	// The total size of everything encoded, which for Encoding.Encode is also the number of bytes it
+	// allocated for results.
+	BytesEncoded int64
+	// The total size of everything successfully decoded.
+	BytesDecoded int64
+	EncodeTime   time.Duration
+	DecodeTime   time.Duration
+}
+
+func (s *Stats) Snapshot() StatsSnapshot {
+	return StatsSnapshot{
+		Encodes:      s.encodes.Load(),
+		Decodes:      s.decodes.Load(),
+		DecodeErrors: s.decodeErrors.Load(),
+		BytesEncoded: s.bytesEncoded.Load(),
+		BytesDecoded: s.bytesDecoded.Load(),
+		EncodeTime:   time.Duration(s.encodeNanos.Load()),
+		DecodeTime:   time.Duration(s.decodeNanos.Load()),
+	}
+}
+
+// Returns the counters as a JSON object, as expvar.Var requires.
+func (s *Stats) String() string {
+	snap := s.Snapshot()
+	return fmt.Sprintf(
+		`{"encodes": %d, "decodes": %d, "decode_errors": %d, "bytes_encoded": %d, `+
+			`"bytes_decoded": %d, "encode_ns": %d, "decode_ns": %d}`,
+		snap.Encodes,
+		snap.Decodes,
+		snap.DecodeErrors,
+		snap.BytesEncoded,
+		snap.BytesDecoded,
+		int64(snap.EncodeTime),
+		int64(snap.DecodeTime),
+	)
+}
+
+func (s *Stats) recordEncode(start time.Time, n int) {
+	s.encodes.Add(1)
+	s.bytesEncoded.Add(int64(n))
+	s.encodeNanos.Add(int64(time.Since(start)))
+}
+
+func (s *Stats) recordDecode(start time.Time, n int, err error) {
+	s.decodes.Add(1)
+	if err != nil {
+		s.decodeErrors.Add(1)
+	} else {
+		s.bytesDecoded.Add(int64(n))
+	}
+	s.decodeNanos.Add(int64(time.Since(start)))
+}
+
+// Returns a copy of enc that records every call to Encode, Decode, EncodeCtx, and DecodeCtx in s. Many Encodings can
+// share one Stats, for example every Encoding of the same record type.
+func (enc Encoding) WithStats(s *Stats) Encoding {
+	enc.stats = s
+	return enc
+}
+
+// Wraps item to record its own encodes and decodes in s, for finding which items within an
+// Encoding are expensive.
+func (s *Stats) Item(item Item) Item {
+	return statsItem{s: s, item: item}
+}
+
+type statsItem struct {
+	s    *Stats
+	item Item
+}
+
+func (e statsItem) Encode(buf []byte) {
+	start := time.Now()
+	e.item.Encode(buf)
+	e.s.recordEncode(start, e.item.Size())
+}
+func (e statsItem) Size() int {
+	return e.item.Size()
+}
+func (e statsItem) unwrap() Item {
+	return e.item
+}
+
+// Implemented so that an Encoder uses item's scratch encoding if it has one, without skipping the
+// stats.
+func (e statsItem) encodeScratch(buf []byte, s *scratch) {
+	start := time.Now()
+	if se, ok := findItem[scratchEncoder](e.item); ok {
+		se.encodeScratch(buf, s)
+	} else {
+		e.item.Encode(buf)
+	}
+	e.s.recordEncode(start, len(buf))
+}
+func (e statsItem) Decode(buf []byte) error {
+	start := time.Now()
+	err := e.item.Decode(buf)
+	size := 0
+	if err == nil {
+		size = decodedSize(e.item)
+	}
+	e.s.recordDecode(start, size, err)
+	return err
+}
@@ -0,0 +1,43 @@
+package encode
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	var s, itemStats Stats
+	var a uint16
+	var b string
+	enc := New(FixedUint16(&a), itemStats.Item(LengthDelimString(&b))).WithStats(&s)
+
+	a, b = 1, "hello"
+	buf := enc.Encode()
+	enc.Encode()
+	require.NoError(t, enc.Decode(buf))
+	require.Error(t, enc.Decode(buf[:3]))
+
+	snap := s.Snapshot()
+	require.Equal(t, int64(2), snap.Encodes)
+	require.Equal(t, int64(2), snap.Decodes)
+	require.Equal(t, int64(1), snap.DecodeErrors)
+	require.Equal(t, int64(16), snap.BytesEncoded)
+	require.Equal(t, int64(8), snap.BytesDecoded)
+
+	itemSnap := itemStats.Snapshot()
+	require.Equal(t, int64(2), itemSnap.Encodes)
+	require.Equal(t, int64(2), itemSnap.Decodes)
+	require.Equal(t, int64(1), itemSnap.DecodeErrors)
+	require.Equal(t, int64(12), itemSnap.BytesEncoded)
+	require.Equal(t, int64(6), itemSnap.BytesDecoded)
+
+	var _ expvar.Var = &s
+	var m map[string]int64
+	require.NoError(t, json.Unmarshal([]byte(s.String()), &m))
+	require.Equal(t, int64(2), m["encodes"])
+	require.Equal(t, int64(1), m["decode_errors"])
+	require.Equal(t, int64(snap.DecodeTime), m["decode_ns"])
+}
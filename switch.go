@@ -0,0 +1,63 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownCase = errors.New("encode: no case for switch key")
+
+// Encode the item in cases for the current value of *key. This is for a message body whose layout
+// depends on a type field earlier in the header, not necessarily right before the body:
+//
+//   var version, msgType uint8
+//   var requestID uint64
+//   var login LoginRequest
+//   var query QueryRequest
+//   encode.New(
+//   	encode.Byte(&version),
+//   	encode.Byte(&msgType),
+//   	encode.Uvarint64(&requestID),
+//   	encode.Switch(&msgType, map[uint8]encode.Item{
+//   		1: encode.Embed(login.Encoding()),
+//   		2: encode.Embed(query.Encoding()),
+//   	}),
+//   )
+//
+// The item that sets *key must come before the Switch in the same Encoding, so that *key holds the
+// decoded value by the time the Switch is decoded. Decoding returns ErrUnknownCase if there's no case
+// for *key, and encoding panics.
+func Switch[K comparable](key *K, cases map[K]Item) Item {
+	return switchItem[K]{key: key, cases: cases}
+}
+
+type switchItem[K comparable] struct {
+	key   *K
+	cases map[K]Item
+}
+
+func (e switchItem[K]) item() (Item, bool) {
+	item, ok := e.cases[*e.key]
+	return item, ok
+}
+func (e switchItem[K]) mustItem() Item {
+	item, ok := e.item()
+	if !ok {
+		panic(fmt.Sprintf("encode: no case for switch key %v", *e.key))
+	}
+	return item
+}
+
+func (e switchItem[K]) Encode(buf []byte) {
+	e.mustItem().Encode(buf)
+}
+func (e switchItem[K]) Size() int {
+	return e.mustItem().Size()
+}
+func (e switchItem[K]) Decode(buf []byte) error {
+	item, ok := e.item()
+	if !ok {
+		return ErrUnknownCase
+	}
+	return item.Decode(buf)
+}
@@ -0,0 +1,45 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwitch(t *testing.T) {
+	var (
+		msgType   uint8
+		requestID uint64
+		name      string
+		count     uint32
+	)
+	enc := New(
+		Byte(&msgType),
+		Uvarint64(&requestID),
+		Switch(&msgType, map[uint8]Item{
+			1: LengthDelimString(&name),
+			2: FixedUint32(&count),
+		}),
+	)
+
+	msgType, requestID, name = 1, 300, "login"
+	b := enc.Encode()
+	require.Equal(t, append([]byte{0x01, 0xAC, 0x02}, New(LengthDelimString(&name)).Encode()...), b)
+	msgType, requestID, name = 0, 0, ""
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, uint8(1), msgType)
+	require.Equal(t, uint64(300), requestID)
+	require.Equal(t, "login", name)
+
+	msgType, count = 2, 0xDEADBEEF
+	b = enc.Encode()
+	require.Equal(t, []byte{0x02, 0xAC, 0x02, 0xDE, 0xAD, 0xBE, 0xEF}, b)
+	count = 0
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, uint32(0xDEADBEEF), count)
+
+	b[0] = 3
+	require.Equal(t, ErrUnknownCase, enc.Decode(b))
+	msgType = 3
+	require.Panics(t, func() { enc.Encode() })
+}
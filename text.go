@@ -0,0 +1,48 @@
+package encode
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+)
+
+// A type with a text form, such as net/netip.Addr, time.Time, or many enums.
+type TextMarshaler interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+// Encode v as a uvarint of the length of its text form, followed by the text form, so that types
+// that already have one can be included without writing an Item for them.
+//
+// Encoding calls v's MarshalText method twice, once to find the size and once to write it, and
+// panics if it returns an error.
+func Text(v TextMarshaler) Item {
+	return textItem{v}
+}
+
+type textItem struct{ v TextMarshaler }
+
+func (e textItem) text() []byte {
+	b, err := e.v.MarshalText()
+	if err != nil {
+		panic(fmt.Sprintf("encode: MarshalText of %T: %s", e.v, err))
+	}
+	return b
+}
+func (e textItem) Encode(buf []byte) {
+	b := e.text()
+	n := binary.PutUvarint(buf, uint64(len(b)))
+	copy(buf[n:], b)
+}
+func (e textItem) Size() int {
+	l := len(e.text())
+	return uvarintSize(uint64(l)) + l
+}
+func (e textItem) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	return e.v.UnmarshalText(b)
+}
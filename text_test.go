@@ -0,0 +1,24 @@
+package encode
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestText(t *testing.T) {
+	addr := netip.MustParseAddr("192.0.2.1")
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	enc := New(Text(&addr), Text(&ts))
+	b := enc.Encode()
+	require.Equal(t, "\x09192.0.2.1\x142024-01-02T03:04:05Z", string(b))
+
+	addr, ts = netip.Addr{}, time.Time{}
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, netip.MustParseAddr("192.0.2.1"), addr)
+	require.True(t, ts.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	require.Error(t, New(Text(&addr)).Decode([]byte("\x03abc")))
+}
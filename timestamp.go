@@ -0,0 +1,36 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Encode v in 8 bytes such that later times sort first, so that an ascending scan visits the most
+// recent entries first. The encoding is MaxUint64 minus the nanoseconds since the Unix epoch, with
+// the sign bit flipped so that times before 1970 also sort correctly.
+//
+// Decoding restores the time to the nanosecond, in the local time zone as with time.Unix.
+func ReverseTimestamp(v *time.Time) TupleItem {
+	return reverseTimestamp{v}
+}
+
+type reverseTimestamp struct{ v *time.Time }
+
+func (e reverseTimestamp) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e reverseTimestamp) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e reverseTimestamp) SizeTuple(last bool) int                 { return e.Size() }
+func (e reverseTimestamp) OrderPreserving()                        {}
+func (e reverseTimestamp) Encode(buf []byte) {
+	binary.BigEndian.PutUint64(buf, ^(uint64(e.v.UnixNano()) ^ (1 << 63)))
+}
+func (e reverseTimestamp) Size() int {
+	return 8
+}
+func (e reverseTimestamp) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	*e.v = time.Unix(0, int64(^binary.BigEndian.Uint64(buf)^(1<<63)))
+	return nil
+}
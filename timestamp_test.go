@@ -0,0 +1,36 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTimestamp(t *testing.T) {
+	times := []time.Time{
+		time.Unix(-100, 0),
+		time.Unix(0, 0),
+		time.Unix(1700000000, 1),
+		time.Unix(1700000000, 2),
+		time.Unix(1800000000, 0),
+	}
+	var prev []byte
+	for _, ts := range times {
+		v := ts
+		b := NewTuple(ReverseTimestamp(&v)).Encode()
+		if prev != nil {
+			require.True(t, bytes.Compare(b, prev) < 0)
+		}
+		prev = b
+
+		v = time.Time{}
+		require.NoError(t, NewTuple(ReverseTimestamp(&v)).Decode(b))
+		require.True(t, v.Equal(ts))
+	}
+
+	var v time.Time
+	require.Equal(t, io.ErrUnexpectedEOF, New(ReverseTimestamp(&v)).Decode(prev[:7]))
+}
@@ -0,0 +1,190 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var (
+	ErrInvalidTLV   = errors.New("encode: TLV value not fully consumed by its field")
+	ErrDuplicateTag = errors.New("encode: TLV tag appears more than once")
+)
+
+// A field of TLV, see TagField.
+type TLVField struct {
+	tag  uint64
+	v    reflect.Value
+	item Item
+}
+
+// Make a field for TLV with the given tag. As with OptionalField, v must be the pointer that item
+// encodes from and decodes into, and the field is left out of the encoding when it's the zero value
+// of its type, unless item was made with Default.
+func TagField(tag uint64, v interface{}, item Item) TLVField {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic(fmt.Sprintf("encode: TagField requires a non-nil pointer, got %T", v))
+	}
+	return TLVField{tag: tag, v: rv.Elem(), item: item}
+}
+
+// One entry of a TLV whose tag doesn't belong to any of its fields.
+type TLVEntry struct {
+	Tag   uint64
+	Value []byte
+}
+
+// Encodes a sequence of (tag, length, value) entries, one for each field that is set, in the order
+// given, followed by the entries in *unknown. Tags and lengths are uvarints, and the whole thing is
+// prefixed with its uvarint length.
+//
+// Decoding dispatches each entry to the field with the matching tag, in any order, and sets fields
+// that don't appear to their zero value or, for fields whose item was made with Default, to their
+// default. Entries with tags that don't match any field are appended to *unknown, so that they're
+// written back out when re-encoding. This lets a program that only understands some of the tags
+// pass the rest through unharmed, as in extensible formats like EMV. unknown may be nil if the
+// caller has no use for them, in which case they're kept internally and still written back.
+//
+// So that decoding and re-encoding gives the same length, which is what the Encoding around a TLV
+// uses to find the next item, a field whose entry was present when decoding is encoded even if it's
+// the zero value, until the next Decode. This means a TLV must not be used concurrently.
+//
+// Returns ErrInvalidTLV if a field's item doesn't decode to exactly the length of its entry, and
+// ErrDuplicateTag if a field's tag appears more than once.
+func TLV(unknown *[]TLVEntry, fields ...TLVField) Item {
+	return tlv{unknown: unknown, fields: fields, state: &tlvState{present: make([]bool, len(fields))}}
+}
+
+type tlv struct {
+	unknown *[]TLVEntry
+	fields  []TLVField
+	state   *tlvState
+}
+
+// What the last Decode found, so that re-encoding writes the same entries.
+type tlvState struct {
+	// Whether each field's entry was present.
+	present []bool
+	// Entries with unknown tags, when the caller didn't give somewhere to put them.
+	unknown []TLVEntry
+}
+
+func (e tlv) unknownEntries() []TLVEntry {
+	if e.unknown != nil {
+		return *e.unknown
+	}
+	return e.state.unknown
+}
+func (e tlv) includes(i int) bool {
+	if _, ok := findItem[defaulter](e.fields[i].item); ok {
+		// Otherwise a zero value would decode as the default.
+		return true
+	}
+	return e.state.present[i] || !e.fields[i].v.IsZero()
+}
+
+func (e tlv) contentSize() int {
+	size := 0
+	for i, f := range e.fields {
+		if !e.includes(i) {
+			continue
+		}
+		l := f.item.Size()
+		size += uvarintSize(f.tag) + uvarintSize(uint64(l)) + l
+	}
+	for _, entry := range e.unknownEntries() {
+		l := len(entry.Value)
+		size += uvarintSize(entry.Tag) + uvarintSize(uint64(l)) + l
+	}
+	return size
+}
+func (e tlv) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(e.contentSize()))
+	for i, f := range e.fields {
+		if !e.includes(i) {
+			continue
+		}
+		l := f.item.Size()
+		n += binary.PutUvarint(buf[n:], f.tag)
+		n += binary.PutUvarint(buf[n:], uint64(l))
+		f.item.Encode(buf[n : n+l : n+l])
+		n += l
+	}
+	for _, entry := range e.unknownEntries() {
+		n += binary.PutUvarint(buf[n:], entry.Tag)
+		n += binary.PutUvarint(buf[n:], uint64(len(entry.Value)))
+		n += copy(buf[n:], entry.Value)
+	}
+}
+func (e tlv) Size() int {
+	contentSize := e.contentSize()
+	return uvarintSize(uint64(contentSize)) + contentSize
+}
+func (e tlv) Decode(buf []byte) error {
+	content, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	seen := make([]bool, len(e.fields))
+	var unknown []TLVEntry
+	for len(content) > 0 {
+		tag, n := binary.Uvarint(content)
+		if n == 0 {
+			return io.ErrUnexpectedEOF
+		}
+		if n < 0 {
+			return ErrOverflowVarint
+		}
+		value, m, err := decodeLengthDelim(content[n:])
+		if err != nil {
+			return err
+		}
+		content = content[n+m:]
+
+		i := e.fieldIndex(tag)
+		if i < 0 {
+			unknown = append(unknown, TLVEntry{Tag: tag, Value: append([]byte(nil), value...)})
+			continue
+		}
+		if seen[i] {
+			return ErrDuplicateTag
+		}
+		f := e.fields[i]
+		err = f.item.Decode(value)
+		if err != nil {
+			return err
+		}
+		if f.item.Size() != len(value) {
+			return ErrInvalidTLV
+		}
+		seen[i] = true
+	}
+	for i, f := range e.fields {
+		if seen[i] {
+			continue
+		}
+		if d, ok := findItem[defaulter](f.item); ok {
+			d.setDefault()
+		} else {
+			f.v.Set(reflect.Zero(f.v.Type()))
+		}
+	}
+	copy(e.state.present, seen)
+	if e.unknown != nil {
+		*e.unknown = unknown
+	} else {
+		e.state.unknown = unknown
+	}
+	return nil
+}
+func (e tlv) fieldIndex(tag uint64) int {
+	for i, f := range e.fields {
+		if f.tag == tag {
+			return i
+		}
+	}
+	return -1
+}
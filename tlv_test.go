@@ -0,0 +1,91 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tlvRecord struct {
+	id      uint64
+	name    string
+	enabled bool
+	unknown []TLVEntry
+}
+
+func (r *tlvRecord) encoding() Encoding {
+	return New(TLV(
+		&r.unknown,
+		TagField(1, &r.id, Uvarint64(&r.id)),
+		TagField(2, &r.name, LengthDelimString(&r.name)),
+		TagField(3, &r.enabled, Default(Bool(&r.enabled), func() { r.enabled = true })),
+	))
+}
+
+func TestTLV(t *testing.T) {
+	r := tlvRecord{id: 300, name: "x", enabled: false}
+	b := r.encoding().Encode()
+	// enabled is written even though it's false, since it has a default.
+	require.Equal(t, []byte{
+		0x0B,
+		0x01, 0x02, 0xAC, 0x02,
+		0x02, 0x02, 0x01, 'x',
+		0x03, 0x01, 0x00,
+	}, b)
+
+	var r2 tlvRecord
+	require.NoError(t, r2.encoding().Decode(b))
+	require.Equal(t, uint64(300), r2.id)
+	require.Equal(t, "x", r2.name)
+	require.False(t, r2.enabled)
+
+	// Absent, so the default applies.
+	require.NoError(t, r2.encoding().Decode(append([]byte{0x08}, b[1:9]...)))
+	require.Equal(t, uint64(300), r2.id)
+	require.True(t, r2.enabled)
+
+	// Out of order, with an unknown tag that gets passed through.
+	b = []byte{
+		0x0A,
+		0x03, 0x01, 0x00,
+		0x09, 0x02, 0xAB, 0xCD,
+		0x01, 0x01, 0x05,
+	}
+	var r3 tlvRecord
+	r3.name = "stale"
+	require.NoError(t, r3.encoding().Decode(b))
+	require.Equal(t, tlvRecord{
+		id:      5,
+		enabled: false,
+		unknown: []TLVEntry{{Tag: 9, Value: []byte{0xAB, 0xCD}}},
+	}, r3)
+	require.Equal(t, []byte{
+		0x0A,
+		0x01, 0x01, 0x05,
+		0x03, 0x01, 0x00,
+		0x09, 0x02, 0xAB, 0xCD,
+	}, r3.encoding().Encode())
+
+	var r4 tlvRecord
+	require.NoError(t, New(TLV(nil, TagField(1, &r4.id, Uvarint64(&r4.id)))).Decode(b))
+	require.Equal(t, uint64(5), r4.id)
+
+	require.Equal(t, ErrInvalidTLV, r4.encoding().Decode([]byte{0x04, 0x01, 0x02, 0x05, 0x00}))
+	require.Equal(t, io.ErrUnexpectedEOF, r4.encoding().Decode([]byte{0x03, 0x01, 0x02, 0x05}))
+
+	// Whatever a TLV consumes when decoding, it re-encodes to the same length, so that the items
+	// after it are decoded from the right place. Here with a field explicitly set to its zero value,
+	// an unknown tag with nowhere to put it, and duplicate tags.
+	var a uint64
+	var after byte
+	enc := New(TLV(nil, TagField(1, &a, Uvarint64(&a))), Byte(&after))
+	b = []byte{0x06, 0x01, 0x01, 0x00, 0x02, 0x01, 0x09, 0x42}
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, uint64(0), a)
+	require.Equal(t, byte(0x42), after)
+	require.Equal(t, b, enc.Encode())
+
+	b = []byte{0x06, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x42}
+	require.Equal(t, ErrDuplicateTag, enc.Decode(b))
+}
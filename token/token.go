@@ -0,0 +1,98 @@
+// Package token builds compact, tamper-evident bearer tokens out of package encode.
+//
+// A token is a format version, an expiry time, and an opaque payload, signed with HMAC-SHA256. The
+// payload is not encrypted, so it is readable by anyone holding the token.
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradenaw/encode"
+)
+
+// The format version written by Encode.
+const Version = 1
+
+var (
+	ErrExpired            = errors.New("token: expired")
+	ErrUnsupportedVersion = errors.New("token: unsupported version")
+	ErrMalformed          = errors.New("token: malformed")
+)
+
+type token struct {
+	version byte
+	expiry  uint64
+	payload []byte
+	// Anything after the signature, which a valid token doesn't have.
+	trailing []byte
+}
+
+func (t *token) encoding(key []byte) encode.Encoding {
+	return encode.New(
+		encode.Signed(
+			encode.HMACSHA256(key),
+			encode.Byte(&t.version),
+			encode.Uvarint64(&t.expiry),
+			encode.LengthDelimBytes(&t.payload),
+		),
+		encode.Rest(&t.trailing),
+	)
+}
+
+// Returns a token carrying payload that Decode accepts until expiry, signed with key. expiry is kept
+// to the second, and must not be before 1970.
+func Encode(key []byte, payload []byte, expiry time.Time) []byte {
+	t := token{version: Version, expiry: uint64(expiry.Unix()), payload: payload}
+	return t.encoding(key).Encode()
+}
+
+// Checks that b is a token signed with key that has not expired as of now, and returns its payload.
+//
+// Returns encode.ErrInvalidSignature if b was not signed with key or was modified, ErrExpired if the
+// token's expiry is at or before now, and ErrUnsupportedVersion if it was written by an incompatible
+// version of this package.
+func Decode(key []byte, b []byte, now time.Time) ([]byte, error) {
+	version, err := peekVersion(b)
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case Version:
+		return decodeV1(key, b, now)
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+}
+
+// Returns the version of the token in b without checking its signature, so that the rest can be
+// decoded with that version's layout. The signature covers the version, so a modified version is
+// still caught by the layout it selects.
+func peekVersion(b []byte) (byte, error) {
+	var (
+		length  uint64
+		version byte
+		rest    []byte
+	)
+	err := encode.New(encode.Uvarint64(&length), encode.Byte(&version), encode.Rest(&rest)).Decode(b)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func decodeV1(key []byte, b []byte, now time.Time) ([]byte, error) {
+	var t token
+	enc := t.encoding(key)
+	err := enc.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.trailing) != 0 {
+		return nil, ErrMalformed
+	}
+	if !now.Before(time.Unix(int64(t.expiry), 0)) {
+		return nil, ErrExpired
+	}
+	return t.payload, nil
+}
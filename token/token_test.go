@@ -0,0 +1,52 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bradenaw/encode"
+)
+
+func TestToken(t *testing.T) {
+	key := []byte("secret")
+	now := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	expiry := now.Add(time.Hour)
+
+	b := Encode(key, []byte("user:42"), expiry)
+	// length, version, expiry, payload length, payload, signature
+	require.Len(t, b, 1+1+5+1+7+32)
+
+	payload, err := Decode(key, b, now)
+	require.NoError(t, err)
+	require.Equal(t, []byte("user:42"), payload)
+
+	_, err = Decode(key, b, expiry.Add(-time.Second))
+	require.NoError(t, err)
+	_, err = Decode(key, b, expiry)
+	require.Equal(t, ErrExpired, err)
+
+	_, err = Decode([]byte("other secret"), b, now)
+	require.Equal(t, encode.ErrInvalidSignature, err)
+
+	for i := range b {
+		tampered := append([]byte(nil), b...)
+		tampered[i] ^= 0x01
+		_, err = Decode(key, tampered, now)
+		require.Error(t, err)
+	}
+
+	_, err = Decode(key, append(b, 0x00), now)
+	require.Equal(t, ErrMalformed, err)
+
+	v2 := token{version: Version + 1, expiry: uint64(expiry.Unix())}
+	_, err = Decode(key, v2.encoding(key).Encode(), now)
+	require.Equal(t, ErrUnsupportedVersion, err)
+
+	// A later version may lay out the signed content differently, so it must not be checked as
+	// this version first.
+	unknown := []byte{0x03, Version + 1, 0xFF, 0xFF}
+	_, err = Decode(key, unknown, now)
+	require.Equal(t, ErrUnsupportedVersion, err)
+}
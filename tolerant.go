@@ -0,0 +1,90 @@
+package encode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The failure to decode one item of an Encoding. Error redacts Err if the item is Sensitive, like
+// InvalidError.
+type FieldError struct {
+	// The index of the item within the Encoding.
+	Index int
+	// The offset in the buffer that the item started at.
+	Offset int
+	Err    error
+
+	sensitive bool
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf(
+		"encode: item %d at offset %d: %s",
+		e.Index, e.Offset, errorText(e.Err, e.sensitive),
+	)
+}
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Every item that failed in a call to DecodeTolerant, in order.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
+}
+
+// Like Decode, but keeps going after an item fails to decode so that as much as possible is
+// recovered from a partially corrupted buffer. Returns FieldErrors listing every item that failed,
+// or nil if none did.
+//
+// After a failure the next item is assumed to start Size() bytes later, which is only reliable for
+// fixed-width items. Items after a failed variable-width item are likely to fail or be garbage.
+// The post-decode hook only runs if every item succeeded.
+func (enc Encoding) DecodeTolerant(buf []byte) error {
+	if enc.preDecode != nil {
+		err := enc.preDecode(buf)
+		if err != nil {
+			return err
+		}
+	}
+	var errs FieldErrors
+	i := 0
+	for index, item := range enc.items {
+		setOffset(item, i)
+		err := item.Decode(buf[i:])
+		if err != nil {
+			errs = append(errs, &FieldError{
+				Index:     index,
+				Offset:    i,
+				Err:       err,
+				sensitive: isSensitive(item),
+			})
+			// Only a guess, which is past the end if buf was truncated partway through item.
+			i += item.Size()
+			if i > len(buf) {
+				i = len(buf)
+			}
+			continue
+		}
+		i += decodedSize(item)
+	}
+	if errs != nil {
+		return errs
+	}
+	if enc.postDecode != nil {
+		return enc.postDecode()
+	}
+	return nil
+}
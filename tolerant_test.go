@@ -0,0 +1,34 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTolerant(t *testing.T) {
+	var a uint16
+	var b, c bool
+	var d uint32
+	enc := New(FixedUint16(&a), Bool(&b), Bool(&c), FixedUint32(&d))
+
+	err := enc.DecodeTolerant([]byte{0x00, 0x01, 0x05, 0x01, 0x00, 0x00})
+	require.Error(t, err)
+	var errs FieldErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	require.Equal(t, 1, errs[0].Index)
+	require.Equal(t, 2, errs[0].Offset)
+	require.Equal(t, ErrInvalidBool, errs[0].Err)
+	require.Equal(t, 3, errs[1].Index)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	require.True(t, errors.Is(err, ErrInvalidBool))
+
+	require.Equal(t, uint16(1), a)
+	require.True(t, c)
+
+	require.NoError(t, enc.DecodeTolerant([]byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}))
+	require.Equal(t, uint32(3), d)
+}
@@ -0,0 +1,132 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrLayoutChanged = errors.New("encode: changed item no longer fits in its old position")
+
+// The location of one item within an encoded buffer.
+type FieldOffset struct {
+	Offset int
+	Size   int
+}
+
+// Like Encode, but also returns where each item ended up in the buffer, in the same order as the
+// items. This is useful for post-processing specific fields, such as patching them in place, signing
+// a range of the buffer, or building an index of field positions.
+func (enc Encoding) EncodeWithOffsets() ([]byte, []FieldOffset) {
+	buf := enc.Encode()
+	return buf, enc.itemOffsets(false)
+}
+
+// Returns where each of enc's items is in its current encoding, or if decoded is set, where they
+// were in the buffer last decoded. These account for items whose size depends on where they are,
+// like AlignTo, and for items that decoded a different size than they encode to.
+func (enc Encoding) itemOffsets(decoded bool) []FieldOffset {
+	offsets := make([]FieldOffset, len(enc.items))
+	offset := 0
+	for i, item := range enc.items {
+		setOffset(item, offset)
+		var size int
+		if decoded {
+			size = decodedSize(item)
+		} else {
+			size = item.Size()
+		}
+		offsets[i] = FieldOffset{Offset: offset, Size: size}
+		offset += size
+	}
+	return offsets
+}
+
+// Tracks which items of an Encoding have changed since it was last encoded or decoded, so that only
+// those parts of the buffer need to be rewritten.
+type Tracker struct {
+	enc      Encoding
+	snapshot [][]byte
+	offsets  []FieldOffset
+}
+
+// Start tracking changes to enc's items. Nothing is considered changed until the first call to
+// Encode or Decode.
+func Track(enc Encoding) *Tracker {
+	return &Tracker{enc: enc}
+}
+
+// Encode the tracked Encoding, and remember the state of each item.
+func (t *Tracker) Encode() []byte {
+	buf := t.enc.Encode()
+	t.remember(buf, t.enc.itemOffsets(false))
+	return buf
+}
+
+// Decode buf into the tracked Encoding, and remember the state of each item.
+func (t *Tracker) Decode(buf []byte) error {
+	err := t.enc.Decode(buf)
+	if err != nil {
+		return err
+	}
+	t.remember(buf, t.enc.itemOffsets(true))
+	return nil
+}
+
+func (t *Tracker) remember(buf []byte, offsets []FieldOffset) {
+	t.snapshot = make([][]byte, len(offsets))
+	t.offsets = offsets
+	for i, o := range offsets {
+		t.snapshot[i] = append([]byte(nil), buf[o.Offset:o.Offset+o.Size]...)
+	}
+}
+
+// Returns the indexes of the items whose encoding differs from when Encode or Decode was last
+// called.
+func (t *Tracker) Dirty() []int {
+	if t.snapshot == nil {
+		return nil
+	}
+	buf := t.enc.encode()
+	return t.dirty(buf, t.enc.itemOffsets(false))
+}
+
+// Returns the indexes of the items whose bytes in buf, the current encoding with the given offsets,
+// differ from the snapshot.
+func (t *Tracker) dirty(buf []byte, offsets []FieldOffset) []int {
+	var dirty []int
+	for i, o := range offsets {
+		if !bytes.Equal(buf[o.Offset:o.Offset+o.Size], t.snapshot[i]) {
+			dirty = append(dirty, i)
+		}
+	}
+	return dirty
+}
+
+// Rewrite only the changed items into buf, which must hold the result of the last Encode or Decode,
+// and return the locations that were written. Afterwards, the current state is remembered as if
+// Encode had been called.
+//
+// This only works if every changed item still encodes to the same size in the same place, which is
+// always true of fixed-width items. Otherwise, buf is left alone and ErrLayoutChanged is returned,
+// and the whole thing needs to be encoded again.
+func (t *Tracker) Update(buf []byte) ([]FieldOffset, error) {
+	if t.snapshot == nil {
+		return nil, nil
+	}
+	current := t.enc.encode()
+	offsets := t.enc.itemOffsets(false)
+	dirty := t.dirty(current, offsets)
+	for _, i := range dirty {
+		if offsets[i] != t.offsets[i] {
+			return nil, ErrLayoutChanged
+		}
+	}
+	written := make([]FieldOffset, 0, len(dirty))
+	for _, i := range dirty {
+		o := offsets[i]
+		copy(buf[o.Offset:o.Offset+o.Size], current[o.Offset:o.Offset+o.Size])
+		t.snapshot[i] = append(t.snapshot[i][:0], current[o.Offset:o.Offset+o.Size]...)
+		written = append(written, o)
+	}
+	return written, nil
+}
@@ -0,0 +1,75 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	var a uint16
+	var b bool
+	var c uint32
+	var d uint64
+	tr := Track(New(FixedUint16(&a), Bool(&b), FixedUint32(&c), Uvarint64(&d)))
+
+	a, b, c, d = 1, true, 2, 3
+	buf := tr.Encode()
+	require.Empty(t, tr.Dirty())
+
+	b = false
+	c = 0x01020304
+	require.Equal(t, []int{1, 2}, tr.Dirty())
+	written, err := tr.Update(buf)
+	require.NoError(t, err)
+	require.Equal(t, []FieldOffset{{Offset: 2, Size: 1}, {Offset: 3, Size: 4}}, written)
+	require.Equal(t, []byte{0x00, 0x01, 0x00, 0x01, 0x02, 0x03, 0x04, 0x03}, buf)
+	require.Empty(t, tr.Dirty())
+
+	d = 1000
+	_, err = tr.Update(buf)
+	require.Equal(t, ErrLayoutChanged, err)
+
+	require.NoError(t, tr.Decode([]byte{0x00, 0x02, 0x01, 0x00, 0x00, 0x00, 0x05, 0x06}))
+	require.Empty(t, tr.Dirty())
+	a = 9
+	require.Equal(t, []int{0}, tr.Dirty())
+}
+
+func TestEncodeWithOffsets(t *testing.T) {
+	a := uint16(1)
+	s := "hello"
+	d := uint64(1000)
+	buf, offsets := New(FixedUint16(&a), LengthDelimString(&s), Uvarint64(&d)).EncodeWithOffsets()
+	require.Equal(t, []byte("\x00\x01\x05hello\xe8\x07"), buf)
+	require.Equal(t, []FieldOffset{{Offset: 0, Size: 2}, {Offset: 2, Size: 6}, {Offset: 8, Size: 2}}, offsets)
+}
+
+func TestTrackerPositionalItems(t *testing.T) {
+	var a byte
+	var b uint32
+	var c byte
+	enc := New(Byte(&a), AlignTo(4), FixedUint32(&b), Byte(&c), AlignTo(4))
+
+	a, b, c = 1, 2, 3
+	buf, offsets := enc.EncodeWithOffsets()
+	require.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 2, 3, 0, 0, 0}, buf)
+	require.Equal(t, []FieldOffset{
+		{Offset: 0, Size: 1},
+		{Offset: 1, Size: 3},
+		{Offset: 4, Size: 4},
+		{Offset: 8, Size: 1},
+		{Offset: 9, Size: 3},
+	}, offsets)
+
+	tr := Track(enc)
+	require.NoError(t, tr.Decode(buf))
+	require.Empty(t, tr.Dirty())
+	b = 7
+	require.Equal(t, []int{2}, tr.Dirty())
+	written, err := tr.Update(buf)
+	require.NoError(t, err)
+	require.Equal(t, []FieldOffset{{Offset: 4, Size: 4}}, written)
+	require.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 7, 3, 0, 0, 0}, buf)
+	require.Equal(t, buf, enc.Encode())
+}
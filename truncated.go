@@ -0,0 +1,62 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var ErrStringTooLong = errors.New("encode: string longer than its maximum length")
+
+// Encode v as in LengthDelimString, but cut down to at most max bytes, for fixed storage budgets like
+// display name columns. v is cut at the last rune boundary that fits, so that a multi-byte UTF-8
+// sequence is never split. *v itself is not modified, so decoding the result may give back a
+// shorter string.
+//
+// Decoding returns ErrStringTooLong for strings longer than max bytes, which this never produces.
+//
+// Panics if max is negative.
+func TruncatedString(v *string, max int) Item {
+	if max < 0 {
+		panic(fmt.Sprintf("invalid max=%d, must be non-negative", max))
+	}
+	return truncatedString{v: v, max: max}
+}
+
+type truncatedString struct {
+	v   *string
+	max int
+}
+
+func (e truncatedString) truncated() string {
+	s := *e.v
+	if len(s) <= e.max {
+		return s
+	}
+	i := e.max
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i]
+}
+func (e truncatedString) Encode(buf []byte) {
+	s := e.truncated()
+	n := binary.PutUvarint(buf, uint64(len(s)))
+	copy(buf[n:], s)
+}
+func (e truncatedString) Size() int {
+	s := e.truncated()
+	return uvarintSize(uint64(len(s))) + len(s)
+}
+func (e truncatedString) Decode(buf []byte) error {
+	b, _, err := decodeLengthDelim(buf)
+	if err != nil {
+		return err
+	}
+	if len(b) > e.max {
+		return ErrStringTooLong
+	}
+	*e.v = string(b)
+	return nil
+}
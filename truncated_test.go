@@ -0,0 +1,30 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncatedString(t *testing.T) {
+	check := func(s string, max int, expected string) {
+		v := s
+		b := New(TruncatedString(&v, max)).Encode()
+		require.Equal(t, s, v)
+		var decoded string
+		require.NoError(t, New(TruncatedString(&decoded, max)).Decode(b))
+		require.Equal(t, expected, decoded)
+	}
+	check("hello", 10, "hello")
+	check("hello", 5, "hello")
+	check("hello", 3, "hel")
+	check("hello", 0, "")
+	// é is 2 bytes and € is 3, neither is split.
+	check("café", 4, "caf")
+	check("café", 5, "café")
+	check("€€", 5, "€")
+	check("€€", 2, "")
+
+	var s string
+	require.Equal(t, ErrStringTooLong, New(TruncatedString(&s, 3)).Decode([]byte("\x04abcd")))
+}
@@ -1,5 +1,16 @@
 package encode
 
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotOrderPreserving = errors.New("encode: item does not preserve ordering")
+
+// An Item that can be used in a Tuple. Its encoding must lexicographically order the same way as the
+// values it encodes, and must be self-delimiting unless it is the last item.
+//
+// OrderPreserving does nothing, and only exists so that Items are not accidentally TupleItems.
 type TupleItem interface {
 	Item
 	EncodeTuple(buf []byte, last bool)
@@ -15,25 +26,100 @@ type Tuple struct {
 func NewTuple(items ...TupleItem) Tuple {
 	return Tuple{items: items}
 }
+
+// Like NewTuple, but for items that are only known to be Items, for example when they are chosen at
+// runtime. Returns an error wrapping ErrNotOrderPreserving if any of them would produce keys that
+// don't sort, rather than letting them be silently composed.
+func NewTupleChecked(items ...Item) (Tuple, error) {
+	tupleItems := make([]TupleItem, len(items))
+	for i, item := range items {
+		tupleItem, ok := item.(TupleItem)
+		if !ok {
+			return Tuple{}, fmt.Errorf("encode: item %d (%T): %w", i, item, ErrNotOrderPreserving)
+		}
+		tupleItems[i] = tupleItem
+	}
+	return NewTuple(tupleItems...), nil
+}
 func (t Tuple) Encode() []byte {
 	return t.EncodePrefix(len(t.items))
 }
 func (t Tuple) EncodePrefix(n int) []byte {
+	return t.appendPrefix(nil, n)
+}
+
+// Appends the encoding of t to buf and returns the extended buffer. If buf has enough spare
+// capacity, this doesn't allocate.
+func (t Tuple) AppendTo(buf []byte) []byte {
+	return t.appendPrefix(buf, len(t.items))
+}
+
+// Encodes n keys for a bulk write, calling set(i) to fill in the values t's items point to before
+// encoding the ith, and appends them to dst. The keys share a single backing buffer, so this makes a
+// handful of allocations in total rather than one per key. Each key's capacity is its length, so
+// appending to one doesn't overwrite the next.
+func (t Tuple) EncodeAll(dst [][]byte, n int, set func(i int)) [][]byte {
+	var buf []byte
+	ends := make([]int, n)
+	for i := 0; i < n; i++ {
+		set(i)
+		buf = t.AppendTo(buf)
+		ends[i] = len(buf)
+	}
+	start := 0
+	for _, end := range ends {
+		dst = append(dst, buf[start:end:end])
+		start = end
+	}
+	return dst
+}
+
+func (t Tuple) appendPrefix(buf []byte, n int) []byte {
 	size := 0
 	for i := 0; i < n; i++ {
 		item := t.items[i]
-		size += item.SizeTuple(i == len(t.items)-1)
+		size += item.SizeTuple(i == n-1)
 	}
-	buf := make([]byte, size)
-	j := 0
+	start := len(buf)
+	if cap(buf)-start < size {
+		grown := make([]byte, start, start+size)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:start+size]
+	// Items may assume they're encoding into zeroed bytes.
+	clear(buf[start:])
+	j := start
 	for i := 0; i < n; i++ {
 		item := t.items[i]
 		size := item.SizeTuple(i == n-1)
-		item.EncodeTuple(buf[j:j+size], i == n-1)
+		item.EncodeTuple(buf[j:j+size:j+size], i == n-1)
 		j += size
 	}
 	return buf
 }
+
+// Returns Hash64 of EncodePrefix(n), for inserting into and querying Bloom filters by key prefix.
+func (t Tuple) HashPrefix(n int) uint64 {
+	return Hash64(t.EncodePrefix(n))
+}
+
+// Returns the encoding of t prefixed with one byte, Hash64 of the encoding mod shards. This spreads
+// sequential keys, such as ones starting with a timestamp, across shards partitions to avoid a hot
+// spot, while keys within each shard still sort in the same order as t. Scanning in order means
+// scanning each of the shards prefixes, []byte{0} through []byte{shards-1}, and merging.
+//
+// Panics if shards is not in [1, 256].
+func ShardedKey(t Tuple, shards int) []byte {
+	if shards < 1 || shards > 256 {
+		panic(fmt.Sprintf("invalid shards=%d, must be in [1, 256]", shards))
+	}
+	b := t.Encode()
+	out := make([]byte, len(b)+1)
+	out[0] = byte(Hash64(b) % uint64(shards))
+	copy(out[1:], b)
+	return out
+}
 func (t Tuple) Decode(buf []byte) error {
 	return t.DecodePrefix(buf, len(t.items))
 }
@@ -0,0 +1,74 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTupleChecked(t *testing.T) {
+	var a uint16
+	var b []byte
+	var c uint64
+
+	tuple, err := NewTupleChecked(FixedUint16(&a), EscapedBytes(&b), OrdUvarint64(&c))
+	require.NoError(t, err)
+	a, b, c = 1, []byte("x"), 2
+	require.Equal(t, []byte{0x00, 0x01, 'x', 0x00, 0x00, 0x02}, tuple.Encode())
+
+	_, err = NewTupleChecked(FixedUint16(&a), Uvarint64(&c))
+	require.True(t, errors.Is(err, ErrNotOrderPreserving))
+
+	_, err = NewTupleChecked(DelimBytes(&b, 0x01))
+	require.True(t, errors.Is(err, ErrNotOrderPreserving))
+	_, ok := DelimBytes(&b, 0x01).(TupleItem)
+	require.False(t, ok)
+	_, err = NewTupleChecked(DelimBytes(&b, 0x00))
+	require.NoError(t, err)
+}
+
+func TestShardedKey(t *testing.T) {
+	var ts uint64
+	tup := NewTuple(FixedUint64(&ts))
+
+	byShard := make(map[byte][][]byte)
+	for ts = 0; ts < 1000; ts++ {
+		k := ShardedKey(tup, 16)
+		require.Less(t, k[0], byte(16))
+		require.Equal(t, tup.Encode(), k[1:])
+		byShard[k[0]] = append(byShard[k[0]], k)
+	}
+	require.Len(t, byShard, 16)
+	for _, keys := range byShard {
+		for i := 1; i < len(keys); i++ {
+			require.True(t, bytes.Compare(keys[i-1], keys[i]) < 0)
+		}
+	}
+
+	require.Equal(t, byte(0), ShardedKey(tup, 1)[0])
+	require.Panics(t, func() { ShardedKey(tup, 257) })
+}
+
+func TestTupleEncodeAll(t *testing.T) {
+	var a uint64
+	var s string
+	tup := NewTuple(OrdUvarint64(&a), EscapedString(&s))
+
+	names := []string{"a", "bb", "", "dddd"}
+	keys := tup.EncodeAll([][]byte{[]byte("existing")}, len(names), func(i int) {
+		a = uint64(i * 1000)
+		s = names[i]
+	})
+	require.Len(t, keys, len(names)+1)
+	require.Equal(t, []byte("existing"), keys[0])
+	for i, name := range names {
+		a = uint64(i * 1000)
+		s = name
+		require.Equal(t, tup.Encode(), keys[i+1])
+		require.Equal(t, len(keys[i+1]), cap(keys[i+1]))
+	}
+
+	require.Empty(t, tup.EncodeAll(nil, 0, func(i int) {}))
+}
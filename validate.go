@@ -0,0 +1,42 @@
+package encode
+
+import "fmt"
+
+// Returns the error that Decode would return for buf, leaving enc's destinations with the values
+// they had before. This is useful for checking records before accepting them, for example before
+// persisting them.
+//
+// This works by encoding the destinations' current values, decoding buf, and then decoding the
+// saved values back, so it costs about three times as much as Decode. The destinations do hold the
+// values decoded from buf in between, so Validate must not be called while anything else is using
+// them. The pre-decode and post-decode hooks run only for the decode of buf. Validate is not
+// counted by WithStats.
+//
+// Returns an error without decoding buf if the current values can't be encoded, for example a
+// Switch key with no matching case, and an error if they can't be decoded back, which no Item in
+// this package does.
+func (enc Encoding) Validate(buf []byte) error {
+	saved, err := enc.save()
+	if err != nil {
+		return err
+	}
+	_, err = enc.decode(buf)
+	restore := enc
+	restore.preDecode, restore.postDecode = nil, nil
+	_, restoreErr := restore.decode(saved)
+	if restoreErr != nil {
+		return fmt.Errorf("encode: Validate could not restore values: %w", restoreErr)
+	}
+	return err
+}
+
+// Encodes enc's current values, returning an error instead of panicking if they're out of range for
+// their items.
+func (enc Encoding) save() (saved []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("encode: Validate could not save values: %v", r)
+		}
+	}()
+	return enc.encode(), nil
+}
@@ -0,0 +1,53 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	var a uint16
+	var b bool
+	var c string
+	enc := New(FixedUint16(&a), Bool(&b), LengthDelimString(&c))
+
+	a, b, c = 7, true, "hello"
+	good := enc.Encode()
+	a, b, c = 1, false, "unchanged"
+
+	require.NoError(t, enc.Validate(good))
+	require.Equal(t, uint16(1), a)
+	require.Equal(t, false, b)
+	require.Equal(t, "unchanged", c)
+
+	require.Equal(t, ErrInvalidBool, enc.Validate([]byte{0, 7, 2, 0}))
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Validate(good[:len(good)-1]))
+	require.Equal(t, uint16(1), a)
+	require.Equal(t, false, b)
+	require.Equal(t, "unchanged", c)
+
+	var s Stats
+	require.NoError(t, enc.WithStats(&s).Validate(good))
+	require.Equal(t, int64(0), s.Snapshot().Decodes)
+
+	// Current values that fail the post-decode hook are still restored, and the hook only sees buf.
+	calls := 0
+	hooked := enc.WithPostDecode(func() error {
+		calls++
+		if a == 1 {
+			return errors.New("a must not be 1")
+		}
+		return nil
+	})
+	require.NoError(t, hooked.Validate(good))
+	require.Equal(t, 1, calls)
+	require.Equal(t, uint16(1), a)
+
+	// Current values that can't be encoded are reported rather than panicking.
+	var x uint32 = 1 << 24
+	require.Error(t, New(FixedUint24(&x)).Validate([]byte{0, 0, 1}))
+	require.Equal(t, uint32(1<<24), x)
+}
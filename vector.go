@@ -0,0 +1,164 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// How Vector stores each element.
+type VectorFormat int
+
+const (
+	// 4 bytes per element, exactly.
+	VectorFloat32 VectorFormat = iota
+	// 2 bytes per element, rounded as in Float16.
+	VectorFloat16
+	// 1 byte per element. The vector's values are mapped linearly onto [-127, 127], and a header
+	// with a float32 scale and offset is stored so that each element decodes as offset + scale*q.
+	// The error in each element is at most half of (max-min)/254.
+	VectorInt8
+)
+
+// Encode v, such as an embedding, as a uvarint of v's length followed by its elements in the given
+// format, in big endian order.
+func Vector(v *[]float32, format VectorFormat) Item {
+	switch format {
+	case VectorFloat32, VectorFloat16, VectorInt8:
+	default:
+		panic("encode: unknown VectorFormat")
+	}
+	return vector{v: v, format: format}
+}
+
+type vector struct {
+	v      *[]float32
+	format VectorFormat
+}
+
+func (e vector) elemSize() int {
+	switch e.format {
+	case VectorFloat32:
+		return 4
+	case VectorFloat16:
+		return 2
+	}
+	return 1
+}
+func (e vector) headerSize() int {
+	if e.format == VectorInt8 {
+		return 8
+	}
+	return 0
+}
+func (e vector) Encode(buf []byte) {
+	v := *e.v
+	i := binary.PutUvarint(buf, uint64(len(v)))
+	switch e.format {
+	case VectorFloat32:
+		for _, x := range v {
+			binary.BigEndian.PutUint32(buf[i:], math.Float32bits(x))
+			i += 4
+		}
+	case VectorFloat16:
+		for _, x := range v {
+			binary.BigEndian.PutUint16(buf[i:], float32ToFloat16(x))
+			i += 2
+		}
+	case VectorInt8:
+		scale, offset := int8Quantization(v)
+		binary.BigEndian.PutUint32(buf[i:], math.Float32bits(scale))
+		binary.BigEndian.PutUint32(buf[i+4:], math.Float32bits(offset))
+		i += 8
+		for _, x := range v {
+			q := 0.0
+			if scale != 0 {
+				q = math.Round(float64((x - offset) / scale))
+			}
+			switch {
+			case q != q:
+				q = 0
+			case q < -127:
+				q = -127
+			case q > 127:
+				q = 127
+			}
+			buf[i] = byte(int8(q))
+			i++
+		}
+	}
+}
+func (e vector) Size() int {
+	n := len(*e.v)
+	return uvarintSize(uint64(n)) + e.headerSize() + n*e.elemSize()
+}
+func (e vector) Decode(buf []byte) error {
+	n, i := binary.Uvarint(buf)
+	if i == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	if i < 0 {
+		return ErrOverflowVarint
+	}
+	if i != uvarintSize(n) {
+		// Size is the minimal length, so accepting padding would leave the next item misaligned.
+		return ErrNonCanonical
+	}
+	// Check before allocating, so that a corrupted length can't cause a huge allocation.
+	if len(buf[i:]) < e.headerSize() {
+		return io.ErrUnexpectedEOF
+	}
+	// Divide rather than multiply, since n*elemSize can overflow.
+	if n > uint64(len(buf[i:])-e.headerSize())/uint64(e.elemSize()) {
+		return io.ErrUnexpectedEOF
+	}
+	v := make([]float32, n)
+	switch e.format {
+	case VectorFloat32:
+		for j := range v {
+			v[j] = math.Float32frombits(binary.BigEndian.Uint32(buf[i:]))
+			i += 4
+		}
+	case VectorFloat16:
+		for j := range v {
+			v[j] = float16ToFloat32(binary.BigEndian.Uint16(buf[i:]))
+			i += 2
+		}
+	case VectorInt8:
+		scale := math.Float32frombits(binary.BigEndian.Uint32(buf[i:]))
+		offset := math.Float32frombits(binary.BigEndian.Uint32(buf[i+4:]))
+		i += 8
+		for j := range v {
+			// scale is rounded, so at the ends of the float32 range this can land just past
+			// MaxFloat32 even though the value that was encoded didn't.
+			x := float64(offset) + float64(scale)*float64(int8(buf[i]))
+			v[j] = float32(math.Max(-math.MaxFloat32, math.Min(x, math.MaxFloat32)))
+			i++
+		}
+	}
+	*e.v = v
+	return nil
+}
+
+// Returns the scale and offset that map the finite values of v onto [-127, 127].
+func int8Quantization(v []float32) (scale float32, offset float32) {
+	min := float32(math.Inf(1))
+	max := float32(math.Inf(-1))
+	for _, x := range v {
+		if math.IsInf(float64(x), 0) || x != x {
+			continue
+		}
+		if x < min {
+			min = x
+		}
+		if x > max {
+			max = x
+		}
+	}
+	if min > max {
+		return 0, 0
+	}
+	// Halve before subtracting, like the midpoint, since max - min overflows when the values span
+	// more than MaxFloat32.
+	return max/254 - min/254, min/2 + max/2
+}
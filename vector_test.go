@@ -0,0 +1,74 @@
+package encode
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	v := make([]float32, 100)
+	for i := range v {
+		v[i] = float32(r.NormFloat64())
+	}
+
+	for _, tc := range []struct {
+		format    VectorFormat
+		size      int
+		tolerance float64
+	}{
+		{VectorFloat32, 1 + 400, 0},
+		{VectorFloat16, 1 + 200, 0.005},
+		{VectorInt8, 1 + 8 + 100, 0},
+	} {
+		x := v
+		enc := New(Vector(&x, tc.format))
+		b := enc.Encode()
+		require.Len(t, b, tc.size)
+		x = nil
+		require.NoError(t, enc.Decode(b))
+		require.Len(t, x, len(v))
+
+		tolerance := tc.tolerance
+		if tc.format == VectorInt8 {
+			scale, _ := int8Quantization(v)
+			tolerance = float64(scale)/2 + 1e-6
+		}
+		for i := range v {
+			require.InDelta(t, v[i], x[i], tolerance)
+		}
+	}
+
+	constant := []float32{3, 3, 3}
+	enc := New(Vector(&constant, VectorInt8))
+	b := enc.Encode()
+	constant = nil
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, []float32{3, 3, 3}, constant)
+
+	weird := []float32{float32(math.NaN()), float32(math.Inf(1)), -1, 1}
+	enc = New(Vector(&weird, VectorInt8))
+	b = enc.Encode()
+	require.NoError(t, enc.Decode(b))
+	require.Equal(t, []float32{0, 1, -1, 1}, weird)
+
+	extreme := []float32{-math.MaxFloat32, 0, math.MaxFloat32}
+	enc = New(Vector(&extreme, VectorInt8))
+	b = enc.Encode()
+	require.NoError(t, enc.Decode(b))
+	require.InEpsilon(t, -math.MaxFloat32, extreme[0], 1e-6)
+	require.Equal(t, float32(0), extreme[1])
+	require.InEpsilon(t, math.MaxFloat32, extreme[2], 1e-6)
+
+	require.Equal(t, io.ErrUnexpectedEOF, enc.Decode([]byte{0xFF, 0xFF, 0xFF, 0x0F}))
+
+	// A count of 1<<62, for which count*elemSize overflows.
+	huge := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x40}
+	for _, format := range []VectorFormat{VectorFloat32, VectorFloat16, VectorInt8} {
+		require.Equal(t, io.ErrUnexpectedEOF, New(Vector(&weird, format)).Decode(huge))
+	}
+}
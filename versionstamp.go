@@ -0,0 +1,90 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var ErrIncompleteVersionStamps = errors.New("encode: need exactly one incomplete VersionStamp")
+
+// A FoundationDB-style version stamp: 10 bytes assigned by the database when a transaction commits,
+// which increase with every commit, followed by 2 bytes chosen by the user to order stamps within a
+// transaction.
+type VersionStamp struct {
+	TransactionVersion [10]byte
+	UserVersion        uint16
+}
+
+// Returns a VersionStamp whose TransactionVersion is a placeholder, to be filled in by the database
+// at commit time. See Tuple.EncodeVersionStamped.
+func IncompleteVersionStamp(userVersion uint16) VersionStamp {
+	v := VersionStamp{UserVersion: userVersion}
+	for i := range v.TransactionVersion {
+		v.TransactionVersion[i] = 0xFF
+	}
+	return v
+}
+
+// Returns true if v's TransactionVersion has been filled in.
+func (v VersionStamp) Complete() bool {
+	for _, b := range v.TransactionVersion {
+		if b != 0xFF {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode v as its 10 byte TransactionVersion followed by UserVersion in big endian, which sorts in
+// commit order.
+func VersionStampItem(v *VersionStamp) TupleItem {
+	return versionStampItem{v}
+}
+
+type versionStampItem struct{ v *VersionStamp }
+
+func (e versionStampItem) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e versionStampItem) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e versionStampItem) SizeTuple(last bool) int                 { return e.Size() }
+func (e versionStampItem) OrderPreserving()                        {}
+func (e versionStampItem) Encode(buf []byte) {
+	copy(buf, e.v.TransactionVersion[:])
+	binary.BigEndian.PutUint16(buf[10:], e.v.UserVersion)
+}
+func (e versionStampItem) Size() int {
+	return 12
+}
+func (e versionStampItem) Decode(buf []byte) error {
+	if len(buf) < 12 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(e.v.TransactionVersion[:], buf)
+	e.v.UserVersion = binary.BigEndian.Uint16(buf[10:])
+	return nil
+}
+
+// Like Encode, but also returns the offset of the placeholder TransactionVersion of the tuple's one
+// incomplete VersionStampItem, so that the database can splice in the real version at commit time,
+// as with FoundationDB's SET_VERSIONSTAMPED_KEY.
+//
+// Only VersionStampItems directly in t are considered. Returns ErrIncompleteVersionStamps if there
+// isn't exactly one incomplete one.
+func (t Tuple) EncodeVersionStamped() ([]byte, int, error) {
+	offset := -1
+	j := 0
+	for i, item := range t.items {
+		v, ok := item.(versionStampItem)
+		if ok && !v.v.Complete() {
+			if offset != -1 {
+				return nil, 0, ErrIncompleteVersionStamps
+			}
+			offset = j
+		}
+		j += item.SizeTuple(i == len(t.items)-1)
+	}
+	if offset == -1 {
+		return nil, 0, ErrIncompleteVersionStamps
+	}
+	return t.Encode(), offset, nil
+}
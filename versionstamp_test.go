@@ -0,0 +1,35 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionStamp(t *testing.T) {
+	prefix := "events"
+	v := IncompleteVersionStamp(7)
+	require.False(t, v.Complete())
+	tup := NewTuple(EscapedString(&prefix), VersionStampItem(&v))
+
+	b, offset, err := tup.EncodeVersionStamped()
+	require.NoError(t, err)
+	require.Equal(t, 8, offset)
+	require.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x07}, b[offset:])
+
+	// What the database does at commit time.
+	copy(b[offset:], []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 3})
+
+	v = VersionStamp{}
+	require.NoError(t, tup.Decode(b))
+	require.True(t, v.Complete())
+	require.Equal(t, VersionStamp{TransactionVersion: [10]byte{7: 1, 9: 3}, UserVersion: 7}, v)
+
+	_, _, err = tup.EncodeVersionStamped()
+	require.Equal(t, ErrIncompleteVersionStamps, err)
+
+	v = IncompleteVersionStamp(0)
+	v2 := IncompleteVersionStamp(1)
+	_, _, err = NewTuple(VersionStampItem(&v), VersionStampItem(&v2)).EncodeVersionStamped()
+	require.Equal(t, ErrIncompleteVersionStamps, err)
+}
@@ -0,0 +1,371 @@
+// Package wal implements a minimal append-only log of records encoded with package encode.
+//
+// The log is a directory of segment files, each a sequence of frames. A frame is:
+//
+//   crc32c     4 bytes, big endian, over everything after it in the frame
+//   flags      1 byte
+//   length     uvarint
+//   record     length bytes, compressed with DEFLATE if flags has flagCompressed set
+//
+// A crash can leave a partial frame at the end of the last segment. Open finds the last complete,
+// valid frame and truncates anything after it before appending.
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrCorrupt = errors.New("wal: corrupt record")
+
+const (
+	flagCompressed = 1 << 0
+
+	segmentSuffix = ".wal"
+	// The largest record the log will read back, before or after inflating, to avoid huge
+	// allocations from a corrupted length or a decompression bomb.
+	maxRecordSize = 1 << 30
+	// Records longer than this are read in pieces, since their length hasn't been checked against
+	// the CRC yet, so that a corrupted length can only cause as large an allocation as there is
+	// actually data.
+	readChunk = 64 << 10
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type Options struct {
+	// Once a segment reaches this many bytes, later records go to a new segment. Zero means 64MiB.
+	SegmentSize int64
+	// Whether to compress records with DEFLATE. Records in a log can be a mix of compressed and
+	// uncompressed, so this can be changed between Opens.
+	Compress bool
+}
+
+// A log open for appending.
+type Log struct {
+	dir  string
+	opts Options
+
+	f       *os.File
+	w       *bufio.Writer
+	segment int
+	size    int64
+}
+
+// Opens the log in dir for appending, creating dir if it doesn't exist. If the last segment ends
+// in a partial frame, as happens when a write is torn by a crash, it is truncated back to the end of
+// the last complete frame. Returns ErrCorrupt if the last segment has a complete frame that's
+// invalid, rather than truncating away the frames after it.
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.SegmentSize == 0 {
+		opts.SegmentSize = 64 << 20
+	}
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, opts: opts}
+	if len(segments) == 0 {
+		return l, l.openSegment(0)
+	}
+	last := segments[len(segments)-1]
+	f, err := os.OpenFile(segmentPath(dir, last), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	good, err := validPrefix(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	err = f.Truncate(good)
+	if err == nil {
+		_, err = f.Seek(good, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.f = f
+	l.w = bufio.NewWriter(f)
+	l.segment = last
+	l.size = good
+	return l, nil
+}
+
+// Appends the current encoding of enc to the log. The record may be buffered until Sync or Close.
+func (l *Log) Append(enc encode.Encoding) error {
+	if l.size >= l.opts.SegmentSize {
+		err := l.rotate()
+		if err != nil {
+			return err
+		}
+	}
+	record := enc.Encode()
+	flags := byte(0)
+	if l.opts.Compress {
+		var compressed bytes.Buffer
+		zw, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		_, err = zw.Write(record)
+		if err != nil {
+			return err
+		}
+		err = zw.Close()
+		if err != nil {
+			return err
+		}
+		record = compressed.Bytes()
+		flags |= flagCompressed
+	}
+
+	frame := make([]byte, 5+binary.MaxVarintLen64+len(record))
+	frame[4] = flags
+	n := 5 + binary.PutUvarint(frame[5:], uint64(len(record)))
+	n += copy(frame[n:], record)
+	frame = frame[:n]
+	binary.BigEndian.PutUint32(frame, crc32.Checksum(frame[4:], crcTable))
+
+	_, err := l.w.Write(frame)
+	if err != nil {
+		return err
+	}
+	l.size += int64(len(frame))
+	return nil
+}
+
+// Flushes buffered records and fsyncs the current segment.
+func (l *Log) Sync() error {
+	err := l.w.Flush()
+	if err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Syncs and closes the log.
+func (l *Log) Close() error {
+	err := l.Sync()
+	closeErr := l.f.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (l *Log) rotate() error {
+	err := l.Close()
+	if err != nil {
+		return err
+	}
+	return l.openSegment(l.segment + 1)
+}
+
+func (l *Log) openSegment(segment int) error {
+	f, err := os.OpenFile(segmentPath(l.dir, segment), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.w = bufio.NewWriter(f)
+	l.segment = segment
+	l.size = 0
+	return nil
+}
+
+// Reads the records of a log in the order they were appended.
+type Iterator struct {
+	dir      string
+	segments []int
+	f        *os.File
+	r        *bufio.Reader
+}
+
+// Returns an Iterator over the log in dir. Records appended after this may or may not be seen.
+func NewIterator(dir string) (*Iterator, error) {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{dir: dir, segments: segments}, nil
+}
+
+// Decodes the next record into enc. Returns io.EOF after the last record.
+//
+// A partial frame at the end of the last segment is treated as the end of the log, since it's what
+// a torn write leaves behind and Open will discard it. Anywhere else, and for any invalid frame, it
+// returns ErrCorrupt.
+func (it *Iterator) Next(enc encode.Encoding) error {
+	for {
+		if it.r == nil {
+			if len(it.segments) == 0 {
+				return io.EOF
+			}
+			f, err := os.Open(segmentPath(it.dir, it.segments[0]))
+			if err != nil {
+				return err
+			}
+			it.segments = it.segments[1:]
+			it.f = f
+			it.r = bufio.NewReader(f)
+		}
+		record, err := readFrame(it.r, maxRecordSize)
+		if err == io.EOF {
+			it.f.Close()
+			it.f = nil
+			it.r = nil
+			continue
+		} else if err == io.ErrUnexpectedEOF {
+			if len(it.segments) == 0 {
+				return io.EOF
+			}
+			return ErrCorrupt
+		} else if err != nil {
+			return err
+		}
+		return enc.Decode(record)
+	}
+}
+
+// Closes the Iterator's open segment, if any.
+func (it *Iterator) Close() error {
+	if it.f == nil {
+		return nil
+	}
+	err := it.f.Close()
+	it.f = nil
+	it.r = nil
+	return err
+}
+
+// Reads one frame from r and returns its decompressed record, which may be at most max bytes both
+// before and after decompressing. Returns io.EOF if r is at the end, io.ErrUnexpectedEOF if it ends
+// partway through a frame, and ErrCorrupt if the frame is invalid.
+func readFrame(r *bufio.Reader, max int) ([]byte, error) {
+	var header [5]byte
+	_, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return nil, err
+	}
+	crc := crc32.Update(0, crcTable, header[4:])
+	l, err := binary.ReadUvarint(r)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, ErrCorrupt
+	}
+	if l > uint64(max) {
+		return nil, ErrCorrupt
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	crc = crc32.Update(crc, crcTable, lenBuf[:binary.PutUvarint(lenBuf[:], l)])
+	record, err := readRecord(r, int(l))
+	if err != nil {
+		return nil, err
+	}
+	crc = crc32.Update(crc, crcTable, record)
+	if crc != binary.BigEndian.Uint32(header[:4]) {
+		return nil, ErrCorrupt
+	}
+	if header[4]&flagCompressed != 0 {
+		inflate := io.LimitReader(flate.NewReader(bytes.NewReader(record)), int64(max)+1)
+		record, err = io.ReadAll(inflate)
+		if err != nil || len(record) > max {
+			return nil, ErrCorrupt
+		}
+	}
+	return record, nil
+}
+
+// Reads l bytes from r, returning io.ErrUnexpectedEOF if it ends first.
+func readRecord(r io.Reader, l int) ([]byte, error) {
+	if l <= readChunk {
+		record := make([]byte, l)
+		_, err := io.ReadFull(r, record)
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return record, err
+	}
+	var record bytes.Buffer
+	record.Grow(readChunk)
+	_, err := io.CopyN(&record, r, int64(l))
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return record.Bytes(), nil
+}
+
+// Returns the length of the longest prefix of f that is made of valid frames, when all that
+// follows it is at most a partial frame. Returns ErrCorrupt if it's followed by an invalid frame.
+func validPrefix(f *os.File) (int64, error) {
+	r := &countingReader{r: f}
+	br := bufio.NewReader(r)
+	good := int64(0)
+	for {
+		_, err := readFrame(br, maxRecordSize)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return good, nil
+		} else if err != nil {
+			return 0, err
+		}
+		good = r.n - int64(br.Buffered())
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	return n, err
+}
+
+func segmentPath(dir string, segment int) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", segment, segmentSuffix))
+}
+
+func listSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		segment, err := strconv.Atoi(strings.TrimSuffix(name, segmentSuffix))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	sort.Ints(segments)
+	return segments, nil
+}
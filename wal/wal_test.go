@@ -0,0 +1,172 @@
+package wal
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bradenaw/encode"
+)
+
+type record struct {
+	id   uint64
+	body string
+}
+
+func (r *record) encoding() encode.Encoding {
+	return encode.New(encode.Uvarint64(&r.id), encode.LengthDelimString(&r.body))
+}
+
+func readAll(t *testing.T, dir string) []record {
+	it, err := NewIterator(dir)
+	require.NoError(t, err)
+	defer it.Close()
+	var records []record
+	for {
+		var r record
+		err := it.Next(r.encoding())
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, r)
+	}
+}
+
+func TestLog(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		l, err := Open(dir, Options{SegmentSize: 100, Compress: compress})
+		require.NoError(t, err)
+
+		var expected []record
+		for i := 0; i < 50; i++ {
+			r := record{id: uint64(i), body: "hello hello hello hello"}
+			require.NoError(t, l.Append(r.encoding()))
+			expected = append(expected, r)
+		}
+		require.NoError(t, l.Close())
+		require.Equal(t, expected, readAll(t, dir))
+
+		segments, err := listSegments(dir)
+		require.NoError(t, err)
+		require.Greater(t, len(segments), 1)
+
+		// Reopen and keep appending.
+		l, err = Open(dir, Options{SegmentSize: 100})
+		require.NoError(t, err)
+		r := record{id: 50, body: "after reopen"}
+		require.NoError(t, l.Append(r.encoding()))
+		expected = append(expected, r)
+		require.NoError(t, l.Close())
+		require.Equal(t, expected, readAll(t, dir))
+	}
+}
+
+func TestTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	require.NoError(t, err)
+	var expected []record
+	for i := 0; i < 3; i++ {
+		r := record{id: uint64(i), body: "some body"}
+		require.NoError(t, l.Append(r.encoding()))
+		expected = append(expected, r)
+	}
+	require.NoError(t, l.Close())
+
+	path := segmentPath(dir, 0)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	for cut := int64(1); cut < 16; cut++ {
+		require.NoError(t, os.Truncate(path, info.Size()-cut))
+		require.Equal(t, expected[:2], readAll(t, dir))
+	}
+
+	// Open throws away the partial frame and appends after the last good one.
+	l, err = Open(dir, Options{})
+	require.NoError(t, err)
+	r := record{id: 3, body: "recovered"}
+	require.NoError(t, l.Append(r.encoding()))
+	require.NoError(t, l.Close())
+	require.Equal(t, append(expected[:2], r), readAll(t, dir))
+}
+
+func TestCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{SegmentSize: 1})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		r := record{id: uint64(i), body: "some body"}
+		require.NoError(t, l.Append(r.encoding()))
+	}
+	require.NoError(t, l.Close())
+
+	path := filepath.Join(dir, "00000000000000000000.wal")
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	b[len(b)-1] ^= 0x01
+	require.NoError(t, os.WriteFile(path, b, 0644))
+
+	it, err := NewIterator(dir)
+	require.NoError(t, err)
+	defer it.Close()
+	var r record
+	require.Equal(t, ErrCorrupt, it.Next(r.encoding()))
+}
+
+func TestCorruptLastSegment(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		r := record{id: uint64(i), body: "some body"}
+		require.NoError(t, l.Append(r.encoding()))
+	}
+	require.NoError(t, l.Close())
+
+	// Corrupt the first frame. The ones after it are intact, so this isn't a torn write and
+	// truncating would lose them.
+	path := segmentPath(dir, 0)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	b[len(b)/3-1] ^= 0x01
+	require.NoError(t, os.WriteFile(path, b, 0644))
+
+	_, err = Open(dir, Options{})
+	require.Equal(t, ErrCorrupt, err)
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, b, after)
+
+	it, err := NewIterator(dir)
+	require.NoError(t, err)
+	defer it.Close()
+	var r record
+	require.Equal(t, ErrCorrupt, it.Next(r.encoding()))
+}
+
+func TestRecordLimit(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		l, err := Open(dir, Options{Compress: compress})
+		require.NoError(t, err)
+		// Longer than readChunk, so it's read in pieces.
+		big := record{id: 1, body: strings.Repeat("a", 3*readChunk)}
+		require.NoError(t, l.Append(big.encoding()))
+		require.NoError(t, l.Close())
+		require.Equal(t, []record{big}, readAll(t, dir))
+
+		f, err := os.Open(segmentPath(dir, 0))
+		require.NoError(t, err)
+		// Over the limit after inflating, even if it isn't before.
+		_, err = readFrame(bufio.NewReader(f), 2*readChunk)
+		require.Equal(t, ErrCorrupt, err)
+		require.NoError(t, f.Close())
+	}
+}